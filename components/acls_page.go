@@ -0,0 +1,611 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"haproxy-runtime-cli/styles"
+	"slices"
+	"strings"
+)
+
+// AclsPage lists the acls and edits the patterns of one of them, one by one or staged and committed at once
+type AclsPage struct {
+	socket *socket.Client
+	keys   aclsPageKeyMap
+	help   help.Model
+	lists  []haproxy.PatternList
+	acls   table.Model
+	// opened is the acl whose patterns are shown, empty while listing the acls
+	opened   string
+	patterns []haproxy.Pattern
+	// staging collects the patterns added and the refs of the ones removed, until they are committed
+	staging bool
+	added   []string
+	removed map[string]bool
+	// visible are the rows matching the filter
+	visible []aclRow
+	entries table.Model
+	filter  textinput.Model
+	// input takes a new pattern or a value to test the acl with
+	input        textinput.Model
+	editing      aclEdit
+	confirmation Confirmation
+	message      string
+}
+
+type aclsPageKeyMap struct {
+	listKeyMap
+	Filter  key.Binding
+	Test    key.Binding
+	Add     key.Binding
+	Delete  key.Binding
+	Stage   key.Binding
+	Clear   key.Binding
+	Commit  key.Binding
+	Discard key.Binding
+	confirmationKeyMap
+}
+
+// aclEdit is what the input of the acls page is taking
+type aclEdit int
+
+const (
+	notEditingAcl aclEdit = iota
+	addingPattern
+	testingValue
+)
+
+// aclRow is a pattern of the table, while staging it is marked as added (+) or removed (-)
+type aclRow struct {
+	pattern haproxy.Pattern
+	change  string
+}
+
+type ActivateAclsPage bool
+
+// AclChanged describes a change of an acl
+type AclChanged string
+
+// aclCommitted describes the committed staged changes
+type aclCommitted string
+
+// aclCommitChecked are the patterns of the acl refetched before committing the staged changes
+type aclCommitChecked struct {
+	name     string
+	patterns []haproxy.Pattern
+}
+
+type aclLists []haproxy.PatternList
+
+type aclEntries struct {
+	name     string
+	patterns []haproxy.Pattern
+}
+
+// aclTested is the result of testing a value against an acl
+type aclTested struct {
+	value string
+	match haproxy.PatternMatch
+}
+
+func NewAclsPage(socket *socket.Client) AclsPage {
+	km := createAclsKeyMap()
+	filter := textinput.New()
+	filter.Prompt = "filter: "
+	filter.PromptStyle = styles.ComplementStyle
+	input := textinput.New()
+	input.PromptStyle = styles.ComplementStyle
+
+	p := AclsPage{
+		socket:       socket,
+		keys:         km,
+		help:         help.New(),
+		confirmation: NewConfirmation(),
+		filter:       filter,
+		input:        input,
+		removed:      map[string]bool{},
+		acls: newTable([]table.Column{
+			{Title: "Id", Width: 4},
+			{Title: "Acl", Width: 30},
+			{Title: "Version", Width: 8},
+			{Title: "Patterns", Width: 8},
+			{Title: "Description", Width: 40},
+		}, []key.Binding{km.Open, km.Refresh, km.GotoStatusPage}),
+		entries: newTable([]table.Column{
+			{Title: "", Width: 1},
+			{Title: "Pattern", Width: 30},
+			{Title: "Ref", Width: 16},
+		}, nil),
+	}
+	p.updateHelp()
+
+	return p
+}
+
+func (p AclsPage) Init() tea.Cmd {
+	return nil
+}
+
+func (p AclsPage) Update(msg tea.Msg) (AclsPage, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ActivateAclsPage:
+		// the acls are fetched when needed, as `show acl` requires the operator level
+		return p, p.refresh()
+	case WorkerSelected:
+		if p.lists == nil {
+			return p, nil
+		}
+		return p, p.refresh()
+	case aclLists:
+		p.lists = msg
+		p.acls.SetRows(patternListsToRows(msg))
+		p.acls = recalculateTableSize(p.acls)
+		return p, nil
+	case aclEntries:
+		if msg.name == p.opened {
+			p.patterns = msg.patterns
+			p.updateRows()
+		}
+		return p, nil
+	case aclCommitChecked:
+		if msg.name != p.opened || !p.staging {
+			return p, nil
+		}
+		changed := !slices.Equal(msg.patterns, p.patterns)
+		p.patterns = msg.patterns
+		p.updateRows()
+		if changed {
+			// the staged changes now show against the current patterns, which have to be reviewed first
+			p.message = fmt.Sprintf("%s changed since staging began, review the staged changes and commit again", p.opened)
+			return p, nil
+		}
+		p.message = ""
+		p.confirmation = p.confirmation.Ask(
+			fmt.Sprintf("commit %d added and %d removed patterns to %s", len(p.added), p.removedCount(), p.opened),
+			commitAcl(p.socket, p.opened, p.patterns, p.stagedPatterns()),
+		)
+		return p, nil
+	case aclTested:
+		if msg.match.Matched {
+			p.message = fmt.Sprintf("%s matches %s", msg.value, msg.match.Pattern)
+		} else {
+			p.message = fmt.Sprintf("%s matches no pattern", msg.value)
+		}
+		return p, nil
+	case AclChanged:
+		p.message = string(msg)
+		return p, p.refresh()
+	case aclCommitted:
+		p.message = string(msg)
+		p = p.stage(false)
+		return p, p.refresh()
+	case tea.WindowSizeMsg:
+		height := msg.Height - styles.PageStyle.GetVerticalMargins() - 3 - 3 - 2
+		p.acls.SetWidth(msg.Width - styles.PageStyle.GetHorizontalMargins())
+		p.acls.SetHeight(height)
+		p.entries.SetWidth(msg.Width - styles.PageStyle.GetHorizontalMargins())
+		p.entries.SetHeight(height - 1)
+	case tea.KeyMsg:
+		if p.editing != notEditingAcl {
+			return p.updateInput(msg)
+		}
+		if p.filter.Focused() {
+			return p.updateFilter(msg)
+		}
+		if p.confirmation.Asking() {
+			var cmd tea.Cmd
+			p.confirmation, cmd = p.confirmation.Update(msg)
+			return p, cmd
+		}
+		if p.opened == "" {
+			return p.updateAcls(msg)
+		}
+
+		row := p.selected()
+		switch {
+		case key.Matches(msg, p.keys.Close):
+			p.opened = ""
+			p.filter.SetValue("")
+			p.message = ""
+			return p, nil
+		case key.Matches(msg, p.keys.Refresh):
+			return p, p.refresh()
+		case key.Matches(msg, p.keys.Filter):
+			return p, p.filter.Focus()
+		case key.Matches(msg, p.keys.Test):
+			return p.edit(testingValue, "test: ")
+		case key.Matches(msg, p.keys.Add):
+			return p.edit(addingPattern, "pattern: ")
+		case key.Matches(msg, p.keys.Stage):
+			p.message = ""
+			return p.stage(true), nil
+		case key.Matches(msg, p.keys.Discard):
+			p.message = "staged changes discarded"
+			return p.stage(false), nil
+		case key.Matches(msg, p.keys.Clear):
+			p.added = nil
+			for _, e := range p.patterns {
+				p.removed[e.Ref] = true
+			}
+			p.updateRows()
+			return p, nil
+		case key.Matches(msg, p.keys.Commit):
+			return p, checkAclCommit(p.socket, p.opened)
+		case row == nil:
+			// the other actions need a selected pattern
+		case key.Matches(msg, p.keys.Delete) && p.staging:
+			if row.change == "+" {
+				i := slices.Index(p.added, row.pattern.Key)
+				p.added = slices.Delete(slices.Clone(p.added), i, i+1)
+			} else if p.removed[row.pattern.Ref] {
+				delete(p.removed, row.pattern.Ref)
+			} else {
+				p.removed[row.pattern.Ref] = true
+			}
+			p.updateRows()
+			return p, nil
+		case key.Matches(msg, p.keys.Delete):
+			p.confirmation = p.confirmation.Ask(
+				fmt.Sprintf("delete %s from %s", row.pattern.Key, p.opened),
+				changeCmd[AclChanged](p.socket, fmt.Sprintf("del acl %s #%s", p.opened, row.pattern.Ref), "deleted "+row.pattern.Key),
+			)
+			return p, nil
+		}
+
+		var cmd tea.Cmd
+		p.entries, cmd = p.entries.Update(msg)
+		return p, cmd
+	}
+
+	return p, nil
+}
+
+func (p AclsPage) updateAcls(msg tea.KeyMsg) (AclsPage, tea.Cmd) {
+	switch {
+	case key.Matches(msg, p.keys.GotoStatusPage):
+		return p, ActivateStatusPageCmd()
+	case key.Matches(msg, p.keys.Refresh):
+		return p, p.refresh()
+	case key.Matches(msg, p.keys.Open):
+		cursor := p.acls.Cursor()
+		if cursor < 0 || cursor >= len(p.lists) {
+			return p, nil
+		}
+		p.opened = p.lists[cursor].Name()
+		p.patterns = nil
+		p.message = ""
+		p = p.stage(false)
+		return p, fetchAclEntries(p.socket, p.opened)
+	}
+
+	var cmd tea.Cmd
+	p.acls, cmd = p.acls.Update(msg)
+
+	return p, cmd
+}
+
+func (p AclsPage) View() string {
+	if p.opened == "" {
+		return tblStyle.Render(p.acls.View()) + "\n" + p.footer(p.acls)
+	}
+
+	title := styles.ActiveStyle.Render(p.opened) + styles.ComplementStyle.Render(fmt.Sprintf("  %d patterns", len(p.patterns)))
+	if p.staging {
+		title += styles.DegradedStyle.Render(fmt.Sprintf("  staged +%d -%d", len(p.added), p.removedCount()))
+	}
+	if p.filter.Value() != "" {
+		title += styles.ComplementStyle.Render(fmt.Sprintf(", %d matching %q", len(p.visible), p.filter.Value()))
+	}
+
+	return title + "\n" + tblStyle.Render(p.entries.View()) + "\n" + p.footer(p.entries)
+}
+
+func (p AclsPage) Supports(msg tea.Msg, isActive bool) bool {
+	switch msg.(type) {
+	case ActivateAclsPage, aclLists, aclEntries, aclCommitChecked, aclTested, AclChanged, aclCommitted, WorkerSelected, tea.WindowSizeMsg:
+		return true
+	case tea.KeyMsg:
+		if isActive {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p AclsPage) footer(t table.Model) string {
+	switch {
+	case p.editing != notEditingAcl:
+		return p.input.View() + "\n" + p.help.ShortHelpView([]key.Binding{p.keys.Confirm, p.keys.Cancel})
+	case p.filter.Focused():
+		return p.filter.View() + "\n" + p.help.ShortHelpView([]key.Binding{p.keys.Confirm, p.keys.Cancel})
+	case p.confirmation.Asking():
+		return p.confirmation.View()
+	case p.message != "":
+		return styles.ComplementStyle.Render(p.message) + "\n" + t.HelpView()
+	}
+
+	return t.HelpView()
+}
+
+// refresh fetches the acls and the patterns of the opened acl
+func (p AclsPage) refresh() tea.Cmd {
+	if p.opened == "" {
+		return fetchAcls(p.socket)
+	}
+
+	return tea.Batch(fetchAcls(p.socket), fetchAclEntries(p.socket, p.opened))
+}
+
+// stage starts or ends staging, the staged changes are dropped either way
+func (p AclsPage) stage(staging bool) AclsPage {
+	p.staging = staging
+	p.added = nil
+	p.removed = map[string]bool{}
+	p.keys.Stage.SetEnabled(!staging)
+	p.keys.Close.SetEnabled(!staging)
+	p.keys.Clear.SetEnabled(staging)
+	p.keys.Commit.SetEnabled(staging)
+	p.keys.Discard.SetEnabled(staging)
+	p.updateHelp()
+	p.updateRows()
+
+	return p
+}
+
+// stagedPatterns are the patterns of the acl once the staged changes are committed
+func (p AclsPage) stagedPatterns() []string {
+	var res []string
+	for _, e := range p.patterns {
+		if !p.removed[e.Ref] {
+			res = append(res, e.Key)
+		}
+	}
+
+	return append(res, p.added...)
+}
+
+// removedCount is the number of patterns of the acl staged to be removed
+func (p AclsPage) removedCount() int {
+	n := 0
+	for _, e := range p.patterns {
+		if p.removed[e.Ref] {
+			n++
+		}
+	}
+
+	return n
+}
+
+func (p AclsPage) edit(editing aclEdit, prompt string) (AclsPage, tea.Cmd) {
+	p.editing = editing
+	p.input.Prompt = prompt
+	p.input.SetValue("")
+
+	return p, p.input.Focus()
+}
+
+// updateInput adds the new pattern, staged or right away, or tests the value
+func (p AclsPage) updateInput(msg tea.KeyMsg) (AclsPage, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		value := strings.TrimSpace(p.input.Value())
+		if value == "" {
+			return p, nil
+		}
+		editing := p.editing
+		p.editing = notEditingAcl
+		p.input.Blur()
+
+		switch {
+		case editing == testingValue:
+			return p, testAcl(p.socket, p.opened, value)
+		case p.staging:
+			if !slices.Contains(p.added, value) {
+				p.added = append(slices.Clone(p.added), value)
+			}
+			p.updateRows()
+			return p, nil
+		}
+		return p, changeCmd[AclChanged](p.socket, fmt.Sprintf("add acl %s %s", p.opened, haproxy.EscapeArg(value)), "added "+value)
+	case tea.KeyEsc:
+		p.editing = notEditingAcl
+		p.input.Blur()
+		return p, nil
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+
+	return p, cmd
+}
+
+// updateFilter filters the patterns while typing, enter keeps the filter and esc drops it
+func (p AclsPage) updateFilter(msg tea.KeyMsg) (AclsPage, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		p.filter.Blur()
+		return p, nil
+	case tea.KeyEsc:
+		p.filter.Blur()
+		p.filter.SetValue("")
+		p.updateRows()
+		return p, nil
+	}
+
+	var cmd tea.Cmd
+	p.filter, cmd = p.filter.Update(msg)
+	p.updateRows()
+
+	return p, cmd
+}
+
+// updateRows shows the patterns matching the filter, while staging with the added ones and the removed ones marked
+func (p *AclsPage) updateRows() {
+	p.visible = nil
+	for _, e := range filterPatterns(p.patterns, p.filter.Value()) {
+		row := aclRow{pattern: e}
+		if p.removed[e.Ref] {
+			row.change = "-"
+		}
+		p.visible = append(p.visible, row)
+	}
+	for _, e := range p.added {
+		if len(filterPatterns([]haproxy.Pattern{{Key: e}}, p.filter.Value())) > 0 {
+			p.visible = append(p.visible, aclRow{pattern: haproxy.Pattern{Key: e}, change: "+"})
+		}
+	}
+
+	rows := make([]table.Row, len(p.visible))
+	for i, r := range p.visible {
+		rows[i] = table.Row{r.change, r.pattern.Key, r.pattern.Ref}
+	}
+	p.entries.SetRows(rows)
+	p.entries = recalculateTableSize(p.entries)
+	// the table leaves the cursor at -1 while it is empty
+	p.entries.SetCursor(min(max(p.entries.Cursor(), 0), len(rows)-1))
+}
+
+// updateHelp lists the keys of staging or of editing right away
+func (p *AclsPage) updateHelp() {
+	table.WithAdditionalShortHelpKeys([]key.Binding{
+		p.keys.Test, p.keys.Add, p.keys.Delete, p.keys.Clear, p.keys.Stage, p.keys.Commit, p.keys.Discard,
+		p.keys.Filter, p.keys.Refresh, p.keys.Close,
+	})(&p.entries)
+}
+
+// selected is the row of the selected pattern
+func (p AclsPage) selected() *aclRow {
+	cursor := p.entries.Cursor()
+	if cursor < 0 || cursor >= len(p.visible) {
+		return nil
+	}
+
+	return &p.visible[cursor]
+}
+
+func ActivateAclsPageCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ActivateAclsPage(true)
+	}
+}
+
+func createAclsKeyMap() aclsPageKeyMap {
+	return aclsPageKeyMap{
+		listKeyMap: createListKeyMap("acls", "patterns"),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter"),
+		),
+		Test: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "test"),
+		),
+		Add: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "add"),
+		),
+		Delete: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "delete"),
+		),
+		Stage: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "stage changes"),
+		),
+		// only enabled while staging
+		Clear: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "clear"),
+			key.WithDisabled(),
+		),
+		Commit: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "commit"),
+			key.WithDisabled(),
+		),
+		Discard: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "discard"),
+			key.WithDisabled(),
+		),
+		confirmationKeyMap: createConfirmationKeyMap(),
+	}
+}
+
+func fetchAcls(s *socket.Client) tea.Cmd {
+	return socket.ExecCmd[aclLists](
+		context.Background(),
+		s,
+		"show acl",
+		func(out *string) (aclLists, error) { return haproxy.ParsePatternLists(*out) },
+	)
+}
+
+func fetchAclEntries(s *socket.Client, name string) tea.Cmd {
+	return socket.ExecCmd[aclEntries](
+		context.Background(),
+		s,
+		"show acl "+name,
+		func(out *string) (aclEntries, error) {
+			patterns, err := haproxy.ParseAclPatterns(*out)
+			return aclEntries{name: name, patterns: patterns}, err
+		},
+	)
+}
+
+// checkAclCommit refetches the patterns of the acl, so the staged changes are committed against its current state
+func checkAclCommit(s *socket.Client, name string) tea.Cmd {
+	return socket.ExecCmd[aclCommitChecked](
+		context.Background(),
+		s,
+		"show acl "+name,
+		func(out *string) (aclCommitChecked, error) {
+			patterns, err := haproxy.ParseAclPatterns(*out)
+			return aclCommitChecked{name: name, patterns: patterns}, err
+		},
+	)
+}
+
+func testAcl(s *socket.Client, name string, value string) tea.Cmd {
+	return socket.ExecCmd[aclTested](
+		context.Background(),
+		s,
+		fmt.Sprintf("get acl %s %s", name, haproxy.EscapeArg(value)),
+		func(out *string) (aclTested, error) {
+			match, err := haproxy.ParsePatternMatch(*out)
+			return aclTested{value: value, match: match}, err
+		},
+	)
+}
+
+// commitAcl replaces the patterns of the acl with the staged ones at once, unless they changed since the commit was confirmed
+func commitAcl(s *socket.Client, name string, base []haproxy.Pattern, patterns []string) tea.Cmd {
+	return func() tea.Msg {
+		res, err := execChecked(context.Background(), s, "show acl "+name)
+		if err != nil {
+			return err
+		}
+		current, err := haproxy.ParseAclPatterns(res)
+		if err != nil {
+			return err
+		}
+		if !slices.Equal(current, base) {
+			return fmt.Errorf("%s changed since the commit was confirmed, nothing was committed", name)
+		}
+
+		version, err := replacePatterns(context.Background(), s, "acl", name, patterns)
+		if err != nil {
+			return err
+		}
+
+		return aclCommitted(fmt.Sprintf("committed %d patterns to %s as version %s", len(patterns), name, version))
+	}
+}
@@ -0,0 +1,252 @@
+package components
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"testing"
+)
+
+func TestAclsPage(t *testing.T) {
+	t.Parallel()
+
+	// openAcl shows the patterns of the inline acl #1 of the fake, `/admin` and `/internal`
+	openAcl := func(client *socket.Client) AclsPage {
+		m, cmd := NewAclsPage(client).Update(ActivateAclsPage(true))
+		m, _ = m.Update(cmd())
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, _ = m.Update(cmd())
+
+		return m
+	}
+
+	fakeModel := func(t *testing.T) AclsPage {
+		return openAcl(fakeClient(t))
+	}
+
+	typed := func(m AclsPage, value string) AclsPage {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(value)})
+		return m
+	}
+
+	t.Run("Update Activate", func(t *testing.T) {
+		m := NewAclsPage(nil)
+		assert.Nil(t, m.Init())
+
+		m, _ = m.Update(aclLists{{Id: 1, Description: "acl 'path_beg'", Entries: 2}})
+
+		res := m.View()
+		assert.Contains(t, res, "#1")
+		assert.Contains(t, res, "enter patterns")
+	})
+
+	t.Run("Update Open", func(t *testing.T) {
+		m := fakeModel(t)
+
+		assert.Equal(t, "#1", m.opened)
+		res := m.View()
+		assert.Contains(t, res, "#1  2 patterns")
+		assert.Contains(t, res, "/internal")
+		assert.Contains(t, res, "s stage changes")
+		assert.NotContains(t, res, "c commit")
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		assert.Empty(t, m.opened)
+	})
+
+	t.Run("Update Filter", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('/'))
+		m = typed(m, "int")
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Len(t, m.visible, 1)
+		assert.Equal(t, "/internal", m.selected().pattern.Key)
+	})
+
+	t.Run("Update Test", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('t'))
+		m = typed(m, "/admin/users")
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, _ = m.Update(cmd())
+		assert.Contains(t, m.View(), "/admin/users matches /admin")
+
+		m, _ = m.Update(keyMsg('t'))
+		m = typed(m, "/public")
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, _ = m.Update(cmd())
+		assert.Contains(t, m.View(), "/public matches no pattern")
+	})
+
+	t.Run("Update Add", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('a'))
+		m = typed(m, "/debug")
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = apply(m, cmd)
+
+		assert.Contains(t, m.View(), "added /debug")
+		assert.Len(t, m.patterns, 3)
+	})
+
+	t.Run("Update Escaped Patterns", func(t *testing.T) {
+		m := fakeModel(t)
+
+		// `;` is part of the pattern instead of starting another command
+		m, _ = m.Update(keyMsg('a'))
+		m = typed(m, "/a;help")
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = apply(m, cmd)
+		assert.Len(t, m.patterns, 3)
+		assert.Equal(t, "/a;help", m.patterns[2].Key)
+
+		m, _ = m.Update(keyMsg('t'))
+		m = typed(m, "/admin;x")
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, _ = m.Update(cmd())
+		assert.Contains(t, m.View(), "/admin;x matches /admin")
+	})
+
+	t.Run("Update Delete", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('d'))
+		assert.Contains(t, m.View(), "delete /admin from #1?")
+		m, cmd := m.Update(keyMsg('y'))
+		m = apply(m, cmd)
+
+		assert.Len(t, m.patterns, 1)
+		assert.Equal(t, "/internal", m.patterns[0].Key)
+	})
+
+	t.Run("Update Staged", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('s'))
+		assert.Contains(t, m.View(), "c commit")
+		// nothing is sent while staging
+		m, cmd := m.Update(keyMsg('d'))
+		assert.Nil(t, cmd)
+		m, _ = m.Update(keyMsg('a'))
+		m = typed(m, "/debug")
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Nil(t, cmd)
+
+		res := m.View()
+		assert.Contains(t, res, "staged +1 -1")
+		assert.Equal(t, []aclRow{
+			{pattern: m.patterns[0], change: "-"},
+			{pattern: m.patterns[1]},
+			{pattern: haproxy.Pattern{Key: "/debug"}, change: "+"},
+		}, m.visible)
+		assert.Equal(t, []string{"/internal", "/debug"}, m.stagedPatterns())
+
+		m, cmd = m.Update(keyMsg('c'))
+		m, _ = m.Update(cmd())
+		assert.Contains(t, m.View(), "commit 1 added and 1 removed patterns to #1?")
+		m, cmd = m.Update(keyMsg('y'))
+		m = apply(m, cmd)
+
+		assert.False(t, m.staging)
+		assert.Contains(t, m.View(), "committed 2 patterns to #1 as version 1")
+		assert.Equal(t, "/internal", m.patterns[0].Key)
+		assert.Equal(t, "/debug", m.patterns[1].Key)
+		assert.Equal(t, 1, m.lists[1].CurrentVersion)
+	})
+
+	t.Run("Update Patterns With Spaces", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('a'))
+		m = typed(m, "Mozilla/5.0 (compatible; bot)")
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = apply(m, cmd)
+		assert.Equal(t, "Mozilla/5.0 (compatible; bot)", m.patterns[2].Key)
+
+		// a staged commit keeps the whole pattern
+		m, _ = m.Update(keyMsg('s'))
+		m, _ = m.Update(keyMsg('d'))
+		m, cmd = m.Update(keyMsg('c'))
+		m, _ = m.Update(cmd())
+		m, cmd = m.Update(keyMsg('y'))
+		m = apply(m, cmd)
+
+		assert.Equal(t, []string{"/internal", "Mozilla/5.0 (compatible; bot)"}, []string{m.patterns[0].Key, m.patterns[1].Key})
+	})
+
+	t.Run("Update Staged Changed Meanwhile", func(t *testing.T) {
+		srv := fakeServer(t)
+		m := openAcl(dial(t, srv))
+
+		m, _ = m.Update(keyMsg('s'))
+		m, _ = m.Update(keyMsg('d'))
+		srv.HAProxy.Exec("add acl #1 /metrics")
+
+		// the commit is aborted and the staged changes are shown against the current patterns
+		m, cmd := m.Update(keyMsg('c'))
+		m, _ = m.Update(cmd())
+		res := m.View()
+		assert.False(t, m.confirmation.Asking())
+		assert.Contains(t, res, "#1 changed since staging began")
+		assert.Contains(t, res, "/metrics")
+		assert.Equal(t, []string{"/internal", "/metrics"}, m.stagedPatterns())
+
+		m, cmd = m.Update(keyMsg('c'))
+		m, _ = m.Update(cmd())
+		assert.Contains(t, m.View(), "commit 0 added and 1 removed patterns to #1?")
+
+		// a change after confirming is not overwritten either
+		srv.HAProxy.Exec("add acl #1 /status")
+		_, cmd = m.Update(keyMsg('y'))
+		err, _ := cmd().(error)
+		assert.ErrorContains(t, err, "#1 changed since the commit was confirmed")
+		assert.Contains(t, srv.HAProxy.Exec("show acl #1"), "/admin")
+	})
+
+	t.Run("Update Staged Undo", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('s'))
+		m, _ = m.Update(keyMsg('x'))
+		assert.Empty(t, m.stagedPatterns())
+
+		// deleting a removed pattern keeps it again, deleting an added one drops it
+		m, _ = m.Update(keyMsg('d'))
+		m, _ = m.Update(keyMsg('a'))
+		m = typed(m, "/debug")
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnd})
+		m, _ = m.Update(keyMsg('d'))
+		assert.Equal(t, []string{"/admin"}, m.stagedPatterns())
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.False(t, m.staging)
+		assert.Contains(t, m.View(), "staged changes discarded")
+		assert.Equal(t, []string{"/admin", "/internal"}, m.stagedPatterns())
+	})
+
+	t.Run("Update Goto Status Page", func(t *testing.T) {
+		_, cmd := NewAclsPage(nil).Update(tea.KeyMsg{Type: tea.KeyBackspace})
+
+		assert.IsType(t, ActivateStatusPageCmd(), cmd)
+	})
+
+	t.Run("Supports", func(t *testing.T) {
+		m := NewAclsPage(nil)
+
+		assert.True(t, m.Supports(ActivateAclsPage(true), false))
+		assert.True(t, m.Supports(aclLists{}, false))
+		assert.True(t, m.Supports(aclTested{}, false))
+		assert.True(t, m.Supports(AclChanged(""), false))
+		assert.True(t, m.Supports(aclCommitted(""), false))
+		assert.True(t, m.Supports(WorkerSelected{}, false))
+		assert.True(t, m.Supports(tea.KeyMsg{}, true))
+		assert.False(t, m.Supports(tea.KeyMsg{}, false))
+	})
+}
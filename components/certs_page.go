@@ -0,0 +1,466 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"haproxy-runtime-cli/styles"
+	"os"
+	"strings"
+	"time"
+)
+
+// certExpiryWarning is how long before their expiry the certificates are flagged
+const certExpiryWarning = 30 * 24 * time.Hour
+
+// CertsPage lists the loaded certificates and replaces them through a `set ssl cert` transaction
+type CertsPage struct {
+	socket *socket.Client
+	keys   certsPageKeyMap
+	help   help.Model
+	loaded bool
+	certs  []haproxy.Certificate
+	table  table.Model
+	// transaction is the uploaded but uncommitted certificate, nil without an ongoing transaction
+	transaction *haproxy.Certificate
+	// details is the file whose `show ssl cert <file>` output is shown, empty while listing the certificates
+	details string
+	viewer  ResponseViewer
+	// input takes the path of the PEM file to upload while uploading
+	input        textinput.Model
+	uploading    bool
+	confirmation Confirmation
+	message      string
+}
+
+type certsPageKeyMap struct {
+	listKeyMap
+	Upload key.Binding
+	Commit key.Binding
+	Abort  key.Binding
+	confirmationKeyMap
+}
+
+type ActivateCertsPage bool
+
+// CertChanged describes a step of a certificate transaction
+type CertChanged string
+
+type certList struct {
+	certs       []haproxy.Certificate
+	transaction *haproxy.Certificate
+}
+
+type certDetails struct {
+	file string
+	dump string
+}
+
+func NewCertsPage(socket *socket.Client) CertsPage {
+	km := createCertsKeyMap()
+	input := textinput.New()
+	input.PromptStyle = styles.ComplementStyle
+	input.Placeholder = "/path/to/certificate.pem"
+
+	return CertsPage{
+		socket:       socket,
+		keys:         km,
+		help:         help.New(),
+		confirmation: NewConfirmation(),
+		viewer:       NewResponseViewer(),
+		input:        input,
+		table: newTable([]table.Column{
+			{Title: "File", Width: 40},
+			{Title: "Subject", Width: 20},
+			{Title: "SANs", Width: 30},
+			{Title: "Issuer", Width: 20},
+			// the colored expiry is the last column, as the table counts the color codes into the width
+			{Title: "Expires", Width: 20},
+		}, certsHelpKeys(km)),
+	}
+}
+
+func (p CertsPage) Init() tea.Cmd {
+	return nil
+}
+
+func (p CertsPage) Update(msg tea.Msg) (CertsPage, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ActivateCertsPage:
+		// the certificates are fetched when needed, as `show ssl cert` requires the operator level
+		return p, p.refresh()
+	case WorkerSelected:
+		if !p.loaded {
+			return p, nil
+		}
+		p.details = ""
+		return p, p.refresh()
+	case certList:
+		p.loaded = true
+		p.certs = msg.certs
+		p.transaction = msg.transaction
+		p.updateRows()
+		return p, nil
+	case certDetails:
+		if msg.file == p.details {
+			p.viewer.SetResponse(msg.dump)
+		}
+		return p, nil
+	case CertChanged:
+		p.message = string(msg)
+		return p, p.refresh()
+	case responseSaved:
+		var cmd tea.Cmd
+		p.viewer, cmd = p.viewer.Update(msg)
+		return p, cmd
+	case tea.WindowSizeMsg:
+		// the transaction takes two lines below the table
+		height := msg.Height - styles.PageStyle.GetVerticalMargins() - 3 - 3 - 2
+		p.table.SetWidth(msg.Width - styles.PageStyle.GetHorizontalMargins())
+		p.table.SetHeight(height - 3)
+		p.viewer.SetSize(msg.Width-styles.PageStyle.GetHorizontalMargins(), height-3)
+	case tea.KeyMsg:
+		if p.uploading {
+			return p.updateInput(msg)
+		}
+		if p.confirmation.Asking() {
+			var cmd tea.Cmd
+			p.confirmation, cmd = p.confirmation.Update(msg)
+			return p, cmd
+		}
+		if p.details != "" {
+			return p.updateDetails(msg)
+		}
+
+		cert := p.selected()
+		switch {
+		case key.Matches(msg, p.keys.GotoStatusPage):
+			return p, ActivateStatusPageCmd()
+		case key.Matches(msg, p.keys.Refresh):
+			return p, p.refresh()
+		case key.Matches(msg, p.keys.Commit):
+			t := p.transaction
+			p.confirmation = p.confirmation.Ask(
+				fmt.Sprintf("commit the certificate of %s expiring %s to %s", t.CommonName(), t.NotAfter.Format(time.DateOnly), t.File),
+				commitCert(p.socket, t.File),
+			)
+			return p, nil
+		case key.Matches(msg, p.keys.Abort):
+			p.confirmation = p.confirmation.Ask(
+				"abort the transaction for "+p.transaction.File,
+				abortCert(p.socket, p.transaction.File),
+			)
+			return p, nil
+		case cert == nil:
+			// the other actions need a selected certificate
+		case key.Matches(msg, p.keys.Open):
+			p.details = cert.File
+			p.message = ""
+			p.viewer.SetResponse("")
+			return p, fetchCertDetails(p.socket, cert.File)
+		case key.Matches(msg, p.keys.Upload):
+			p.uploading = true
+			p.input.Prompt = "PEM for " + cert.File + ": "
+			p.input.SetValue("")
+			return p, p.input.Focus()
+		}
+
+		var cmd tea.Cmd
+		p.table, cmd = p.table.Update(msg)
+		return p, cmd
+	}
+
+	return p, nil
+}
+
+// updateInput uploads the typed PEM file for the selected certificate
+func (p CertsPage) updateInput(msg tea.KeyMsg) (CertsPage, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		p.uploading = false
+		p.input.Blur()
+		cert := p.selected()
+		path := strings.TrimSpace(p.input.Value())
+		if cert == nil || path == "" {
+			return p, nil
+		}
+		return p, uploadCert(p.socket, cert.File, path)
+	case tea.KeyEsc:
+		p.uploading = false
+		p.input.Blur()
+		return p, nil
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+
+	return p, cmd
+}
+
+// updateDetails scrolls the details of a certificate
+func (p CertsPage) updateDetails(msg tea.KeyMsg) (CertsPage, tea.Cmd) {
+	if p.viewer.Handles(msg) {
+		var cmd tea.Cmd
+		p.viewer, cmd = p.viewer.Update(msg)
+		return p, cmd
+	}
+
+	switch {
+	case key.Matches(msg, p.keys.Close):
+		p.details = ""
+	case key.Matches(msg, p.keys.Refresh):
+		return p, p.refresh()
+	}
+
+	return p, nil
+}
+
+func (p CertsPage) View() string {
+	if p.details != "" {
+		return styles.ActiveStyle.Render(p.details) + "\n" + styles.ResponseStyle.Render(p.viewer.View()) + "\n" +
+			p.footer(p.help.ShortHelpView([]key.Binding{p.keys.Refresh, p.keys.Close}))
+	}
+
+	now := time.Now()
+	expiring := 0
+	for _, c := range p.certs {
+		if !c.NotAfter.IsZero() && c.ExpiresWithin(certExpiryWarning, now) {
+			expiring++
+		}
+	}
+	title := styles.ActiveStyle.Render(fmt.Sprintf("%d certificates", len(p.certs)))
+	if expiring > 0 {
+		title += styles.DegradedStyle.Render(fmt.Sprintf(", %d expiring within %d days", expiring, int(certExpiryWarning.Hours()/24)))
+	}
+
+	s := title + "\n" + tblStyle.Render(p.table.View()) + "\n"
+	if t := p.transaction; t != nil {
+		s += styles.ActiveStyle.Render("transaction for "+t.File) + "\n" +
+			styles.ComplementStyle.Render(fmt.Sprintf("new certificate: %s · %s · issued by %s · expires ", t.CommonName(), strings.Join(t.SANs, ", "), t.IssuerName())) +
+			certExpiry(*t, now) + "\n"
+	}
+
+	return s + p.footer(p.table.HelpView())
+}
+
+func (p CertsPage) Supports(msg tea.Msg, isActive bool) bool {
+	switch msg.(type) {
+	case ActivateCertsPage, certList, certDetails, CertChanged, WorkerSelected, tea.WindowSizeMsg:
+		return true
+	case responseSaved, tea.KeyMsg:
+		if isActive {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p CertsPage) footer(help string) string {
+	switch {
+	case p.uploading:
+		return p.input.View() + "\n" + p.help.ShortHelpView([]key.Binding{p.keys.Confirm, p.keys.Cancel})
+	case p.confirmation.Asking():
+		return p.confirmation.View()
+	case p.message != "":
+		return styles.ComplementStyle.Render(p.message) + "\n" + help
+	}
+
+	return help
+}
+
+// refresh fetches the certificates and the details of the shown one
+func (p CertsPage) refresh() tea.Cmd {
+	if p.details == "" {
+		return fetchCerts(p.socket)
+	}
+
+	return tea.Batch(fetchCerts(p.socket), fetchCertDetails(p.socket, p.details))
+}
+
+// updateRows shows the certificates, commit and abort are offered during a transaction
+func (p *CertsPage) updateRows() {
+	p.keys.Commit.SetEnabled(p.transaction != nil)
+	p.keys.Abort.SetEnabled(p.transaction != nil)
+	// the table keeps copies of the help keys, so the enabled keys must be handed over again
+	table.WithAdditionalShortHelpKeys(certsHelpKeys(p.keys))(&p.table)
+
+	now := time.Now()
+	rows := make([]table.Row, len(p.certs))
+	for i, c := range p.certs {
+		rows[i] = table.Row{c.File, c.CommonName(), strings.Join(c.SANs, ", "), c.IssuerName(), certExpiry(c, now)}
+	}
+	p.table.SetRows(rows)
+	p.table = recalculateTableSize(p.table)
+	// the table leaves the cursor at -1 while it is empty
+	p.table.SetCursor(min(max(p.table.Cursor(), 0), len(rows)-1))
+}
+
+// selected is the certificate of the selected row
+func (p CertsPage) selected() *haproxy.Certificate {
+	cursor := p.table.Cursor()
+	if cursor < 0 || cursor >= len(p.certs) {
+		return nil
+	}
+
+	return &p.certs[cursor]
+}
+
+func ActivateCertsPageCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ActivateCertsPage(true)
+	}
+}
+
+func createCertsKeyMap() certsPageKeyMap {
+	km := certsPageKeyMap{
+		listKeyMap: createListKeyMap("certificates", "details"),
+		Upload: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "upload new"),
+		),
+		Commit: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "commit"),
+			key.WithDisabled(),
+		),
+		Abort: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "abort"),
+			key.WithDisabled(),
+		),
+		confirmationKeyMap: createConfirmationKeyMap(),
+	}
+	// the details close with esc as well
+	km.Close.SetKeys("backspace", "esc")
+
+	return km
+}
+
+func certsHelpKeys(km certsPageKeyMap) []key.Binding {
+	return []key.Binding{km.Open, km.Upload, km.Commit, km.Abort, km.Refresh, km.GotoStatusPage}
+}
+
+// certExpiry is the expiry date with the days left, flagged when it is close or past
+func certExpiry(c haproxy.Certificate, now time.Time) string {
+	if c.NotAfter.IsZero() {
+		return "-"
+	}
+
+	date := c.NotAfter.Format(time.DateOnly)
+	switch {
+	case !c.NotAfter.After(now):
+		return styles.DownStyle.Render(date + " (expired)")
+	case c.ExpiresWithin(certExpiryWarning, now):
+		return styles.DegradedStyle.Render(fmt.Sprintf("%s (%dd)", date, int(c.NotAfter.Sub(now).Hours()/24)))
+	}
+
+	return fmt.Sprintf("%s (%dd)", date, int(c.NotAfter.Sub(now).Hours()/24))
+}
+
+// fetchCerts fetches the details of every certificate file and of the ongoing transaction,
+// bundles which HAProxy can't display are listed without details
+func fetchCerts(s *socket.Client) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		list, err := execChecked(ctx, s, "show ssl cert")
+		if err != nil {
+			return err
+		}
+
+		var res certList
+		for _, file := range haproxy.ParseCertFiles(list) {
+			// bundles and certificates whose details do not parse are listed without details
+			cert := haproxy.Certificate{File: file}
+			if out, err := execChecked(ctx, s, "show ssl cert "+file); err == nil {
+				if parsed, err := haproxy.ParseCertificate(out); err == nil {
+					cert = parsed
+				}
+			}
+			res.certs = append(res.certs, cert)
+		}
+
+		if file := haproxy.ParseCertTransaction(list); file != "" {
+			out, err := execChecked(ctx, s, "show ssl cert *"+file)
+			if err != nil {
+				return err
+			}
+			cert, err := haproxy.ParseCertificate(out)
+			if err != nil {
+				return err
+			}
+			// the uncommitted certificate is named `*<file>`
+			cert.File = file
+			res.transaction = &cert
+		}
+
+		return res
+	}
+}
+
+func fetchCertDetails(s *socket.Client, file string) tea.Cmd {
+	return socket.ExecCmd[certDetails](
+		context.Background(),
+		s,
+		"show ssl cert "+file,
+		func(out *string) (certDetails, error) { return certDetails{file: file, dump: *out}, nil },
+	)
+}
+
+// uploadCert starts a transaction replacing the certificate file with the PEM file on disk
+func uploadCert(s *socket.Client, file string, path string) tea.Cmd {
+	return func() tea.Msg {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(content), "-----BEGIN CERTIFICATE-----") {
+			return fmt.Errorf("%s contains no PEM certificate", path)
+		}
+
+		// an empty line ends the payload, so the PEM may not contain any
+		var lines []string
+		for _, l := range strings.Split(strings.ReplaceAll(string(content), "\r", ""), "\n") {
+			if strings.TrimSpace(l) != "" {
+				lines = append(lines, l)
+			}
+		}
+
+		res, err := execChecked(context.Background(), s, fmt.Sprintf("set ssl cert %s <<\n%s\n", file, strings.Join(lines, "\n")))
+		if err != nil {
+			return err
+		}
+
+		return CertChanged(strings.TrimSpace(res))
+	}
+}
+
+func commitCert(s *socket.Client, file string) tea.Cmd {
+	return func() tea.Msg {
+		command := "commit ssl cert " + file
+		res, err := execChecked(context.Background(), s, command)
+		if err != nil {
+			return err
+		}
+		// HAProxy reports the progress of the commit, followed by its outcome
+		if !strings.Contains(res, "Success!") {
+			message := "the commit failed"
+			if lines := nonEmptyLines(res); len(lines) > 0 {
+				message = lines[len(lines)-1]
+			}
+			return haproxy.ResponseError{Command: command, Message: message}
+		}
+
+		return CertChanged("committed the new certificate of " + file)
+	}
+}
+
+func abortCert(s *socket.Client, file string) tea.Cmd {
+	return changeCmd[CertChanged](s, "abort ssl cert "+file, "aborted the transaction for "+file)
+}
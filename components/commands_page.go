@@ -1,6 +1,7 @@
 package components
 
 import (
+	"context"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
@@ -8,12 +9,11 @@ import (
 	"haproxy-runtime-cli/haproxy"
 	"haproxy-runtime-cli/socket"
 	"haproxy-runtime-cli/styles"
-	"net"
 )
 
 type CommandsPage struct {
 	commands list.Model
-	socket   func() net.Conn
+	socket   *socket.Client
 	keys     commandsPageKeyMap
 }
 
@@ -24,7 +24,7 @@ type commandsPageKeyMap struct {
 
 type ActivateCommandsPage bool
 
-func NewCommandsPage(socket func() net.Conn) CommandsPage {
+func NewCommandsPage(socket *socket.Client) CommandsPage {
 	keys := createCommandsKeyMap()
 	return CommandsPage{
 		socket:   socket,
@@ -90,12 +90,6 @@ func ActivateCommandsPageCmd() tea.Cmd {
 	}
 }
 
-func errorCmd(err error) tea.Cmd {
-	return func() tea.Msg {
-		return err
-	}
-}
-
 func createCommandsKeyMap() commandsPageKeyMap {
 	return commandsPageKeyMap{
 		GotoStatusPage: key.NewBinding(
@@ -149,11 +143,11 @@ func resizeList(model *list.Model, msg tea.WindowSizeMsg) {
 	model.SetSize(msg.Width, msg.Height-(hv*2))
 }
 
-func fetchHelpCommand(sock func() net.Conn) tea.Cmd {
+func fetchHelpCommand(sock *socket.Client) tea.Cmd {
 	return func() tea.Msg {
-		out, err := socket.Exec(sock, "help")
+		out, err := socket.Exec(context.Background(), sock, "help")
 		if err != nil {
-			return errorCmd(err)
+			return err
 		}
 		return haproxy.ParseHelp(out)
 	}
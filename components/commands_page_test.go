@@ -26,7 +26,7 @@ func TestCommandsPage(t *testing.T) {
 			Output: []byte("The following commands are valid at this level\nhelp : foo"),
 		}
 
-		return NewCommandsPage(func() net.Conn { return conn })
+		return NewCommandsPage(socket.NewClient(func() (net.Conn, error) { return conn, nil }))
 	}
 
 	parsedModel := func() CommandsPage {
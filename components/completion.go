@@ -0,0 +1,157 @@
+package components
+
+import (
+	"context"
+	tea "github.com/charmbracelet/bubbletea"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// completionSource is the runtime state the names of an argument are fetched from
+type completionSource int
+
+const (
+	noSource completionSource = iota
+	serverSource
+	backendSource
+	mapSource
+	aclSource
+	tableSource
+	certSource
+)
+
+// completionNames are the names of a source, fetched when the selected command has an argument taking them
+type completionNames struct {
+	source completionSource
+	names  []string
+}
+
+// keywordPattern matches the keywords of a usage worth completing, like `auto` or `no-maint`
+var keywordPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// argumentSource returns the source of an argument of the usage of a command,
+// `get map <acl> <value>` is one of the commands naming a map argument <acl>
+func argumentSource(command string, argument string) completionSource {
+	if strings.Contains(argument, "/") {
+		return serverSource
+	}
+
+	switch strings.Trim(argument, "<>[") {
+	case "bk", "backend":
+		return backendSource
+	case "map":
+		return mapSource
+	case "acl":
+		if strings.Contains(command, " map") {
+			return mapSource
+		}
+		return aclSource
+	case "table":
+		return tableSource
+	case "certfile", "cert":
+		return certSource
+	}
+
+	return noSource
+}
+
+// fetchCompletions fetches the names of every source the arguments of the command take
+func fetchCompletions(s *socket.Client, command haproxy.Command, g haproxy.Grammar) tea.Cmd {
+	var sources []completionSource
+	for _, t := range g.Tokens() {
+		source := argumentSource(command.Name, t.Name)
+		if source != noSource && !slices.Contains(sources, source) {
+			sources = append(sources, source)
+		}
+	}
+
+	var cmds []tea.Cmd
+	for _, source := range sources {
+		cmds = append(cmds, fetchNames(s, source))
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// fetchNames is best effort, completion is just left out if the socket lacks the level for a command
+func fetchNames(s *socket.Client, source completionSource) tea.Cmd {
+	command := map[completionSource]string{
+		serverSource:  "show servers state",
+		backendSource: "show servers state",
+		mapSource:     "show map",
+		aclSource:     "show acl",
+		tableSource:   "show table",
+		certSource:    "show ssl cert",
+	}[source]
+
+	return func() tea.Msg {
+		res, err := socket.Exec(context.Background(), s, command)
+		if err != nil || haproxy.CheckResponse(command, *res) != nil {
+			return nil
+		}
+
+		return completionNames{source: source, names: parseNames(source, *res)}
+	}
+}
+
+func parseNames(source completionSource, out string) []string {
+	var names []string
+
+	switch source {
+	case serverSource, backendSource:
+		backends, _ := haproxy.ParseBackends(out)
+		for _, b := range backends {
+			if source == backendSource {
+				names = append(names, b.Name)
+				continue
+			}
+			for _, s := range b.Servers {
+				names = append(names, b.Name+"/"+s.Name)
+			}
+		}
+	case mapSource, aclSource:
+		lists, _ := haproxy.ParsePatternLists(out)
+		for _, l := range lists {
+			names = append(names, l.Name())
+		}
+	case tableSource:
+		tables, _ := haproxy.ParseTables(out)
+		for _, t := range tables {
+			names = append(names, t.Name)
+		}
+	case certSource:
+		names = haproxy.ParseCertFiles(out)
+	}
+
+	return names
+}
+
+// suggestions completes the argument being typed, with the names of its source or the keywords the usage expects.
+// The suggestions are whole input values, as the text input matches them against everything typed.
+func suggestions(command string, g haproxy.Grammar, names map[completionSource][]string, value string) []string {
+	args := strings.Fields(value)
+	typed := value
+	if len(args) > 0 && !strings.HasSuffix(value, " ") {
+		// the last argument is still being typed
+		typed = strings.TrimSuffix(value, args[len(args)-1])
+		args = args[:len(args)-1]
+	}
+
+	var res []string
+	for _, t := range g.Expected(args) {
+		candidates := names[argumentSource(command, t.Name)]
+		if argumentSource(command, t.Name) == noSource && keywordPattern.MatchString(t.Name) {
+			candidates = []string{t.Name}
+		}
+		for _, c := range candidates {
+			if !slices.Contains(res, typed+c) {
+				res = append(res, typed+c)
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,51 @@
+package components
+
+import (
+	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"net"
+	"testing"
+)
+
+func TestCompletion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Argument Source", func(t *testing.T) {
+		assert.Equal(t, serverSource, argumentSource("set server", "<bk>/<srv>"))
+		assert.Equal(t, backendSource, argumentSource("show servers state", "<backend>"))
+		assert.Equal(t, mapSource, argumentSource("show map", "map"))
+		assert.Equal(t, mapSource, argumentSource("get map", "<acl>"))
+		assert.Equal(t, aclSource, argumentSource("get acl", "<acl>"))
+		assert.Equal(t, tableSource, argumentSource("show table", "<table>"))
+		assert.Equal(t, certSource, argumentSource("show ssl cert", "<certfile>"))
+		assert.Equal(t, noSource, argumentSource("echo", "<text>"))
+	})
+
+	t.Run("Fetch Names", func(t *testing.T) {
+		c := fakeClient(t)
+
+		assert.Equal(t, completionNames{source: backendSource, names: []string{"default", "other"}}, fetchNames(c, backendSource)())
+		assert.Equal(t, completionNames{source: mapSource, names: []string{"/etc/haproxy/maps/hosts.map"}}, fetchNames(c, mapSource)())
+		assert.Equal(t, completionNames{source: aclSource, names: []string{"/etc/haproxy/acl/blocklist.acl", "#1"}}, fetchNames(c, aclSource)())
+		assert.Equal(t, completionNames{source: tableSource, names: []string{"http-in"}}, fetchNames(c, tableSource)())
+		assert.Len(t, fetchNames(c, certSource)().(completionNames).names, 2)
+	})
+
+	t.Run("Fetch Names Permission Denied", func(t *testing.T) {
+		conn := &socket.DummySocket{Output: []byte("Permission denied")}
+		c := socket.NewClient(func() (net.Conn, error) { return conn, nil })
+
+		assert.Nil(t, fetchNames(c, mapSource)())
+	})
+
+	t.Run("Suggestions", func(t *testing.T) {
+		g := haproxy.ParseGrammar("<what> {auto|on|off}")
+		assert.Equal(t, []string{"all auto", "all on", "all off"}, suggestions("set profiling", g, nil, "all o"))
+
+		g = haproxy.ParseGrammar("[@<ver>] <map> <key> <val>")
+		names := map[completionSource][]string{mapSource: {"/etc/hosts.map"}}
+		assert.Equal(t, []string{"/etc/hosts.map"}, suggestions("add map", g, names, ""))
+		assert.Empty(t, suggestions("add map", g, names, "/etc/hosts.map "))
+	})
+}
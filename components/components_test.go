@@ -0,0 +1,55 @@
+package components
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/fake"
+	"haproxy-runtime-cli/socket"
+	"testing"
+)
+
+// fakeServer starts a fake HAProxy, which is closed once the test is done
+func fakeServer(t *testing.T) *fake.Server {
+	srv, err := fake.NewServer()
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+
+	return srv
+}
+
+// fakeClient connects to a fake HAProxy started for the test
+func fakeClient(t *testing.T) *socket.Client {
+	return dial(t, fakeServer(t))
+}
+
+// dial connects to the given fake HAProxy, for tests changing it behind the back of the client
+func dial(t *testing.T, srv *fake.Server) *socket.Client {
+	endpoint, err := socket.ParseEndpoint(srv.Addr, socket.TLSOptions{})
+	assert.Nil(t, err)
+
+	return socket.NewClient(endpoint.Dial)
+}
+
+func keyMsg(r rune) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+}
+
+type page[P any] interface {
+	Update(msg tea.Msg) (P, tea.Cmd)
+}
+
+// apply runs the command of a change and the refresh following it, which may be a batch
+func apply[P page[P]](m P, cmd tea.Cmd) P {
+	m, cmd = m.Update(cmd())
+
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			m, _ = m.Update(c())
+		}
+		return m
+	}
+	m, _ = m.Update(msg)
+
+	return m
+}
@@ -0,0 +1,85 @@
+package components
+
+import (
+	"context"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"haproxy-runtime-cli/socket"
+	"haproxy-runtime-cli/styles"
+)
+
+// Confirmation asks before a change is sent, the command of the change runs once it is confirmed
+type Confirmation struct {
+	question string
+	command  tea.Cmd
+	keys     confirmationKeyMap
+	help     help.Model
+}
+
+type confirmationKeyMap struct {
+	Confirm key.Binding
+	Cancel  key.Binding
+}
+
+func NewConfirmation() Confirmation {
+	return Confirmation{
+		keys: createConfirmationKeyMap(),
+		help: help.New(),
+	}
+}
+
+// Ask shows the question until the command is confirmed or cancelled
+func (c Confirmation) Ask(question string, command tea.Cmd) Confirmation {
+	c.question = question
+	c.command = command
+
+	return c
+}
+
+// Asking reports whether a question waits for an answer, the page passes its keys to the confirmation meanwhile
+func (c Confirmation) Asking() bool {
+	return c.question != ""
+}
+
+func (c Confirmation) Update(msg tea.KeyMsg) (Confirmation, tea.Cmd) {
+	command := c.command
+
+	switch {
+	case key.Matches(msg, c.keys.Confirm):
+		c.question, c.command = "", nil
+		return c, command
+	case key.Matches(msg, c.keys.Cancel):
+		c.question, c.command = "", nil
+	}
+
+	return c, nil
+}
+
+func (c Confirmation) View() string {
+	return styles.ActiveStyle.Render(c.question+"?") + "\n" + c.help.ShortHelpView([]key.Binding{c.keys.Confirm, c.keys.Cancel})
+}
+
+// createConfirmationKeyMap are the keys of the confirmation, pages taking an input accept or cancel it with them as well
+func createConfirmationKeyMap() confirmationKeyMap {
+	return confirmationKeyMap{
+		Confirm: key.NewBinding(
+			key.WithKeys("y", "enter"),
+			key.WithHelp("y", "confirm"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("n", "esc"),
+			key.WithHelp("n", "cancel"),
+		),
+	}
+}
+
+// changeCmd sends the command of a change, the page is told with done (e.g. MapChanged) once HAProxy took it
+func changeCmd[T ~string](s *socket.Client, command string, done string) tea.Cmd {
+	return socket.ExecCmd[T](
+		context.Background(),
+		s,
+		command,
+		func(*string) (T, error) { return T(done), nil },
+	)
+}
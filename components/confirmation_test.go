@@ -0,0 +1,40 @@
+package components
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConfirmation(t *testing.T) {
+	t.Parallel()
+
+	changed := func() tea.Msg { return "changed" }
+
+	t.Run("Confirm", func(t *testing.T) {
+		c := NewConfirmation().Ask("change it", changed)
+		assert.True(t, c.Asking())
+		assert.Contains(t, c.View(), "change it?")
+		assert.Contains(t, c.View(), "y confirm")
+
+		c, cmd := c.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.False(t, c.Asking())
+		assert.Equal(t, "changed", cmd())
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		c := NewConfirmation().Ask("change it", changed)
+
+		c, cmd := c.Update(keyMsg('n'))
+		assert.False(t, c.Asking())
+		assert.Nil(t, cmd)
+	})
+
+	t.Run("Other Keys", func(t *testing.T) {
+		c := NewConfirmation().Ask("change it", changed)
+
+		c, cmd := c.Update(keyMsg('k'))
+		assert.True(t, c.Asking())
+		assert.Nil(t, cmd)
+	})
+}
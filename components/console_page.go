@@ -0,0 +1,286 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/history"
+	"haproxy-runtime-cli/socket"
+	"haproxy-runtime-cli/styles"
+	"strings"
+	"time"
+)
+
+// ConsolePage runs any command line typed, like the interactive mode of the cli
+type ConsolePage struct {
+	socket *socket.Client
+	keys   consolePageKeyMap
+	help   help.Model
+	input  textinput.Model
+	// transcript shows the executed command lines with their responses
+	transcript viewport.Model
+	lines      []string
+	// cancel interrupts the command line in flight, nil if none is running
+	cancel  context.CancelFunc
+	history *history.History
+	// commands of `help`, to show the help of the typed command
+	commands haproxy.ParsedHelp
+	// past counts the steps back through the history, 0 is the typed draft
+	past  int
+	draft string
+}
+
+type consolePageKeyMap struct {
+	Execute    key.Binding
+	Previous   key.Binding
+	Next       key.Binding
+	Clear      key.Binding
+	Scroll     key.Binding
+	Cancel     key.Binding
+	GotoStatus key.Binding
+}
+
+type ActivateConsolePage bool
+
+// consoleResponse is the response of a command line typed into the console
+type consoleResponse struct {
+	line     string
+	response string
+}
+
+// consoleFailed ends the command line in flight without a response, e.g. on a connection error
+type consoleFailed struct {
+	err error
+}
+
+func NewConsolePage(socket *socket.Client) ConsolePage {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.PromptStyle = styles.ComplementStyle
+	input.TextStyle = styles.ComplementStyle.Bold(false)
+	input.Placeholder = "show info; show stat"
+	input.Focus()
+
+	return ConsolePage{
+		socket:     socket,
+		keys:       createConsoleKeyMap(),
+		help:       help.New(),
+		input:      input,
+		transcript: viewport.New(0, 0),
+		history:    history.New(),
+	}
+}
+
+// SetHistory replaces the history, it is shared with the execute page
+func (c *ConsolePage) SetHistory(h *history.History) {
+	c.history = h
+}
+
+func (c ConsolePage) Init() tea.Cmd {
+	return nil
+}
+
+func (c ConsolePage) Update(msg tea.Msg) (ConsolePage, tea.Cmd) {
+	switch msg := msg.(type) {
+	case consoleResponse:
+		c.cancel = nil
+		c.keys.Cancel.SetEnabled(false)
+		c.record(msg.response)
+		if c.history != nil {
+			if err := c.history.Add(history.Entry{Command: msg.line, Response: msg.response, Time: time.Now()}); err != nil {
+				return c, func() tea.Msg { return err }
+			}
+		}
+		return c, nil
+	case consoleFailed:
+		c.cancel = nil
+		c.keys.Cancel.SetEnabled(false)
+		c.record(styles.ErrorBarStyle.Render(describeError(msg.err)))
+		return c, nil
+	case haproxy.ParsedHelp:
+		c.commands = msg
+		return c, nil
+	case tea.WindowSizeMsg:
+		c.transcript.Width = msg.Width - styles.PageStyle.GetHorizontalMargins()
+		c.transcript.Height = max(msg.Height-styles.PageStyle.GetVerticalMargins()-3-3-5, 1)
+		c.transcript.GotoBottom()
+		return c, nil
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, c.keys.Execute):
+			return c.execute()
+		case key.Matches(msg, c.keys.Cancel):
+			c.cancel()
+			c.cancel = nil
+			c.keys.Cancel.SetEnabled(false)
+			c.record("command cancelled")
+			return c, nil
+		case key.Matches(msg, c.keys.GotoStatus):
+			return c, ActivateStatusPageCmd()
+		case key.Matches(msg, c.keys.Previous):
+			return c.browse(c.past + 1), nil
+		case key.Matches(msg, c.keys.Next):
+			return c.browse(c.past - 1), nil
+		case key.Matches(msg, c.keys.Clear):
+			c.lines = nil
+			c.transcript.SetContent("")
+			return c, nil
+		case key.Matches(msg, c.keys.Scroll):
+			var cmd tea.Cmd
+			c.transcript, cmd = c.transcript.Update(msg)
+			return c, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	c.input, cmd = c.input.Update(msg)
+
+	return c, cmd
+}
+
+func (c ConsolePage) View() string {
+	keys := []key.Binding{c.keys.Execute, c.keys.Previous, c.keys.Scroll, c.keys.Clear, c.keys.Cancel}
+	if c.cancel == nil {
+		keys = append(keys, c.keys.GotoStatus)
+	}
+
+	return tblStyle.Render(c.transcript.View()) + "\n" +
+		c.input.View() + "\n" +
+		c.typedHelp() + "\n" +
+		c.help.ShortHelpView(keys)
+}
+
+func (c ConsolePage) Supports(msg tea.Msg, isActive bool) bool {
+	switch msg.(type) {
+	case consoleResponse, consoleFailed, haproxy.ParsedHelp, tea.WindowSizeMsg:
+		return true
+	case tea.KeyMsg:
+		if isActive {
+			return true
+		}
+	}
+
+	return false
+}
+
+// execute sends the typed line as is, HAProxy runs each command of a `;` separated chain
+func (c ConsolePage) execute() (ConsolePage, tea.Cmd) {
+	line := strings.TrimSpace(c.input.Value())
+	if line == "" || c.cancel != nil {
+		return c, nil
+	}
+
+	c.record(styles.ActiveStyle.Render("> " + line))
+	c.input.SetValue("")
+	c.past = 0
+
+	var ctx context.Context
+	ctx, c.cancel = context.WithCancel(context.Background())
+	c.keys.Cancel.SetEnabled(true)
+	s := c.socket
+
+	return c, func() tea.Msg {
+		res, err := socket.Exec(ctx, s, line)
+		if errors.Is(err, context.Canceled) {
+			return nil
+		} else if err != nil {
+			return consoleFailed{err: err}
+		}
+
+		return consoleResponse{line: line, response: *res}
+	}
+}
+
+// record appends to the transcript and scrolls to its end
+func (c *ConsolePage) record(text string) {
+	c.lines = append(c.lines, strings.TrimRight(text, "\n"))
+	c.transcript.SetContent(strings.Join(c.lines, "\n"))
+	c.transcript.GotoBottom()
+}
+
+// browse shows the command line executed the given steps back, 0 restores the draft
+func (c ConsolePage) browse(past int) ConsolePage {
+	if c.history == nil || past < 0 || past > len(c.history.Entries()) {
+		return c
+	}
+
+	if c.past == 0 {
+		c.draft = c.input.Value()
+	}
+	c.past = past
+
+	if past == 0 {
+		c.input.SetValue(c.draft)
+	} else {
+		entries := c.history.Entries()
+		c.input.SetValue(entries[len(entries)-past].Command)
+	}
+	c.input.CursorEnd()
+
+	return c
+}
+
+// typedHelp shows the help and usage of the command being typed, the last one of a chain
+func (c ConsolePage) typedHelp() string {
+	chain := strings.Split(c.input.Value(), ";")
+	line := strings.TrimSpace(chain[len(chain)-1])
+	if line == "" {
+		return ""
+	}
+
+	command, args := commandOf(c.commands, line)
+	if !c.commands.Contains(command.Name) {
+		return ""
+	}
+	if args != "" && strings.HasSuffix(c.input.Value(), " ") {
+		args += " "
+	}
+
+	return styles.ComplementStyle.Render(command.Name+" ") + strings.TrimSuffix(usage(haproxy.ParseGrammar(command.Args), args), "\n") +
+		"\n" + styles.ComplementStyle.Faint(true).Render(command.Help)
+}
+
+func ActivateConsolePageCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ActivateConsolePage(true)
+	}
+}
+
+func createConsoleKeyMap() consolePageKeyMap {
+	return consolePageKeyMap{
+		Execute: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "execute"),
+		),
+		Previous: key.NewBinding(
+			key.WithKeys("up"),
+			key.WithHelp("↑/↓", "history"),
+		),
+		Next: key.NewBinding(
+			key.WithKeys("down"),
+		),
+		Clear: key.NewBinding(
+			key.WithKeys("ctrl+l"),
+			key.WithHelp("ctrl+l", "clear"),
+		),
+		Scroll: key.NewBinding(
+			key.WithKeys("pgup", "pgdown"),
+			key.WithHelp("pgup/pgdown", "scroll"),
+		),
+		// only enabled while a command is running
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+			key.WithDisabled(),
+		),
+		GotoStatus: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "status page"),
+		),
+	}
+}
@@ -0,0 +1,139 @@
+package components
+
+import (
+	"errors"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/history"
+	"haproxy-runtime-cli/socket"
+	"net"
+	"testing"
+)
+
+func TestConsolePage(t *testing.T) {
+	t.Parallel()
+
+	fakeModel := func(t *testing.T) ConsolePage {
+		m := NewConsolePage(fakeClient(t))
+		m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+
+		return m
+	}
+
+	typeLine := func(m ConsolePage, line string) ConsolePage {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(line)})
+		return m
+	}
+
+	t.Run("New", func(t *testing.T) {
+		m := NewConsolePage(nil)
+		assert.NotNil(t, m.keys)
+		assert.Nil(t, m.Init())
+	})
+
+	t.Run("Update Execute", func(t *testing.T) {
+		m := typeLine(fakeModel(t), "show servers state other; get weight default/apache")
+
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Empty(t, m.input.Value())
+		assert.Contains(t, m.View(), "esc cancel")
+
+		m, _ = m.Update(cmd())
+		res := m.View()
+		assert.Contains(t, res, "> show servers state other; get weight default/apache")
+		assert.Contains(t, res, "5 other 1 haproxy")
+		assert.Contains(t, res, "80 (initial 80)")
+		assert.Contains(t, res, "esc status page")
+		assert.Equal(t, "show servers state other; get weight default/apache", m.history.Entries()[0].Command)
+	})
+
+	t.Run("Update Execute Empty", func(t *testing.T) {
+		_, cmd := fakeModel(t).Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Nil(t, cmd)
+	})
+
+	t.Run("Update Cancel", func(t *testing.T) {
+		m := typeLine(fakeModel(t), "show info")
+		m, execute := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.View(), "command cancelled")
+		assert.Nil(t, execute())
+
+		// without a running command esc leaves the console
+		_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.Equal(t, ActivateStatusPage(true), cmd())
+	})
+
+	t.Run("Update Execute Failed", func(t *testing.T) {
+		m := NewConsolePage(socket.NewClient(func() (net.Conn, error) { return nil, errors.New("connection refused") }))
+		m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+
+		m, execute := typeLine(m, "show info").Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, cmd := m.Update(execute())
+
+		// the failure is part of the transcript and the next line can be sent right away
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.View(), "connection refused")
+		assert.NotContains(t, m.View(), "esc cancel")
+		_, cmd = typeLine(m, "show stat").Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.NotNil(t, cmd)
+
+		_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.Equal(t, ActivateStatusPage(true), cmd())
+	})
+
+	t.Run("Update History", func(t *testing.T) {
+		m := fakeModel(t)
+		h := history.New()
+		_ = h.Add(history.Entry{Command: "show info"})
+		_ = h.Add(history.Entry{Command: "show stat"})
+		m.SetHistory(h)
+
+		m = typeLine(m, "draft")
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+		assert.Equal(t, "show stat", m.input.Value())
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+		assert.Equal(t, "show info", m.input.Value())
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		assert.Equal(t, "draft", m.input.Value())
+	})
+
+	t.Run("Update Clear", func(t *testing.T) {
+		m := typeLine(fakeModel(t), "help")
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, _ = m.Update(cmd())
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+		assert.NotContains(t, m.View(), "> help")
+	})
+
+	t.Run("View Typed Help", func(t *testing.T) {
+		m, _ := fakeModel(t).Update(haproxy.ParsedHelp{
+			{Name: "show info", Help: "report information about the running process"},
+			{Name: "set weight", Help: "change a server's weight", Args: "<bk>/<srv> <weight>"},
+		})
+
+		m = typeLine(m, "show info; set weight default/apache ")
+		res := m.View()
+		assert.Contains(t, res, "change a server's weight")
+		assert.Contains(t, res, "set weight <bk>/<srv> <weight>")
+		assert.NotContains(t, res, "report information")
+	})
+
+	t.Run("Supports", func(t *testing.T) {
+		m := NewConsolePage(nil)
+
+		assert.True(t, m.Supports(consoleResponse{}, false))
+		assert.True(t, m.Supports(haproxy.ParsedHelp{}, false))
+		assert.True(t, m.Supports(tea.WindowSizeMsg{}, false))
+		assert.True(t, m.Supports(tea.KeyMsg{}, true))
+		assert.False(t, m.Supports(tea.KeyMsg{}, false))
+	})
+}
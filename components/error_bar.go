@@ -0,0 +1,97 @@
+package components
+
+import (
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"haproxy-runtime-cli/styles"
+	"strings"
+)
+
+// ErrorBar shows the latest error on top of every page, until it is dismissed
+type ErrorBar struct {
+	err  error
+	keys errorBarKeyMap
+	help help.Model
+}
+
+type errorBarKeyMap struct {
+	Dismiss key.Binding
+}
+
+func NewErrorBar() ErrorBar {
+	return ErrorBar{
+		keys: errorBarKeyMap{
+			Dismiss: key.NewBinding(
+				key.WithKeys("esc"),
+				key.WithHelp("esc", "dismiss"),
+			),
+		},
+		help: help.New(),
+	}
+}
+
+func (e ErrorBar) Init() tea.Cmd {
+	return nil
+}
+
+func (e ErrorBar) Update(msg tea.Msg) (ErrorBar, tea.Cmd) {
+	switch msg := msg.(type) {
+	case error:
+		e.err = msg
+	case tea.KeyMsg:
+		if key.Matches(msg, e.keys.Dismiss) {
+			e.err = nil
+		}
+	}
+
+	return e, nil
+}
+
+func (e ErrorBar) View() string {
+	if e.err == nil {
+		return ""
+	}
+
+	return styles.ErrorBarStyle.Render(describeError(e.err)+" ") + e.help.ShortHelpView([]key.Binding{e.keys.Dismiss})
+}
+
+// Supports all errors, and the dismiss key while an error is shown
+func (e ErrorBar) Supports(msg tea.Msg, _ bool) bool {
+	switch msg := msg.(type) {
+	case error:
+		return true
+	case tea.KeyMsg:
+		return e.err != nil && key.Matches(msg, e.keys.Dismiss)
+	}
+
+	return false
+}
+
+func describeError(err error) string {
+	var connectionErr socket.ConnectionError
+	var parseErr haproxy.ParseError
+
+	lines := strings.Split(err.Error(), "\n")
+	msg := lines[0]
+	if len(lines) > 1 {
+		msg = fmt.Sprintf("%s (and %d more)", msg, len(lines)-1)
+	}
+
+	switch {
+	case errors.As(err, &connectionErr):
+		return msg + ", is HAProxy running?"
+	case errors.Is(err, haproxy.ErrPermissionDenied):
+		return msg + ", the level of the socket is too low"
+	case errors.Is(err, haproxy.ErrUnknownCommand):
+		return msg + ", not supported by this HAProxy version"
+	case errors.As(err, &parseErr):
+		return "malformed response, " + msg
+	}
+
+	return msg
+}
@@ -0,0 +1,56 @@
+package components
+
+import (
+	"errors"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"testing"
+)
+
+func TestErrorBar(t *testing.T) {
+	t.Parallel()
+
+	t.Run("New", func(t *testing.T) {
+		m := NewErrorBar()
+		assert.Nil(t, m.Init())
+		assert.Equal(t, "", m.View())
+	})
+
+	t.Run("Update Error", func(t *testing.T) {
+		m, cmd := NewErrorBar().Update(errors.New("boom"))
+
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.View(), "boom")
+		assert.Contains(t, m.View(), "esc dismiss")
+	})
+
+	t.Run("Update Dismiss", func(t *testing.T) {
+		m, _ := NewErrorBar().Update(errors.New("boom"))
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Equal(t, "", m.View())
+	})
+
+	t.Run("Describe Errors", func(t *testing.T) {
+		assert.Equal(t, "connection to haproxy failed: refused, is HAProxy running?", describeError(socket.ConnectionError{Err: errors.New("refused")}))
+		assert.Equal(t, "disable health default/apache: Permission denied, the level of the socket is too low", describeError(haproxy.ResponseError{Command: "disable health default/apache", Message: "Permission denied"}))
+		assert.Equal(t, "show foo: Unknown command: 'show foo'., not supported by this HAProxy version", describeError(haproxy.ResponseError{Command: "show foo", Message: "Unknown command: 'show foo'."}))
+		assert.Equal(t, `malformed response, failed to parse "a b": invalid (and 1 more)`, describeError(errors.Join(
+			haproxy.ParseError{Line: "a b", Err: errors.New("invalid")},
+			haproxy.ParseError{Line: "c d", Err: errors.New("invalid")},
+		)))
+	})
+
+	t.Run("Supports", func(t *testing.T) {
+		m := NewErrorBar()
+
+		assert.True(t, m.Supports(errors.New("boom"), false))
+		assert.False(t, m.Supports(tea.KeyMsg{Type: tea.KeyEsc}, true))
+
+		m, _ = m.Update(errors.New("boom"))
+		assert.True(t, m.Supports(tea.KeyMsg{Type: tea.KeyEsc}, false))
+		assert.False(t, m.Supports(tea.KeyMsg{Type: tea.KeyEnter}, false))
+	})
+}
@@ -1,47 +1,94 @@
 package components
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/history"
 	"haproxy-runtime-cli/socket"
 	"haproxy-runtime-cli/styles"
-	"net"
+	"slices"
 	"strings"
+	"time"
 )
 
 type ExecutePage struct {
-	command  haproxy.Command
-	socket   func() net.Conn
-	keys     executePageKeyMap
-	help     help.Model
-	input    textinput.Model
-	response ExecuteResponse
+	command haproxy.Command
+	// grammar of the arguments of the command, to validate them before sending
+	grammar haproxy.Grammar
+	problem string
+	// names of backends, maps, etc. to complete the arguments with
+	names  map[completionSource][]string
+	socket *socket.Client
+	keys   executePageKeyMap
+	help   help.Model
+	input  textinput.Model
+	// viewer scrolls, searches and formats the response
+	viewer ResponseViewer
+	// cancel interrupts the command in flight, nil if none is running
+	cancel context.CancelFunc
+	// running is the command line in flight, kept in the history with its response
+	running string
+
+	history *history.History
+	// commands are the commands of `help`, to find the command of a line of the history
+	commands haproxy.ParsedHelp
+	// past counts the steps back through the history of the command, 0 is the typed draft
+	past  int
+	draft string
+	// recalled is the time of the history entry shown as response
+	recalled time.Time
+	// search is the reverse search over the whole history, found is the index of the matched entry
+	search textinput.Model
+	found  int
 }
 
 type executePageKeyMap struct {
 	GotoCommands key.Binding
 	Execute      key.Binding
+	Cancel       key.Binding
+	Previous     key.Binding
+	Next         key.Binding
+	Search       key.Binding
+	// Complete is handled by the text input, it is only listed for the help
+	Complete key.Binding
 }
 
 type ExecuteResponse string
 
+// executeFailed ends the command in flight without a response, the error is passed on to the error bar
+type executeFailed struct {
+	err error
+}
+
 type ActivateExecutePage bool
 
-func NewExecutePage(socket func() net.Conn) ExecutePage {
+func NewExecutePage(socket *socket.Client) ExecutePage {
 	ti := createInput()
+	search := textinput.New()
+	search.Prompt = "(reverse-i-search) "
+	search.PromptStyle = styles.ComplementStyle.MarginTop(1)
 
 	return ExecutePage{
-		socket: socket,
-		keys:   createExecuteKeyMap(),
-		help:   help.New(),
-		input:  ti,
+		socket:  socket,
+		keys:    createExecuteKeyMap(),
+		help:    help.New(),
+		input:   ti,
+		viewer:  NewResponseViewer(),
+		history: history.New(),
+		search:  search,
 	}
 }
 
+// SetHistory replaces the history, e.g. with the persisted one of the socket
+func (e *ExecutePage) SetHistory(h *history.History) {
+	e.history = h
+}
+
 func (e ExecutePage) Init() tea.Cmd {
 	return nil
 }
@@ -49,48 +96,263 @@ func (e ExecutePage) Init() tea.Cmd {
 func (e ExecutePage) Update(msg tea.Msg) (ExecutePage, tea.Cmd) {
 	switch msg := msg.(type) {
 	case ExecuteResponse:
-		e.response = msg
+		e.viewer.SetResponse(string(msg))
+		e.recalled = time.Time{}
+		e.cancel = nil
+		e.keys.Cancel.SetEnabled(false)
+		if e.history != nil && e.running != "" {
+			err := e.history.Add(history.Entry{Command: e.running, Response: string(msg), Time: time.Now()})
+			e.running = ""
+			if err != nil {
+				return e, func() tea.Msg { return err }
+			}
+		}
+	case executeFailed:
+		e.cancel = nil
+		e.running = ""
+		e.keys.Cancel.SetEnabled(false)
+		e.viewer.SetResponse("")
+		e.recalled = time.Time{}
+		return e, func() tea.Msg { return msg.err }
+	case haproxy.ParsedHelp:
+		e.commands = msg
+		return e, nil
 	case haproxy.Command:
-		e.command = msg
-		e.input.Prompt = e.command.Name + " "
-		e.input.Placeholder = e.command.Args
-		e.input.SetValue("")
+		return e.selectCommand(msg)
+	case completionNames:
+		e.names[msg.source] = msg.names
+		e.input.SetSuggestions(suggestions(e.command.Name, e.grammar, e.names, e.input.Value()))
+		return e, nil
+	case responseSaved:
+		var cmd tea.Cmd
+		e.viewer, cmd = e.viewer.Update(msg)
+		return e, cmd
+	case tea.WindowSizeMsg:
+		// the description, input, usage, problem, history note and help around the response
+		e.viewer.SetSize(msg.Width-styles.PageStyle.GetHorizontalMargins(), msg.Height-styles.PageStyle.GetVerticalMargins()-3-3-12)
+		return e, nil
 	case tea.KeyMsg:
+		if e.search.Focused() {
+			return e.updateSearch(msg)
+		}
+		if e.viewer.Handles(msg) {
+			var cmd tea.Cmd
+			e.viewer, cmd = e.viewer.Update(msg)
+			return e, cmd
+		}
+
 		switch {
 		case key.Matches(msg, e.keys.GotoCommands):
 			if e.input.Value() == "" {
 				return e, ActivateCommandsPageCmd()
 			}
 		case key.Matches(msg, e.keys.Execute):
-			//TODO properly execute command and show result
-			return e, executeCommand(e)
+			return e.execute()
+		case key.Matches(msg, e.keys.Cancel):
+			e.cancel()
+			e.cancel = nil
+			e.running = ""
+			e.keys.Cancel.SetEnabled(false)
+			e.viewer.SetResponse("command cancelled")
+			return e, nil
+		case key.Matches(msg, e.keys.Previous):
+			return e.browse(e.past + 1), nil
+		case key.Matches(msg, e.keys.Next):
+			return e.browse(e.past - 1), nil
+		case key.Matches(msg, e.keys.Search) && e.history != nil:
+			e.search.SetValue("")
+			e.found = -1
+			return e, e.search.Focus()
+		default:
+			e.problem = ""
 		}
 	}
 
 	var cmd tea.Cmd
 	e.input, cmd = e.input.Update(msg)
+	e.input.SetSuggestions(suggestions(e.command.Name, e.grammar, e.names, e.input.Value()))
+
+	return e, cmd
+}
+
+// selectCommand switches to a command with an empty input
+func (e ExecutePage) selectCommand(c haproxy.Command) (ExecutePage, tea.Cmd) {
+	e.command = c
+	e.input.Prompt = e.command.Name + " "
+	e.input.Placeholder = e.command.Args
+	e.input.SetValue("")
+	e.grammar = haproxy.ParseGrammar(e.command.Args)
+	e.problem = ""
+	e.past = 0
+	e.names = map[completionSource][]string{}
+	e.input.SetSuggestions(nil)
+
+	return e, fetchCompletions(e.socket, e.command, e.grammar)
+}
+
+// execute sends the command once its arguments are complete and no other command is running
+func (e ExecutePage) execute() (ExecutePage, tea.Cmd) {
+	if e.cancel != nil {
+		e.problem = "a command is running, esc cancels it"
+		return e, nil
+	}
+	if err := e.grammar.Validate(strings.Fields(e.input.Value())); err != nil {
+		e.problem = err.Error()
+		return e, nil
+	}
+	e.problem = ""
+	e.past = 0
+
+	var ctx context.Context
+	ctx, e.cancel = context.WithCancel(context.Background())
+	e.keys.Cancel.SetEnabled(true)
+	e.running = e.line()
+
+	return e, executeCommand(ctx, e)
+}
+
+// line is the command line of the command and its arguments
+func (e ExecutePage) line() string {
+	return strings.TrimSpace(e.command.Name + " " + e.input.Value())
+}
+
+// pastEntries are the entries of the history of the selected command, oldest first
+func (e ExecutePage) pastEntries() []history.Entry {
+	if e.history == nil {
+		return nil
+	}
+
+	var res []history.Entry
+	for _, entry := range e.history.Entries() {
+		if c, _ := commandOf(e.commands, entry.Command); c.Name == e.command.Name {
+			res = append(res, entry)
+		}
+	}
+
+	return res
+}
+
+// browse shows the arguments and response of the command executed the given steps back, 0 restores the draft
+func (e ExecutePage) browse(past int) ExecutePage {
+	entries := e.pastEntries()
+	if past < 0 || past > len(entries) {
+		return e
+	}
+
+	if e.past == 0 {
+		e.draft = e.input.Value()
+	}
+	e.past = past
+	e.problem = ""
+
+	if past == 0 {
+		e.input.SetValue(e.draft)
+		e.recalled = time.Time{}
+		e.viewer.SetResponse("")
+		return e
+	}
+
+	entry := entries[len(entries)-past]
+	_, args := commandOf(e.commands, entry.Command)
+	e.input.SetValue(args)
+	e.input.CursorEnd()
+	e.viewer.SetResponse(entry.Response)
+	e.recalled = entry.Time
+
+	return e
+}
+
+// updateSearch searches the history while typing, ctrl+r finds older matches and enter runs the found command again
+func (e ExecutePage) updateSearch(msg tea.KeyMsg) (ExecutePage, tea.Cmd) {
+	switch {
+	case key.Matches(msg, e.keys.Search):
+		if i, ok := e.history.Search(e.search.Value(), e.found); ok {
+			e.found = i
+		}
+		return e, nil
+	case key.Matches(msg, e.keys.Execute):
+		e.search.Blur()
+		if e.found < 0 {
+			return e, nil
+		}
+		c, args := commandOf(e.commands, e.history.Entries()[e.found].Command)
+		e, fetch := e.selectCommand(c)
+		e.input.SetValue(args)
+		e, execute := e.execute()
+		return e, tea.Batch(fetch, execute)
+	case msg.Type == tea.KeyEsc:
+		e.search.Blur()
+		return e, nil
+	}
+
+	var cmd tea.Cmd
+	e.search, cmd = e.search.Update(msg)
+	e.found, _ = e.history.Search(e.search.Value(), len(e.history.Entries()))
 
 	return e, cmd
 }
 
-func executeCommand(e ExecutePage) func() tea.Msg {
-	return socket.ExecCmd[ExecuteResponse](
-		e.socket,
-		fmt.Sprintf("%s %s", e.command.Name, e.input.Value()),
-		func(s *string) ExecuteResponse { return ExecuteResponse(*s) },
-	)
+// commandOf splits a command line into the longest command of the help it starts with and its arguments,
+// without the help the whole line is the command
+func commandOf(commands haproxy.ParsedHelp, line string) (haproxy.Command, string) {
+	var res *haproxy.Command
+	for i, c := range commands {
+		if (line == c.Name || strings.HasPrefix(line, c.Name+" ")) && (res == nil || len(c.Name) > len(res.Name)) {
+			res = &commands[i]
+		}
+	}
+
+	if res == nil {
+		return haproxy.Command{Name: line}, ""
+	}
+
+	return *res, strings.TrimSpace(strings.TrimPrefix(line, res.Name))
+}
+
+// executeCommand shows the response as is, even if HAProxy reports a failure
+func executeCommand(ctx context.Context, e ExecutePage) func() tea.Msg {
+	command := e.line()
+
+	return func() tea.Msg {
+		res, err := socket.Exec(ctx, e.socket, command)
+		if errors.Is(err, context.Canceled) {
+			return nil
+		} else if err != nil {
+			return executeFailed{err: err}
+		}
+
+		return ExecuteResponse(*res)
+	}
 }
 
 func (e ExecutePage) View() string {
+	if e.search.Focused() {
+		found := ""
+		if e.found >= 0 {
+			found = e.history.Entries()[e.found].Command
+		}
+		return description(e.command) + "\n" +
+			e.search.View() + "  " + styles.ComplementStyle.Render(found) + "\n\n" +
+			e.help.ShortHelpView([]key.Binding{e.keys.Execute, e.keys.Search})
+	}
+
+	recalled := ""
+	if !e.recalled.IsZero() {
+		recalled = styles.ComplementStyle.Faint(true).Render("from history, "+e.recalled.Format(time.DateTime)) + "\n"
+	}
+
 	return description(e.command) + "\n" +
-		input(e.input) + "\n" +
-		response(e.response) + "\n" +
-		e.help.ShortHelpView([]key.Binding{e.keys.GotoCommands, e.keys.Execute})
+		e.input.View() + "\n" +
+		usage(e.grammar, e.input.Value()) +
+		problem(e.problem) + "\n" +
+		recalled +
+		styles.ResponseStyle.Render(e.viewer.View()) + "\n" +
+		e.help.ShortHelpView([]key.Binding{e.keys.GotoCommands, e.keys.Execute, e.keys.Complete, e.keys.Previous, e.keys.Search, e.keys.Cancel})
 }
 
 func (e ExecutePage) Supports(msg tea.Msg, isActive bool) bool {
 	switch msg.(type) {
-	case ExecuteResponse, haproxy.Command:
+	case ExecuteResponse, executeFailed, haproxy.Command, haproxy.ParsedHelp, completionNames, responseSaved, tea.WindowSizeMsg:
 		return true
 	case tea.KeyMsg:
 		if isActive {
@@ -117,32 +379,82 @@ func createExecuteKeyMap() executePageKeyMap {
 			key.WithKeys("enter"),
 			key.WithHelp("enter", "execute"),
 		),
+		Previous: key.NewBinding(
+			key.WithKeys("up"),
+			key.WithHelp("↑/↓", "history"),
+		),
+		Next: key.NewBinding(
+			key.WithKeys("down"),
+		),
+		Search: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "search history"),
+		),
+		Complete: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "complete"),
+		),
+		// only enabled while a command is running
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+			key.WithDisabled(),
+		),
+	}
+}
+
+// usage shows the arguments of the command with the one being typed highlighted
+func usage(g haproxy.Grammar, value string) string {
+	if g.Usage == "" {
+		return ""
+	}
+
+	args := strings.Fields(value)
+	if len(args) > 0 && !strings.HasSuffix(value, " ") {
+		// the last argument is still being typed
+		args = args[:len(args)-1]
+	}
+
+	tokens := g.Expected(args)
+	slices.SortFunc(tokens, func(a, b haproxy.Token) int { return a.Start - b.Start })
+
+	var b strings.Builder
+	pos := 0
+	for _, t := range tokens {
+		if t.Start < pos {
+			continue
+		}
+		b.WriteString(styles.ComplementStyle.Faint(true).Render(g.Usage[pos:t.Start]))
+		b.WriteString(styles.ActiveStyle.Render(g.Usage[t.Start:t.End]))
+		pos = t.End
 	}
+	b.WriteString(styles.ComplementStyle.Faint(true).Render(g.Usage[pos:]))
+
+	return b.String() + "\n"
 }
 
-func input(t textinput.Model) string {
-	total := strings.Count(t.Value(), " ")
-	curr := strings.Split(t.Placeholder, " ")
-	if total > len(curr) {
-		total = len(curr)
+func problem(p string) string {
+	if p == "" {
+		return ""
 	}
 
-	return t.View() + t.PlaceholderStyle.Render(strings.Join(curr[total:], " "))
+	return styles.ErrorBarStyle.Render(p) + "\n"
 }
 
 func description(c haproxy.Command) string {
 	return styles.ActiveStyle.MarginTop(1).Render(c.Help)
 }
 
-func response(r ExecuteResponse) string {
-	return styles.ResponseStyle.Render(string(r))
-}
-
 func createInput() textinput.Model {
 	ti := textinput.New()
 	//ti.Width = 80
 	ti.PromptStyle = styles.ComplementStyle.MarginTop(1)
 	ti.TextStyle = styles.ComplementStyle.Bold(false)
+	ti.ShowSuggestions = true
+	// up and down browse the history
+	ti.KeyMap.NextSuggestion = key.NewBinding(key.WithKeys("ctrl+n"))
+	ti.KeyMap.PrevSuggestion = key.NewBinding(key.WithKeys("ctrl+p"))
+	ti.CompletionStyle = styles.ComplementStyle.Faint(true)
 
 	ti.Focus()
 
@@ -1,9 +1,11 @@
 package components
 
 import (
+	"errors"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/stretchr/testify/assert"
 	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/history"
 	"haproxy-runtime-cli/socket"
 	"net"
 	"testing"
@@ -21,7 +23,7 @@ func TestExecutePage(t *testing.T) {
 			Output: []byte(`some commands response`),
 		}
 
-		return NewExecutePage(func() net.Conn { return conn })
+		return NewExecutePage(socket.NewClient(func() (net.Conn, error) { return conn, nil }))
 	}
 
 	t.Run("New", func(t *testing.T) {
@@ -49,11 +51,29 @@ func TestExecutePage(t *testing.T) {
 			Name: "foo", Help: "foo help text", Args: "<a>/<b>",
 		})
 
-		assert.Nil(t, cmd)
+		// <a>/<b> is completed with the servers
+		assert.NotNil(t, cmd)
 		assert.Equal(t, "foo ", m.input.Prompt)
 		assert.Equal(t, "<a>/<b>", m.input.Placeholder)
 	})
 
+	t.Run("Update Completion", func(t *testing.T) {
+		m, cmd := NewExecutePage(fakeClient(t)).Update(haproxy.Command{Name: "set server", Args: "<bk>/<srv> [opts]"})
+		m, _ = m.Update(cmd())
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("other/")})
+		assert.Equal(t, []string{"other/haproxy", "other/apache"}, m.input.MatchedSuggestions())
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+		assert.Equal(t, "other/apache", m.input.Value())
+	})
+
+	t.Run("Update Command without Completion", func(t *testing.T) {
+		_, cmd := socketModel().Update(haproxy.Command{Name: "echo", Args: "<text>"})
+
+		assert.Nil(t, cmd)
+	})
+
 	t.Run("Update Goto Commands Page", func(t *testing.T) {
 		_, cmd := socketModel().Update(tea.KeyMsg{Type: tea.KeyBackspace, Runes: []rune{}})
 
@@ -76,6 +96,76 @@ func TestExecutePage(t *testing.T) {
 		assert.Equal(t, ExecuteResponse("some commands response"), cmd())
 	})
 
+	t.Run("Update Execute Incomplete", func(t *testing.T) {
+		m, _ := socketModel().Update(haproxy.Command{Name: "add map", Args: "[@<ver>] <map> <key> <val>"})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m k")})
+
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.View(), "incomplete command, missing <val>")
+
+		// typing clears the problem
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" v")})
+		assert.NotContains(t, m.View(), "incomplete command")
+
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, ExecuteResponse("some commands response"), cmd())
+	})
+
+	t.Run("Update Cancel", func(t *testing.T) {
+		m, execute := socketModel().Update(tea.KeyMsg{Type: tea.KeyEnter, Runes: []rune{}})
+		assert.Contains(t, m.View(), "esc cancel")
+
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Nil(t, cmd)
+		assert.Nil(t, execute())
+		assert.Contains(t, m.View(), "command cancelled")
+		assert.NotContains(t, m.View(), "esc cancel")
+	})
+
+	t.Run("Update Execute while running", func(t *testing.T) {
+		m, _ := socketModel().Update(haproxy.Command{Name: "show stat", Args: "[desc|json|no-maint|typed|up]*"})
+		m.input.SetValue("json")
+		m, execute := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		m.input.SetValue("typed")
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.View(), "a command is running, esc cancels it")
+		assert.Equal(t, "show stat json", m.running)
+
+		// the response is kept with the command it belongs to
+		m, _ = m.Update(execute())
+		assert.Nil(t, m.cancel)
+		assert.Equal(t, "show stat json", m.history.Entries()[0].Command)
+	})
+
+	t.Run("Update Execute Failed", func(t *testing.T) {
+		m := NewExecutePage(socket.NewClient(func() (net.Conn, error) { return nil, errors.New("connection refused") }))
+
+		m, execute := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		msg := execute()
+		assert.IsType(t, executeFailed{}, msg)
+
+		m, cmd := m.Update(msg)
+
+		// the error is shown by the error bar and the page is ready for the next command
+		assert.ErrorAs(t, cmd().(error), &socket.ConnectionError{})
+		assert.Nil(t, m.cancel)
+		assert.Empty(t, m.running)
+		assert.NotContains(t, m.View(), "esc cancel")
+		_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.NotNil(t, cmd)
+	})
+
+	t.Run("Update Cancel without running command", func(t *testing.T) {
+		m, cmd := socketModel().Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Nil(t, cmd)
+		assert.NotContains(t, m.View(), "command cancelled")
+	})
+
 	t.Run("View", func(t *testing.T) {
 		m, _ := socketModel().Update(haproxy.Command{
 			Name: "foo", Help: "bar help text", Args: "<a>/<b>",
@@ -87,11 +177,115 @@ func TestExecutePage(t *testing.T) {
 		assert.Contains(t, res, "foo")
 	})
 
+	t.Run("View Usage", func(t *testing.T) {
+		m, _ := socketModel().Update(haproxy.Command{Name: "add map", Args: "[@<ver>] <map> <key> <val>"})
+
+		assert.Contains(t, m.View(), "<key>")
+		assert.Equal(t, "", usage(haproxy.Grammar{}, "foo"))
+	})
+
+	t.Run("Update History", func(t *testing.T) {
+		m, _ := socketModel().Update(haproxy.ParsedHelp{{Name: "show stat"}, {Name: "show info"}})
+		m, _ = m.Update(haproxy.Command{Name: "show stat", Args: "[desc|json|no-maint|typed|up]*"})
+
+		for _, args := range []string{"json", "typed"} {
+			m.input.SetValue(args)
+			var cmd tea.Cmd
+			m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			m, _ = m.Update(cmd())
+		}
+		m.history.Add(history.Entry{Command: "show info"})
+		assert.Len(t, m.history.Entries(), 3)
+		assert.Equal(t, "show stat typed", m.history.Entries()[1].Command)
+
+		m.input.SetValue("draft")
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+		assert.Equal(t, "typed", m.input.Value())
+		assert.Contains(t, m.View(), "from history")
+		assert.Contains(t, m.View(), "some commands response")
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+		assert.Equal(t, "json", m.input.Value())
+		// the other command is skipped
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+		assert.Equal(t, "json", m.input.Value())
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		assert.Equal(t, "draft", m.input.Value())
+		assert.NotContains(t, m.View(), "from history")
+	})
+
+	t.Run("Update History Search", func(t *testing.T) {
+		m, _ := socketModel().Update(haproxy.ParsedHelp{{Name: "show stat", Args: "[desc|json|no-maint|typed|up]*"}, {Name: "show info"}})
+		for _, c := range []string{"show stat json", "show info", "show stat typed"} {
+			m.history.Add(history.Entry{Command: c})
+		}
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("stat")})
+		assert.Contains(t, m.View(), "show stat typed")
+
+		// older matches
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+		assert.Contains(t, m.View(), "show stat json")
+
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, "show stat", m.command.Name)
+		assert.Equal(t, "json", m.input.Value())
+		assert.Equal(t, "show stat json", m.running)
+		assert.NotNil(t, cmd)
+	})
+
+	t.Run("Update History Search Cancel", func(t *testing.T) {
+		m, _ := socketModel().Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("nothing")})
+		assert.Contains(t, m.View(), "reverse-i-search")
+
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Nil(t, cmd)
+		assert.NotContains(t, m.View(), "reverse-i-search")
+	})
+
+	t.Run("Command Of", func(t *testing.T) {
+		commands := haproxy.ParsedHelp{{Name: "show servers state"}, {Name: "show servers conn"}, {Name: "show stat"}}
+
+		c, args := commandOf(commands, "show servers state default")
+		assert.Equal(t, "show servers state", c.Name)
+		assert.Equal(t, "default", args)
+
+		c, args = commandOf(commands, "show stat")
+		assert.Equal(t, "show stat", c.Name)
+		assert.Empty(t, args)
+
+		c, _ = commandOf(nil, "show info")
+		assert.Equal(t, "show info", c.Name)
+	})
+
+	t.Run("Update Response Viewer", func(t *testing.T) {
+		m, _ := socketModel().Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+		m, _ = m.Update(ExecuteResponse("Name: HAProxy\nVersion: 2.9.4\n"))
+		assert.Contains(t, m.View(), "key-value")
+
+		// the viewer takes its keys, other keys still go to the input
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("version")})
+		assert.Empty(t, m.input.Value())
+		assert.Contains(t, m.View(), "1 matching lines")
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+		assert.Equal(t, "a", m.input.Value())
+	})
+
 	t.Run("Supports", func(t *testing.T) {
 		m := model()
 
 		assert.True(t, m.Supports(ExecuteResponse(""), false))
 		assert.True(t, m.Supports(haproxy.Command{}, false))
+		assert.True(t, m.Supports(haproxy.ParsedHelp{}, false))
+		assert.True(t, m.Supports(tea.WindowSizeMsg{}, false))
+		assert.True(t, m.Supports(responseSaved(""), false))
 		assert.True(t, m.Supports(tea.KeyMsg{}, true))
 		assert.False(t, m.Supports(tea.KeyMsg{}, false))
 	})
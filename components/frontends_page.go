@@ -0,0 +1,308 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"haproxy-runtime-cli/styles"
+	"strconv"
+)
+
+type FrontendsPage struct {
+	socket *socket.Client
+	keys   frontendsPageKeyMap
+	help   help.Model
+	table  table.Model
+	// rows holds the frontend or listener of each table row
+	rows []haproxy.Stat
+	// input takes the new maxconn of a frontend
+	input        textinput.Model
+	editing      bool
+	confirmation Confirmation
+	message      string
+}
+
+type frontendsPageKeyMap struct {
+	GotoStatusPage key.Binding
+	Refresh        key.Binding
+	Enable         key.Binding
+	Disable        key.Binding
+	Shutdown       key.Binding
+	Maxconn        key.Binding
+	confirmationKeyMap
+}
+
+type ActivateFrontendsPage bool
+
+// FrontendChanged is the response of a command changing a frontend
+type FrontendChanged string
+
+func NewFrontendsPage(socket *socket.Client) FrontendsPage {
+	km := createFrontendsKeyMap()
+	input := textinput.New()
+	input.Prompt = "maxconn: "
+	input.PromptStyle = styles.ComplementStyle
+	input.CharLimit = 10
+
+	return FrontendsPage{
+		socket:       socket,
+		keys:         km,
+		help:         help.New(),
+		confirmation: NewConfirmation(),
+		input:        input,
+		table: newTable([]table.Column{
+			{Title: "Frontend", Width: 20},
+			{Title: "Listener", Width: 10},
+			{Title: "Address", Width: 20},
+			{Title: "Status", Width: 8},
+			{Title: "Sessions", Width: 10},
+			{Title: "Limit", Width: 8},
+			{Title: "Req/s", Width: 6},
+			{Title: "Denied", Width: 8},
+			{Title: "Errors", Width: 8},
+		}, []key.Binding{km.Enable, km.Disable, km.Shutdown, km.Maxconn, km.Refresh, km.GotoStatusPage}),
+	}
+}
+
+func (p FrontendsPage) Init() tea.Cmd {
+	return fetchStats(p.socket)
+}
+
+func (p FrontendsPage) Update(msg tea.Msg) (FrontendsPage, tea.Cmd) {
+	switch msg := msg.(type) {
+	case []haproxy.Stat:
+		p.rows = frontendStats(msg)
+		p.table.SetRows(frontendsToRows(p.rows))
+		p.table = recalculateTableSize(p.table)
+		return p, nil
+	case FrontendChanged:
+		p.message = string(msg)
+		return p, fetchStats(p.socket)
+	case WorkerSelected:
+		return p, fetchStats(p.socket)
+	case tea.WindowSizeMsg:
+		p.table.SetWidth(msg.Width - styles.PageStyle.GetHorizontalMargins())
+		p.table.SetHeight(msg.Height - styles.PageStyle.GetVerticalMargins() - 3 - 3 - 2)
+	case tea.KeyMsg:
+		if p.editing {
+			return p.updateMaxconn(msg)
+		}
+		if p.confirmation.Asking() {
+			var cmd tea.Cmd
+			p.confirmation, cmd = p.confirmation.Update(msg)
+			return p, cmd
+		}
+
+		frontend := p.selected()
+		switch {
+		case key.Matches(msg, p.keys.GotoStatusPage):
+			return p, ActivateStatusPageCmd()
+		case key.Matches(msg, p.keys.Refresh):
+			return p, fetchStats(p.socket)
+		case frontend == "" && key.Matches(msg, p.keys.Enable, p.keys.Disable, p.keys.Shutdown, p.keys.Maxconn):
+			// the actions need a selected frontend, on a listener row they would apply to the whole frontend
+			p.message = "no frontend selected"
+			if row := p.row(); row != nil {
+				p.message = fmt.Sprintf("listener %s can't be changed alone, select frontend %s", row.ServiceName, row.ProxyName)
+			}
+			return p, nil
+		case key.Matches(msg, p.keys.Enable):
+			p.confirmation = p.confirmation.Ask(
+				"enable frontend "+frontend,
+				changeFrontend(p.socket, "enable frontend "+frontend),
+			)
+			return p, nil
+		case key.Matches(msg, p.keys.Disable):
+			p.confirmation = p.confirmation.Ask(
+				"disable frontend "+frontend,
+				changeFrontend(p.socket, "disable frontend "+frontend),
+			)
+			return p, nil
+		case key.Matches(msg, p.keys.Shutdown):
+			p.confirmation = p.confirmation.Ask(
+				"shutdown frontend "+frontend,
+				changeFrontend(p.socket, "shutdown frontend "+frontend),
+			)
+			return p, nil
+		case key.Matches(msg, p.keys.Maxconn):
+			p.editing = true
+			p.input.SetValue("")
+			return p, p.input.Focus()
+		}
+	}
+
+	var cmd tea.Cmd
+	p.table, cmd = p.table.Update(msg)
+
+	return p, cmd
+}
+
+func (p FrontendsPage) View() string {
+	return tblStyle.Render(p.table.View()) + "\n" + p.footer()
+}
+
+func (p FrontendsPage) Supports(msg tea.Msg, isActive bool) bool {
+	switch msg.(type) {
+	case []haproxy.Stat, FrontendChanged, WorkerSelected, tea.WindowSizeMsg:
+		return true
+	case tea.KeyMsg:
+		if isActive {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p FrontendsPage) footer() string {
+	switch {
+	case p.editing:
+		return p.input.View() + "\n" + p.help.ShortHelpView([]key.Binding{p.keys.Confirm, p.keys.Cancel})
+	case p.confirmation.Asking():
+		return p.confirmation.View()
+	case p.message != "":
+		return styles.ComplementStyle.Render(p.message) + "\n" + p.table.HelpView()
+	}
+
+	return p.table.HelpView()
+}
+
+func (p FrontendsPage) updateMaxconn(msg tea.KeyMsg) (FrontendsPage, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEnter:
+		if _, err := strconv.Atoi(p.input.Value()); err != nil {
+			return p, nil
+		}
+		p.editing = false
+		p.input.Blur()
+		command := fmt.Sprintf("set maxconn frontend %s %s", p.selected(), p.input.Value())
+		p.confirmation = p.confirmation.Ask(command, changeFrontend(p.socket, command))
+		return p, nil
+	case msg.Type == tea.KeyEsc:
+		p.editing = false
+		p.input.Blur()
+		return p, nil
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+
+	return p, cmd
+}
+
+// selected is the name of the frontend of the selected row, empty on a listener row
+func (p FrontendsPage) selected() string {
+	row := p.row()
+	if row == nil || row.Type != haproxy.FRONTEND {
+		return ""
+	}
+
+	return row.ProxyName
+}
+
+// row is the frontend or listener of the selected row
+func (p FrontendsPage) row() *haproxy.Stat {
+	cursor := p.table.Cursor()
+	if cursor < 0 || cursor >= len(p.rows) {
+		return nil
+	}
+
+	return &p.rows[cursor]
+}
+
+func ActivateFrontendsPageCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ActivateFrontendsPage(true)
+	}
+}
+
+func createFrontendsKeyMap() frontendsPageKeyMap {
+	return frontendsPageKeyMap{
+		GotoStatusPage: key.NewBinding(
+			key.WithKeys("backspace"),
+			key.WithHelp("backspace", "status page"),
+		),
+		Refresh: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "refresh"),
+		),
+		Enable: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "enable"),
+		),
+		Disable: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "disable"),
+		),
+		Shutdown: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "shutdown"),
+		),
+		Maxconn: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "set maxconn"),
+		),
+		confirmationKeyMap: createConfirmationKeyMap(),
+	}
+}
+
+// frontendStats keeps the frontends and their listeners
+func frontendStats(stats []haproxy.Stat) []haproxy.Stat {
+	var res []haproxy.Stat
+	for _, s := range stats {
+		if s.Type == haproxy.FRONTEND || s.Type == haproxy.LISTENER {
+			res = append(res, s)
+		}
+	}
+
+	return res
+}
+
+func frontendsToRows(stats []haproxy.Stat) []table.Row {
+	var rows []table.Row
+
+	for _, s := range stats {
+		if s.Type == haproxy.LISTENER {
+			rows = append(rows, table.Row{"", s.ServiceName, s.Address, s.Status, "", "", "", "", ""})
+			continue
+		}
+
+		rows = append(rows, table.Row{
+			s.ProxyName,
+			"",
+			"",
+			s.Status,
+			fmt.Sprintf("%d/%d", s.CurrentSessions, s.MaxSessions),
+			strconv.Itoa(s.SessionLimit),
+			strconv.Itoa(s.RequestRate),
+			strconv.FormatInt(s.DeniedRequests, 10),
+			strconv.FormatInt(s.RequestErrors, 10),
+		})
+	}
+
+	return rows
+}
+
+func changeFrontend(s *socket.Client, command string) tea.Cmd {
+	return socket.ExecCmd[FrontendChanged](
+		context.Background(),
+		s,
+		command,
+		func(s *string) (FrontendChanged, error) { return FrontendChanged(*s), nil },
+	)
+}
+
+func fetchStats(s *socket.Client) tea.Cmd {
+	return socket.ExecCmd[[]haproxy.Stat](
+		context.Background(),
+		s,
+		"show stat",
+		func(s *string) ([]haproxy.Stat, error) { return haproxy.ParseStat(*s) },
+	)
+}
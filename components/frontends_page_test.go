@@ -0,0 +1,193 @@
+package components
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"net"
+	"testing"
+)
+
+func TestFrontendsPage(t *testing.T) {
+	t.Parallel()
+
+	model := func() FrontendsPage {
+		return FrontendsPage{}
+	}
+
+	stats := []haproxy.Stat{
+		{ProxyName: "http-in", ServiceName: "FRONTEND", Type: haproxy.FRONTEND, Status: "OPEN", CurrentSessions: 3, MaxSessions: 12, SessionLimit: 3000, RequestRate: 2, DeniedRequests: 4, RequestErrors: 7},
+		{ProxyName: "http-in", ServiceName: "sock-1", Type: haproxy.LISTENER, Status: "OPEN", Address: "0.0.0.0:80"},
+		{ProxyName: "app", ServiceName: "BACKEND", Type: haproxy.BACKEND, Status: "UP"},
+	}
+
+	fakeModel := func(t *testing.T) FrontendsPage {
+		m := NewFrontendsPage(fakeClient(t))
+		m, _ = m.Update(m.Init()())
+
+		return m
+	}
+
+	t.Run("New", func(t *testing.T) {
+		m := NewFrontendsPage(nil)
+		assert.NotNil(t, m)
+		assert.NotNil(t, m.table)
+		assert.NotNil(t, m.keys)
+	})
+
+	t.Run("Init with fetch stats", func(t *testing.T) {
+		m := fakeModel(t)
+
+		assert.Len(t, m.rows, 5)
+		assert.Equal(t, "http-in", m.selected())
+	})
+
+	t.Run("Update Stats", func(t *testing.T) {
+		m, cmd := NewFrontendsPage(nil).Update(stats)
+
+		assert.Nil(t, cmd)
+		assert.Len(t, m.table.Rows(), 2)
+
+		res := m.View()
+		assert.Contains(t, res, "http-in")
+		assert.Contains(t, res, "0.0.0.0:80")
+		assert.Contains(t, res, "3/12")
+		assert.NotContains(t, res, "app")
+	})
+
+	t.Run("Update Resize", func(t *testing.T) {
+		m, cmd := model().Update(tea.WindowSizeMsg{Width: 100, Height: 100})
+
+		assert.Nil(t, cmd)
+		assert.Equal(t, 90, m.table.Height())
+		assert.Equal(t, 96, m.table.Width())
+	})
+
+	t.Run("Update Goto Status Page", func(t *testing.T) {
+		_, cmd := NewFrontendsPage(nil).Update(tea.KeyMsg{Type: tea.KeyBackspace, Runes: []rune{}})
+
+		assert.NotNil(t, cmd)
+		assert.IsType(t, ActivateStatusPageCmd(), cmd)
+	})
+
+	t.Run("Update Action without Frontend", func(t *testing.T) {
+		m, _ := NewFrontendsPage(nil).Update(keyMsg('d'))
+
+		assert.False(t, m.confirmation.Asking())
+	})
+
+	t.Run("Update Action on Listener", func(t *testing.T) {
+		m, _ := NewFrontendsPage(nil).Update(stats)
+		m.table.MoveDown(1)
+
+		m, cmd := m.Update(keyMsg('x'))
+
+		assert.Nil(t, cmd)
+		assert.False(t, m.confirmation.Asking())
+		assert.Contains(t, m.View(), "listener sock-1 can't be changed alone, select frontend http-in")
+	})
+
+	t.Run("Update Action without Frontends", func(t *testing.T) {
+		m, cmd := NewFrontendsPage(nil).Update(keyMsg('d'))
+
+		assert.Nil(t, cmd)
+		assert.False(t, m.confirmation.Asking())
+		assert.Contains(t, m.View(), "no frontend selected")
+	})
+
+	t.Run("Update Disable", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, cmd := m.Update(keyMsg('d'))
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.View(), "disable frontend http-in?")
+
+		m, cmd = m.Update(keyMsg('y'))
+		assert.False(t, m.confirmation.Asking())
+		m, cmd = m.Update(cmd())
+		m, _ = m.Update(cmd())
+
+		assert.Equal(t, "PAUSED", m.rows[0].Status)
+	})
+
+	t.Run("Update Shutdown", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('x'))
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, cmd = m.Update(cmd())
+
+		assert.Contains(t, m.View(), "Frontend http-in was stopped.")
+		m, _ = m.Update(cmd())
+		assert.Equal(t, "STOP", m.rows[0].Status)
+	})
+
+	t.Run("Update Cancel", func(t *testing.T) {
+		m, _ := NewFrontendsPage(nil).Update(stats)
+
+		m, _ = m.Update(keyMsg('e'))
+		assert.Equal(t, "enable frontend http-in", m.confirmation.question)
+
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.Nil(t, cmd)
+		assert.False(t, m.confirmation.Asking())
+	})
+
+	t.Run("Update Maxconn", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('m'))
+		assert.True(t, m.editing)
+		assert.Contains(t, m.View(), "maxconn:")
+
+		// not a number
+		m, _ = m.Update(keyMsg('a'))
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.True(t, m.editing)
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("500")})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.False(t, m.editing)
+		assert.Equal(t, "set maxconn frontend http-in 500", m.confirmation.question)
+
+		m, cmd := m.Update(keyMsg('y'))
+		m, cmd = m.Update(cmd())
+		m, _ = m.Update(cmd())
+
+		assert.Equal(t, 500, m.rows[0].SessionLimit)
+	})
+
+	t.Run("Update Maxconn Cancel", func(t *testing.T) {
+		m, _ := NewFrontendsPage(nil).Update(stats)
+
+		m, _ = m.Update(keyMsg('m'))
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.False(t, m.editing)
+		assert.False(t, m.confirmation.Asking())
+	})
+
+	t.Run("Update Permission Denied", func(t *testing.T) {
+		m, _ := NewFrontendsPage(nil).Update(stats)
+		conn := &socket.DummySocket{Output: []byte("Permission denied")}
+		m.socket = socket.NewClient(func() (net.Conn, error) { return conn, nil })
+
+		m, _ = m.Update(keyMsg('d'))
+		_, cmd := m.Update(keyMsg('y'))
+
+		assert.ErrorIs(t, cmd().(error), haproxy.ErrPermissionDenied)
+	})
+
+	t.Run("Supports", func(t *testing.T) {
+		m := model()
+
+		assert.True(t, m.Supports([]haproxy.Stat{}, false))
+		assert.True(t, m.Supports(FrontendChanged(""), false))
+		assert.True(t, m.Supports(WorkerSelected{}, false))
+		assert.True(t, m.Supports(tea.WindowSizeMsg{}, false))
+		assert.True(t, m.Supports(tea.KeyMsg{}, true))
+		assert.False(t, m.Supports(tea.KeyMsg{}, false))
+	})
+}
@@ -0,0 +1,35 @@
+package components
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// listKeyMap are the keys of the pages listing e.g. the maps, one of which is opened to show e.g. its entries
+type listKeyMap struct {
+	GotoStatusPage key.Binding
+	Close          key.Binding
+	Open           key.Binding
+	Refresh        key.Binding
+}
+
+// createListKeyMap names what is listed and what is shown once opened, e.g. maps and entries
+func createListKeyMap(listed string, opened string) listKeyMap {
+	return listKeyMap{
+		GotoStatusPage: key.NewBinding(
+			key.WithKeys("backspace"),
+			key.WithHelp("backspace", "status page"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("backspace"),
+			key.WithHelp("backspace", listed),
+		),
+		Open: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", opened),
+		),
+		Refresh: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "refresh"),
+		),
+	}
+}
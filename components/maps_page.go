@@ -0,0 +1,453 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"haproxy-runtime-cli/styles"
+	"os"
+	"strings"
+)
+
+// MapsPage lists the maps and edits the entries of one of them
+type MapsPage struct {
+	socket *socket.Client
+	keys   mapsPageKeyMap
+	help   help.Model
+	lists  []haproxy.PatternList
+	maps   table.Model
+	// opened is the map whose entries are shown, empty while listing the maps
+	opened   string
+	patterns []haproxy.Pattern
+	// visible are the entries matching the filter
+	visible []haproxy.Pattern
+	entries table.Model
+	filter  textinput.Model
+	// input takes a new entry, a new value or the file to update the map from
+	input        textinput.Model
+	editing      mapEdit
+	confirmation Confirmation
+	message      string
+}
+
+type mapsPageKeyMap struct {
+	listKeyMap
+	Filter key.Binding
+	Add    key.Binding
+	Edit   key.Binding
+	Delete key.Binding
+	Update key.Binding
+	confirmationKeyMap
+}
+
+// mapEdit is what the input of the maps page is taking
+type mapEdit int
+
+const (
+	notEditing mapEdit = iota
+	addingEntry
+	editingValue
+	choosingFile
+)
+
+type ActivateMapsPage bool
+
+// MapChanged describes a change of a map
+type MapChanged string
+
+type mapLists []haproxy.PatternList
+
+type mapEntries struct {
+	name     string
+	patterns []haproxy.Pattern
+}
+
+// mapFile holds the entries of a map file to replace the entries of a map with
+type mapFile struct {
+	path    string
+	entries []string
+}
+
+func NewMapsPage(socket *socket.Client) MapsPage {
+	km := createMapsKeyMap()
+	filter := textinput.New()
+	filter.Prompt = "filter: "
+	filter.PromptStyle = styles.ComplementStyle
+	input := textinput.New()
+	input.PromptStyle = styles.ComplementStyle
+
+	return MapsPage{
+		socket:       socket,
+		keys:         km,
+		help:         help.New(),
+		confirmation: NewConfirmation(),
+		filter:       filter,
+		input:        input,
+		maps: newTable([]table.Column{
+			{Title: "Id", Width: 4},
+			{Title: "Map", Width: 30},
+			{Title: "Version", Width: 8},
+			{Title: "Entries", Width: 8},
+			{Title: "Description", Width: 40},
+		}, []key.Binding{km.Open, km.Refresh, km.GotoStatusPage}),
+		entries: newTable([]table.Column{
+			{Title: "Key", Width: 30},
+			{Title: "Value", Width: 30},
+			{Title: "Ref", Width: 16},
+		}, []key.Binding{km.Add, km.Edit, km.Delete, km.Update, km.Filter, km.Refresh, km.Close}),
+	}
+}
+
+func (p MapsPage) Init() tea.Cmd {
+	return nil
+}
+
+func (p MapsPage) Update(msg tea.Msg) (MapsPage, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ActivateMapsPage:
+		// the maps are fetched when needed, as `show map` requires the operator level
+		return p, p.refresh()
+	case WorkerSelected:
+		if p.lists == nil {
+			return p, nil
+		}
+		return p, p.refresh()
+	case mapLists:
+		p.lists = msg
+		p.maps.SetRows(patternListsToRows(msg))
+		p.maps = recalculateTableSize(p.maps)
+		return p, nil
+	case mapEntries:
+		if msg.name == p.opened {
+			p.patterns = msg.patterns
+			p.updateRows()
+		}
+		return p, nil
+	case mapFile:
+		p.confirmation = p.confirmation.Ask(
+			fmt.Sprintf("replace the %d entries of %s with the %d of %s", len(p.patterns), p.opened, len(msg.entries), msg.path),
+			updateMap(p.socket, p.opened, msg.entries),
+		)
+		return p, nil
+	case MapChanged:
+		p.message = string(msg)
+		return p, p.refresh()
+	case tea.WindowSizeMsg:
+		height := msg.Height - styles.PageStyle.GetVerticalMargins() - 3 - 3 - 2
+		p.maps.SetWidth(msg.Width - styles.PageStyle.GetHorizontalMargins())
+		p.maps.SetHeight(height)
+		p.entries.SetWidth(msg.Width - styles.PageStyle.GetHorizontalMargins())
+		p.entries.SetHeight(height - 1)
+	case tea.KeyMsg:
+		if p.editing != notEditing {
+			return p.updateInput(msg)
+		}
+		if p.filter.Focused() {
+			return p.updateFilter(msg)
+		}
+		if p.confirmation.Asking() {
+			var cmd tea.Cmd
+			p.confirmation, cmd = p.confirmation.Update(msg)
+			return p, cmd
+		}
+		if p.opened == "" {
+			return p.updateMaps(msg)
+		}
+
+		entry := p.selected()
+		switch {
+		case key.Matches(msg, p.keys.Close):
+			p.opened = ""
+			p.filter.SetValue("")
+			p.message = ""
+			return p, nil
+		case key.Matches(msg, p.keys.Refresh):
+			return p, p.refresh()
+		case key.Matches(msg, p.keys.Filter):
+			return p, p.filter.Focus()
+		case key.Matches(msg, p.keys.Add):
+			return p.edit(addingEntry, "key value: ", "")
+		case key.Matches(msg, p.keys.Update):
+			return p.edit(choosingFile, "update from file: ", "")
+		case entry == nil:
+			// the other actions need a selected entry
+		case key.Matches(msg, p.keys.Edit):
+			return p.edit(editingValue, entry.Key+": ", entry.Value)
+		case key.Matches(msg, p.keys.Delete):
+			p.confirmation = p.confirmation.Ask(
+				fmt.Sprintf("delete %s from %s", entry.Key, p.opened),
+				changeCmd[MapChanged](p.socket, fmt.Sprintf("del map %s #%s", haproxy.EscapeArg(p.opened), entry.Ref), "deleted "+entry.Key),
+			)
+			return p, nil
+		}
+
+		var cmd tea.Cmd
+		p.entries, cmd = p.entries.Update(msg)
+		return p, cmd
+	}
+
+	return p, nil
+}
+
+func (p MapsPage) updateMaps(msg tea.KeyMsg) (MapsPage, tea.Cmd) {
+	switch {
+	case key.Matches(msg, p.keys.GotoStatusPage):
+		return p, ActivateStatusPageCmd()
+	case key.Matches(msg, p.keys.Refresh):
+		return p, p.refresh()
+	case key.Matches(msg, p.keys.Open):
+		cursor := p.maps.Cursor()
+		if cursor < 0 || cursor >= len(p.lists) {
+			return p, nil
+		}
+		p.opened = p.lists[cursor].Name()
+		p.patterns = nil
+		p.message = ""
+		p.updateRows()
+		return p, fetchMapEntries(p.socket, p.opened)
+	}
+
+	var cmd tea.Cmd
+	p.maps, cmd = p.maps.Update(msg)
+
+	return p, cmd
+}
+
+func (p MapsPage) View() string {
+	if p.opened == "" {
+		return tblStyle.Render(p.maps.View()) + "\n" + p.footer(p.maps)
+	}
+
+	title := styles.ActiveStyle.Render(p.opened) + styles.ComplementStyle.Render(fmt.Sprintf("  %d entries", len(p.patterns)))
+	if p.filter.Value() != "" {
+		title += styles.ComplementStyle.Render(fmt.Sprintf(", %d matching %q", len(p.visible), p.filter.Value()))
+	}
+
+	return title + "\n" + tblStyle.Render(p.entries.View()) + "\n" + p.footer(p.entries)
+}
+
+func (p MapsPage) Supports(msg tea.Msg, isActive bool) bool {
+	switch msg.(type) {
+	case ActivateMapsPage, mapLists, mapEntries, mapFile, MapChanged, WorkerSelected, tea.WindowSizeMsg:
+		return true
+	case tea.KeyMsg:
+		if isActive {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p MapsPage) footer(t table.Model) string {
+	switch {
+	case p.editing != notEditing:
+		return p.input.View() + "\n" + p.help.ShortHelpView([]key.Binding{p.keys.Confirm, p.keys.Cancel})
+	case p.filter.Focused():
+		return p.filter.View() + "\n" + p.help.ShortHelpView([]key.Binding{p.keys.Confirm, p.keys.Cancel})
+	case p.confirmation.Asking():
+		return p.confirmation.View()
+	case p.message != "":
+		return styles.ComplementStyle.Render(p.message) + "\n" + t.HelpView()
+	}
+
+	return t.HelpView()
+}
+
+// refresh fetches the maps and the entries of the opened map
+func (p MapsPage) refresh() tea.Cmd {
+	if p.opened == "" {
+		return fetchMaps(p.socket)
+	}
+
+	return tea.Batch(fetchMaps(p.socket), fetchMapEntries(p.socket, p.opened))
+}
+
+func (p MapsPage) edit(editing mapEdit, prompt string, value string) (MapsPage, tea.Cmd) {
+	p.editing = editing
+	p.input.Prompt = prompt
+	p.input.SetValue(value)
+	p.input.CursorEnd()
+
+	return p, p.input.Focus()
+}
+
+// updateInput sends the new entry or value, or reads the file to update the map from
+func (p MapsPage) updateInput(msg tea.KeyMsg) (MapsPage, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		value := strings.TrimSpace(p.input.Value())
+		var cmd tea.Cmd
+		switch p.editing {
+		case addingEntry:
+			k, v, _ := strings.Cut(value, " ")
+			if strings.TrimSpace(v) == "" {
+				return p, nil
+			}
+			v = strings.TrimSpace(v)
+			cmd = changeCmd[MapChanged](p.socket, fmt.Sprintf("add map %s %s %s", haproxy.EscapeArg(p.opened), haproxy.EscapeArg(k), haproxy.EscapeArg(v)), "added "+k)
+		case editingValue:
+			entry := p.selected()
+			if entry == nil || value == "" {
+				return p, nil
+			}
+			cmd = changeCmd[MapChanged](p.socket, fmt.Sprintf("set map %s #%s %s", haproxy.EscapeArg(p.opened), entry.Ref, haproxy.EscapeArg(value)), "set "+entry.Key+" to "+value)
+		case choosingFile:
+			cmd = readMapFile(value)
+		}
+		p.editing = notEditing
+		p.input.Blur()
+		return p, cmd
+	case tea.KeyEsc:
+		p.editing = notEditing
+		p.input.Blur()
+		return p, nil
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+
+	return p, cmd
+}
+
+// updateFilter filters the entries while typing, enter keeps the filter and esc drops it
+func (p MapsPage) updateFilter(msg tea.KeyMsg) (MapsPage, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		p.filter.Blur()
+		return p, nil
+	case tea.KeyEsc:
+		p.filter.Blur()
+		p.filter.SetValue("")
+		p.updateRows()
+		return p, nil
+	}
+
+	var cmd tea.Cmd
+	p.filter, cmd = p.filter.Update(msg)
+	p.updateRows()
+
+	return p, cmd
+}
+
+// updateRows shows the entries matching the filter on their key or value
+func (p *MapsPage) updateRows() {
+	p.visible = filterPatterns(p.patterns, p.filter.Value())
+
+	rows := make([]table.Row, len(p.visible))
+	for i, e := range p.visible {
+		rows[i] = table.Row{e.Key, e.Value, e.Ref}
+	}
+	p.entries.SetRows(rows)
+	p.entries = recalculateTableSize(p.entries)
+	// the table leaves the cursor at -1 while it is empty
+	p.entries.SetCursor(min(max(p.entries.Cursor(), 0), len(rows)-1))
+}
+
+// selected is the entry of the selected row
+func (p MapsPage) selected() *haproxy.Pattern {
+	cursor := p.entries.Cursor()
+	if cursor < 0 || cursor >= len(p.visible) {
+		return nil
+	}
+
+	return &p.visible[cursor]
+}
+
+func ActivateMapsPageCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ActivateMapsPage(true)
+	}
+}
+
+func createMapsKeyMap() mapsPageKeyMap {
+	return mapsPageKeyMap{
+		listKeyMap: createListKeyMap("maps", "entries"),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter"),
+		),
+		Add: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "add"),
+		),
+		Edit: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit"),
+		),
+		Delete: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "delete"),
+		),
+		Update: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "update from file"),
+		),
+		confirmationKeyMap: createConfirmationKeyMap(),
+	}
+}
+
+func fetchMaps(s *socket.Client) tea.Cmd {
+	return socket.ExecCmd[mapLists](
+		context.Background(),
+		s,
+		"show map",
+		func(out *string) (mapLists, error) { return haproxy.ParsePatternLists(*out) },
+	)
+}
+
+func fetchMapEntries(s *socket.Client, name string) tea.Cmd {
+	return socket.ExecCmd[mapEntries](
+		context.Background(),
+		s,
+		"show map "+haproxy.EscapeArg(name),
+		func(out *string) (mapEntries, error) {
+			patterns, err := haproxy.ParsePatterns(*out)
+			return mapEntries{name: name, patterns: patterns}, err
+		},
+	)
+}
+
+// readMapFile reads the entries of a map file, `<key> <value>` lines with `#` comments
+func readMapFile(path string) tea.Cmd {
+	return func() tea.Msg {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var entries []string
+		for i, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			// the key ends at the first whitespace, the value is the rest of the line as it is
+			end := strings.IndexAny(line, " \t")
+			if end < 0 {
+				return fmt.Errorf("%s line %d: missing value", path, i+1)
+			}
+			entries = append(entries, line[:end]+" "+strings.TrimLeft(line[end:], " \t"))
+		}
+
+		return mapFile{path: path, entries: entries}
+	}
+}
+
+// updateMap replaces the entries of a map at once
+func updateMap(s *socket.Client, name string, entries []string) tea.Cmd {
+	return func() tea.Msg {
+		version, err := replacePatterns(context.Background(), s, "map", name, entries)
+		if err != nil {
+			return err
+		}
+
+		return MapChanged(fmt.Sprintf("committed %d entries to %s as version %s", len(entries), name, version))
+	}
+}
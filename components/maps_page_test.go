@@ -0,0 +1,191 @@
+package components
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMapsPage(t *testing.T) {
+	t.Parallel()
+
+	const hosts = "/etc/haproxy/maps/hosts.map"
+
+	// fakeModel shows the entries of the hosts map of the fake
+	fakeModel := func(t *testing.T) MapsPage {
+		m, cmd := NewMapsPage(fakeClient(t)).Update(ActivateMapsPage(true))
+		m, _ = m.Update(cmd())
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, _ = m.Update(cmd())
+
+		return m
+	}
+
+	t.Run("Update Activate", func(t *testing.T) {
+		m := NewMapsPage(nil)
+		assert.Nil(t, m.Init())
+
+		m, _ = m.Update(mapLists{{Id: -1, File: hosts, Entries: 2}})
+
+		res := m.View()
+		assert.Contains(t, res, hosts)
+		assert.Contains(t, res, "enter entries")
+	})
+
+	t.Run("Update Open", func(t *testing.T) {
+		m := fakeModel(t)
+
+		assert.Equal(t, hosts, m.opened)
+		assert.Len(t, m.patterns, 2)
+		res := m.View()
+		assert.Contains(t, res, hosts+"  2 entries")
+		assert.Contains(t, res, "api.example.com")
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		assert.Empty(t, m.opened)
+	})
+
+	t.Run("Update Filter", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('/'))
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("OTHER")})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Len(t, m.visible, 1)
+		assert.Equal(t, "api.example.com", m.selected().Key)
+		assert.Contains(t, m.View(), `1 matching "OTHER"`)
+	})
+
+	t.Run("Update Add", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('a'))
+		// a value is required
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("www.example.com")})
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Nil(t, cmd)
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" www")})
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = apply(m, cmd)
+
+		assert.Contains(t, m.View(), "added www.example.com")
+		assert.Equal(t, haproxy.Pattern{Ref: m.patterns[2].Ref, Key: "www.example.com", Value: "www"}, m.patterns[2])
+	})
+
+	t.Run("Update Edit", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('e'))
+		assert.Contains(t, m.View(), "example.com: default")
+		m.input.SetValue("backup")
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = apply(m, cmd)
+
+		assert.Equal(t, "backup", m.patterns[0].Value)
+	})
+
+	t.Run("Update Escaped Values", func(t *testing.T) {
+		m := fakeModel(t)
+
+		// spaces and `;` are part of the value instead of cutting it short or starting another command
+		m, _ = m.Update(keyMsg('a'))
+		m.input.SetValue(`a.com be one; clear map ` + hosts)
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = apply(m, cmd)
+		assert.Len(t, m.patterns, 3)
+		assert.Equal(t, "be one; clear map "+hosts, m.patterns[2].Value)
+
+		m, _ = m.Update(keyMsg('e'))
+		m.input.SetValue(`x y\z`)
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = apply(m, cmd)
+		assert.Equal(t, `x y\z`, m.patterns[0].Value)
+	})
+
+	t.Run("Update Delete", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, cmd := m.Update(keyMsg('d'))
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.View(), "delete example.com from "+hosts+"?")
+
+		m, cmd = m.Update(keyMsg('y'))
+		m = apply(m, cmd)
+
+		assert.Len(t, m.patterns, 1)
+		assert.Equal(t, "api.example.com", m.patterns[0].Key)
+	})
+
+	t.Run("Update Cancel", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('d'))
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Nil(t, cmd)
+		assert.False(t, m.confirmation.Asking())
+	})
+
+	t.Run("Update From File", func(t *testing.T) {
+		m := fakeModel(t)
+		path := filepath.Join(t.TempDir(), "hosts.map")
+		assert.Nil(t, os.WriteFile(path, []byte("# new hosts\na.example.com a\n\nb.example.com \t b  c\td\n"), 0o644))
+
+		m, _ = m.Update(keyMsg('u'))
+		m.input.SetValue(path)
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, _ = m.Update(cmd())
+		assert.Contains(t, m.View(), "replace the 2 entries of "+hosts+" with the 2 of "+path+"?")
+
+		m, cmd = m.Update(keyMsg('y'))
+		m = apply(m, cmd)
+
+		assert.Contains(t, m.View(), "committed 2 entries to "+hosts+" as version 1")
+		assert.Equal(t, "a.example.com", m.patterns[0].Key)
+		// whitespace within the value is kept
+		assert.Equal(t, "b  c\td", m.patterns[1].Value)
+		assert.Equal(t, 1, m.lists[0].CurrentVersion)
+	})
+
+	t.Run("Update From Malformed File", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "hosts.map")
+		assert.Nil(t, os.WriteFile(path, []byte("a.example.com\n"), 0o644))
+
+		assert.EqualError(t, readMapFile(path)().(error), path+" line 1: missing value")
+		assert.Error(t, readMapFile(filepath.Join(t.TempDir(), "missing.map"))().(error))
+	})
+
+	t.Run("Update Permission Denied", func(t *testing.T) {
+		conn := &socket.DummySocket{Output: []byte("Permission denied")}
+		client := socket.NewClient(func() (net.Conn, error) { return conn, nil })
+
+		err := updateMap(client, hosts, []string{"a b"})().(error)
+
+		assert.ErrorIs(t, err, haproxy.ErrPermissionDenied)
+	})
+
+	t.Run("Update Goto Status Page", func(t *testing.T) {
+		_, cmd := NewMapsPage(nil).Update(tea.KeyMsg{Type: tea.KeyBackspace})
+
+		assert.IsType(t, ActivateStatusPageCmd(), cmd)
+	})
+
+	t.Run("Supports", func(t *testing.T) {
+		m := NewMapsPage(nil)
+
+		assert.True(t, m.Supports(ActivateMapsPage(true), false))
+		assert.True(t, m.Supports(mapLists{}, false))
+		assert.True(t, m.Supports(MapChanged(""), false))
+		assert.True(t, m.Supports(WorkerSelected{}, false))
+		assert.True(t, m.Supports(tea.WindowSizeMsg{}, false))
+		assert.True(t, m.Supports(tea.KeyMsg{}, true))
+		assert.False(t, m.Supports(tea.KeyMsg{}, false))
+	})
+}
@@ -0,0 +1,101 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/bubbles/table"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"strconv"
+	"strings"
+)
+
+// patternListsToRows are the rows of the maps or acls
+func patternListsToRows(lists []haproxy.PatternList) []table.Row {
+	rows := make([]table.Row, len(lists))
+	for i, l := range lists {
+		rows[i] = table.Row{strconv.Itoa(l.Id), l.Name(), strconv.Itoa(l.CurrentVersion), strconv.Itoa(l.Entries), l.Description}
+	}
+
+	return rows
+}
+
+// filterPatterns keeps the entries of a map or acl containing the term in their key or value
+func filterPatterns(patterns []haproxy.Pattern, term string) []haproxy.Pattern {
+	if term == "" {
+		return patterns
+	}
+
+	var res []haproxy.Pattern
+	term = strings.ToLower(term)
+	for _, e := range patterns {
+		if strings.Contains(strings.ToLower(e.Key), term) || strings.Contains(strings.ToLower(e.Value), term) {
+			res = append(res, e)
+		}
+	}
+
+	return res
+}
+
+// maxPayload is the size of the entries sent with one command, a command and its payload have to fit
+// in the buffer of HAProxy (tune.bufsize, 16k by default)
+const maxPayload = 8 << 10
+
+// replacePatterns replaces the entries of a map or acl (the kind) at once: they are added to a new version
+// of it, which is then committed. It returns the committed version.
+func replacePatterns(ctx context.Context, s *socket.Client, kind string, name string, entries []string) (string, error) {
+	arg := haproxy.EscapeArg(name)
+	res, err := execChecked(ctx, s, fmt.Sprintf("prepare %s %s", kind, arg))
+	if err != nil {
+		return "", err
+	}
+	version := strings.TrimPrefix(strings.TrimSpace(res), "New version created: ")
+	if _, err := strconv.Atoi(version); err != nil {
+		return "", fmt.Errorf("prepare %s %s: unexpected response %q", kind, name, res)
+	}
+
+	for _, chunk := range payloadChunks(entries, maxPayload) {
+		command := fmt.Sprintf("add %s @%s %s <<\n%s\n", kind, version, arg, strings.Join(chunk, "\n"))
+		if _, err := execChecked(ctx, s, command); err != nil {
+			// the uncommitted version is dropped, the map or acl stays as it is
+			_, _ = execChecked(ctx, s, fmt.Sprintf("clear %s @%s %s", kind, version, arg))
+			return "", err
+		}
+	}
+
+	if _, err := execChecked(ctx, s, fmt.Sprintf("commit %s @%s %s", kind, version, arg)); err != nil {
+		return "", err
+	}
+
+	return version, nil
+}
+
+// payloadChunks splits the entries into payloads of at most max bytes, an entry longer than that is sent alone
+func payloadChunks(entries []string, max int) [][]string {
+	var chunks [][]string
+	size := 0
+	for _, e := range entries {
+		if len(chunks) == 0 || size+len(e)+1 > max {
+			chunks = append(chunks, nil)
+			size = 0
+		}
+		chunks[len(chunks)-1] = append(chunks[len(chunks)-1], e)
+		size += len(e) + 1
+	}
+
+	return chunks
+}
+
+// execChecked sends a command of a sequence, a failure reported by HAProxy is returned as error
+func execChecked(ctx context.Context, s *socket.Client, command string) (string, error) {
+	res, err := socket.Exec(ctx, s, command)
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(command, "\n")
+	if err := haproxy.CheckResponse(line, *res); err != nil {
+		return "", err
+	}
+
+	return *res, nil
+}
@@ -0,0 +1,75 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/haproxy"
+	"testing"
+)
+
+func TestPatternListsToRows(t *testing.T) {
+	lists := []haproxy.PatternList{{Id: 1, CurrentVersion: 2, Entries: 3, Description: "acl 'path_beg'"}}
+
+	assert.Equal(t, []table.Row{{"1", "#1", "2", "3", "acl 'path_beg'"}}, patternListsToRows(lists))
+}
+
+func TestFilterPatterns(t *testing.T) {
+	patterns := []haproxy.Pattern{{Key: "example.com", Value: "default"}, {Key: "api.example.com", Value: "Other"}}
+
+	assert.Equal(t, patterns, filterPatterns(patterns, ""))
+	assert.Equal(t, patterns[1:], filterPatterns(patterns, "other"))
+	assert.Equal(t, patterns[1:], filterPatterns(patterns, "API"))
+	assert.Empty(t, filterPatterns(patterns, "www"))
+}
+
+func TestReplacePatterns(t *testing.T) {
+	client := fakeClient(t)
+	ctx := context.Background()
+
+	version, err := replacePatterns(ctx, client, "acl", "/etc/haproxy/acl/blocklist.acl", []string{"10.0.0.1", "10.0.0.2"})
+	assert.Nil(t, err)
+	assert.Equal(t, "1", version)
+
+	res, err := execChecked(ctx, client, "show acl /etc/haproxy/acl/blocklist.acl")
+	assert.Nil(t, err)
+	patterns, _ := haproxy.ParseAclPatterns(res)
+	assert.Len(t, patterns, 2)
+
+	// a map entry needs a value, the failed version is cleared and the map kept
+	_, err = replacePatterns(ctx, client, "map", "/etc/haproxy/maps/hosts.map", []string{"example.com"})
+	assert.ErrorContains(t, err, "expects three parameters")
+
+	res, err = execChecked(ctx, client, "show map /etc/haproxy/maps/hosts.map")
+	assert.Nil(t, err)
+	patterns, _ = haproxy.ParsePatterns(res)
+	assert.Len(t, patterns, 2)
+
+	_, err = replacePatterns(ctx, client, "acl", "missing.acl", nil)
+	assert.ErrorContains(t, err, "Unknown ACL identifier")
+
+	// names are escaped, a `;` in one does not start another command
+	_, err = replacePatterns(ctx, client, "map", "/etc/haproxy/maps/hosts.map;clear map /etc/haproxy/maps/hosts.map", nil)
+	assert.ErrorContains(t, err, "Unknown map identifier")
+
+	// the entries of a huge file are sent in several payloads, each fitting in the buffer of HAProxy
+	var entries []string
+	for i := range 2000 {
+		entries = append(entries, fmt.Sprintf("host-%04d.example.com backend-%04d", i, i))
+	}
+	_, err = replacePatterns(ctx, client, "map", "/etc/haproxy/maps/hosts.map", entries)
+	assert.Nil(t, err)
+
+	res, err = execChecked(ctx, client, "show map /etc/haproxy/maps/hosts.map")
+	assert.Nil(t, err)
+	patterns, _ = haproxy.ParsePatterns(res)
+	assert.Len(t, patterns, 2000)
+}
+
+func TestPayloadChunks(t *testing.T) {
+	assert.Empty(t, payloadChunks(nil, 10))
+	assert.Equal(t, [][]string{{"aaa", "bbb"}, {"ccc"}}, payloadChunks([]string{"aaa", "bbb", "ccc"}, 8))
+	// an entry longer than a payload is sent alone
+	assert.Equal(t, [][]string{{"a"}, {"bbbbbbbbbb"}, {"c"}}, payloadChunks([]string{"a", "bbbbbbbbbb", "c"}, 8))
+}
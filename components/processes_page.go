@@ -0,0 +1,275 @@
+package components
+
+import (
+	"context"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"haproxy-runtime-cli/styles"
+	"strconv"
+)
+
+type ProcessesPage struct {
+	socket    *socket.Client
+	keys      processesPageKeyMap
+	processes []haproxy.Process
+	table     table.Model
+	reload    *haproxy.ReloadResult
+	// confirmation asks before HAProxy is reloaded
+	confirmation Confirmation
+}
+
+type processesPageKeyMap struct {
+	GotoStatusPage key.Binding
+	Select         key.Binding
+	Refresh        key.Binding
+	Reload         key.Binding
+}
+
+type ActivateProcessesPage bool
+
+// MasterDetected is sent once the socket turned out to be the master cli of a master-worker HAProxy
+type MasterDetected []haproxy.Process
+
+// WorkerSelected is sent once commands are routed to another worker
+type WorkerSelected haproxy.Process
+
+func NewProcessesPage(socket *socket.Client) ProcessesPage {
+	km := createProcessesKeyMap()
+	return ProcessesPage{
+		socket:       socket,
+		keys:         km,
+		confirmation: NewConfirmation(),
+		table: newTable([]table.Column{
+			{Title: "", Width: 1},
+			{Title: "PID", Width: 8},
+			{Title: "Type", Width: 8},
+			{Title: "Generation", Width: 10},
+			{Title: "Reloads", Width: 8},
+			{Title: "Uptime", Width: 12},
+			{Title: "Version", Width: 10},
+		}, []key.Binding{km.Select, km.Refresh, km.Reload, km.GotoStatusPage}),
+	}
+}
+
+func (p ProcessesPage) Init() tea.Cmd {
+	return detectMaster(p.socket)
+}
+
+func (p ProcessesPage) Update(msg tea.Msg) (ProcessesPage, tea.Cmd) {
+	switch msg := msg.(type) {
+	case MasterDetected:
+		p = p.setProcesses(msg)
+	case []haproxy.Process:
+		p = p.setProcesses(msg)
+	case WorkerSelected:
+		return p, fetchProcesses(p.socket)
+	case haproxy.ReloadResult:
+		p.reload = &msg
+		if msg.Success || !msg.Reported {
+			return p, selectCurrentWorker(p.socket)
+		}
+		return p, fetchProcesses(p.socket)
+	case tea.WindowSizeMsg:
+		p.table.SetWidth(msg.Width - styles.PageStyle.GetHorizontalMargins())
+		p.table.SetHeight(msg.Height - styles.PageStyle.GetVerticalMargins() - 3 - 3 - 6)
+	case tea.KeyMsg:
+		if p.confirmation.Asking() {
+			var cmd tea.Cmd
+			p.confirmation, cmd = p.confirmation.Update(msg)
+			return p, cmd
+		}
+
+		switch {
+		case key.Matches(msg, p.keys.GotoStatusPage):
+			return p, ActivateStatusPageCmd()
+		case key.Matches(msg, p.keys.Refresh):
+			return p, fetchProcesses(p.socket)
+		case key.Matches(msg, p.keys.Reload):
+			p.reload = nil
+			p.confirmation = p.confirmation.Ask("reload haproxy", reloadHAProxy(p.socket))
+			return p, nil
+		case key.Matches(msg, p.keys.Select):
+			cursor := p.table.Cursor()
+			if cursor < 0 || cursor >= len(p.processes) || p.processes[cursor].Type != haproxy.WORKER {
+				return p, nil
+			}
+			return p, tea.Sequence(selectWorker(p.socket, p.processes[cursor]), ActivateStatusPageCmd())
+		}
+	}
+
+	var cmd tea.Cmd
+	p.table, cmd = p.table.Update(msg)
+
+	return p, cmd
+}
+
+func (p ProcessesPage) View() string {
+	footer := p.table.HelpView()
+	if p.confirmation.Asking() {
+		footer = p.confirmation.View()
+	}
+
+	return tblStyle.Render(p.table.View()) + "\n" + reloadResult(p.reload) + footer
+}
+
+func (p ProcessesPage) Supports(msg tea.Msg, isActive bool) bool {
+	switch msg.(type) {
+	case MasterDetected, []haproxy.Process, WorkerSelected, haproxy.ReloadResult, tea.WindowSizeMsg:
+		return true
+	case tea.KeyMsg:
+		if isActive {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p ProcessesPage) setProcesses(processes []haproxy.Process) ProcessesPage {
+	p.processes = processes
+	p.table.SetRows(processesToRows(processes, p.target()))
+	p.table = recalculateTableSize(p.table)
+
+	return p
+}
+
+func (p ProcessesPage) target() string {
+	if p.socket == nil {
+		return ""
+	}
+
+	return p.socket.Target()
+}
+
+func ActivateProcessesPageCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ActivateProcessesPage(true)
+	}
+}
+
+func createProcessesKeyMap() processesPageKeyMap {
+	return processesPageKeyMap{
+		GotoStatusPage: key.NewBinding(
+			key.WithKeys("backspace"),
+			key.WithHelp("backspace", "status page"),
+		),
+		Select: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "send commands to worker"),
+		),
+		Refresh: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "refresh"),
+		),
+		Reload: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "reload haproxy"),
+		),
+	}
+}
+
+func processesToRows(processes []haproxy.Process, target string) []table.Row {
+	var rows []table.Row
+
+	for _, p := range processes {
+		selected := ""
+		if p.Target() == target {
+			selected = "*"
+		}
+
+		generation := "current"
+		if p.Old {
+			generation = "old"
+		}
+
+		rows = append(rows, table.Row{
+			selected,
+			strconv.Itoa(p.Pid),
+			p.Type,
+			generation,
+			strconv.Itoa(p.Reloads),
+			p.Uptime,
+			p.Version,
+		})
+	}
+
+	return rows
+}
+
+func reloadResult(r *haproxy.ReloadResult) string {
+	switch {
+	case r == nil:
+		return ""
+	case !r.Reported:
+		return styles.ActiveStyle.Render("reload requested, this HAProxy version does not report its outcome") + "\n"
+	case r.Success:
+		return styles.ActiveStyle.Render("reload succeeded") + "\n" + styles.ResponseStyle.Render(r.Logs) + "\n"
+	default:
+		return styles.ErrorStyle.Render("reload failed") + "\n" + styles.ResponseStyle.Render(r.Logs) + "\n"
+	}
+}
+
+func detectMaster(s *socket.Client) tea.Cmd {
+	return func() tea.Msg {
+		processes, err := socket.Detect(context.Background(), s)
+		if err != nil {
+			return err
+		}
+		if processes == nil {
+			return nil
+		}
+
+		return MasterDetected(processes)
+	}
+}
+
+func fetchProcesses(s *socket.Client) tea.Cmd {
+	return func() tea.Msg {
+		processes, err := socket.Processes(context.Background(), s)
+		if err != nil {
+			return err
+		}
+
+		return processes
+	}
+}
+
+func selectWorker(s *socket.Client, p haproxy.Process) tea.Cmd {
+	return func() tea.Msg {
+		s.SetTarget(p.Target())
+
+		return WorkerSelected(p)
+	}
+}
+
+// selectCurrentWorker routes commands to the newest worker, e.g. after a reload
+func selectCurrentWorker(s *socket.Client) tea.Cmd {
+	return func() tea.Msg {
+		processes, err := socket.Processes(context.Background(), s)
+		if err != nil {
+			return err
+		}
+
+		w := haproxy.CurrentWorker(processes)
+		if w == nil {
+			return processes
+		}
+		s.SetTarget(w.Target())
+
+		return WorkerSelected(*w)
+	}
+}
+
+func reloadHAProxy(s *socket.Client) tea.Cmd {
+	return func() tea.Msg {
+		out, err := socket.ExecMaster(context.Background(), s, "reload")
+		if err != nil {
+			return err
+		}
+
+		return haproxy.ParseReload(*out)
+	}
+}
@@ -0,0 +1,138 @@
+package components
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/fake"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestProcessesPage(t *testing.T) {
+	t.Parallel()
+
+	processes := []haproxy.Process{
+		{Pid: 1, Type: haproxy.MASTER, Uptime: "0d00h02m10s", Version: "3.1.0"},
+		{Pid: 31, Type: haproxy.WORKER, Uptime: "0d00h00m09s", Version: "3.1.0"},
+		{Pid: 8, Type: haproxy.WORKER, Reloads: 1, Uptime: "0d00h02m10s", Version: "3.1.0", Old: true},
+	}
+
+	model := func() ProcessesPage {
+		return NewProcessesPage(nil)
+	}
+
+	masterModel := func(t *testing.T) ProcessesPage {
+		srv, err := fake.NewMasterServer()
+		assert.Nil(t, err)
+		t.Cleanup(func() { _ = srv.Close() })
+
+		return NewProcessesPage(dial(t, srv))
+	}
+
+	t.Run("New", func(t *testing.T) {
+		m := NewProcessesPage(nil)
+		assert.NotNil(t, m)
+		assert.NotNil(t, m.table)
+		assert.NotNil(t, m.keys)
+	})
+
+	t.Run("Init detects no master", func(t *testing.T) {
+		conn := &socket.DummySocket{Output: []byte("The following commands are valid at this level:\n  help : foo")}
+		m := NewProcessesPage(socket.NewClient(func() (net.Conn, error) { return conn, nil }))
+
+		assert.Nil(t, m.Init()())
+	})
+
+	t.Run("Init detects master", func(t *testing.T) {
+		m := masterModel(t)
+		res := m.Init()()
+
+		assert.IsType(t, MasterDetected{}, res)
+		assert.Len(t, res, 2)
+		assert.Equal(t, "@!8", m.socket.Target())
+	})
+
+	t.Run("Update Processes", func(t *testing.T) {
+		m, cmd := model().Update(processes)
+
+		assert.Nil(t, cmd)
+		assert.Len(t, m.table.Rows(), 3)
+		assert.Contains(t, m.View(), "old")
+	})
+
+	t.Run("Update Select Worker", func(t *testing.T) {
+		m := masterModel(t)
+		m, _ = m.Update(m.Init()())
+		m.table.SetCursor(1)
+
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		cmds := reflect.ValueOf(cmd()).Convert(reflect.TypeOf([]tea.Cmd{})).Interface().([]tea.Cmd)
+
+		assert.Equal(t, WorkerSelected(haproxy.Process{Pid: 8, Type: haproxy.WORKER, Uptime: m.processes[1].Uptime, Version: fake.Version}), cmds[0]())
+		assert.IsType(t, ActivateStatusPageCmd(), cmds[1])
+	})
+
+	t.Run("Update Select Master is ignored", func(t *testing.T) {
+		m, _ := model().Update(processes)
+
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Nil(t, cmd)
+	})
+
+	t.Run("Update Reload", func(t *testing.T) {
+		m := masterModel(t)
+		m, _ = m.Update(m.Init()())
+
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.View(), "reload haproxy?")
+
+		m, cmd = m.Update(keyMsg('y'))
+		res := cmd()
+		assert.Equal(t, haproxy.ReloadResult{Reported: true, Success: true, Logs: "[NOTICE]   (1) : haproxy version is 3.1.0-fake\n[NOTICE]   (1) : path to executable is /usr/local/sbin/haproxy"}, res)
+
+		// after a reload, commands are sent to the new worker
+		m, cmd = m.Update(res)
+		assert.Equal(t, 9, cmd().(WorkerSelected).Pid)
+		assert.Equal(t, "@!9", m.socket.Target())
+		assert.Contains(t, m.View(), "reload succeeded")
+	})
+
+	t.Run("Update Reload Cancel", func(t *testing.T) {
+		m := masterModel(t)
+		m, _ = m.Update(m.Init()())
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+		m, cmd := m.Update(keyMsg('n'))
+
+		assert.Nil(t, cmd)
+		assert.NotContains(t, m.View(), "reload haproxy?")
+	})
+
+	t.Run("View Failed Reload", func(t *testing.T) {
+		m, _ := model().Update(haproxy.ReloadResult{Reported: true, Logs: "[ALERT] config error"})
+
+		assert.Contains(t, m.View(), "reload failed")
+		assert.Contains(t, m.View(), "[ALERT] config error")
+	})
+
+	t.Run("Update Goto Status Page", func(t *testing.T) {
+		_, cmd := model().Update(tea.KeyMsg{Type: tea.KeyBackspace})
+
+		assert.IsType(t, ActivateStatusPageCmd(), cmd)
+	})
+
+	t.Run("Supports", func(t *testing.T) {
+		m := model()
+
+		assert.True(t, m.Supports(MasterDetected{}, false))
+		assert.True(t, m.Supports([]haproxy.Process{}, false))
+		assert.True(t, m.Supports(WorkerSelected{}, false))
+		assert.True(t, m.Supports(haproxy.ReloadResult{}, false))
+		assert.True(t, m.Supports(tea.KeyMsg{}, true))
+		assert.False(t, m.Supports(tea.KeyMsg{}, false))
+	})
+}
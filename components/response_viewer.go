@@ -0,0 +1,470 @@
+package components
+
+import (
+	"fmt"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"haproxy-runtime-cli/styles"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ResponseViewer scrolls through a response, searches it and renders csv, tables and key-value lists aligned
+type ResponseViewer struct {
+	keys     responseViewerKeyMap
+	help     help.Model
+	viewport viewport.Model
+	raw      string
+	format   responseFormat
+	// lines of the rendered response, header counts the heading lines of tables
+	lines  []string
+	header int
+	// plain shows the response as HAProxy sent it
+	plain bool
+	// input takes the search query or the file to save the response to, depending on the mode
+	input   textinput.Model
+	mode    viewerMode
+	query   string
+	matches []int
+	match   int
+	message string
+}
+
+type responseViewerKeyMap struct {
+	Scroll    key.Binding
+	Pan       key.Binding
+	Search    key.Binding
+	NextMatch key.Binding
+	Plain     key.Binding
+	Copy      key.Binding
+	Save      key.Binding
+	Apply     key.Binding
+	Cancel    key.Binding
+	Overwrite key.Binding
+	Keep      key.Binding
+}
+
+type viewerMode int
+
+const (
+	viewing viewerMode = iota
+	searching
+	saving
+	// overwriting asks before the response replaces an existing file
+	overwriting
+)
+
+// responseFormat is the layout of a response detected by detectFormat
+type responseFormat int
+
+const (
+	plainFormat responseFormat = iota
+	csvFormat
+	tableFormat
+	keyValueFormat
+)
+
+func (f responseFormat) String() string {
+	return [...]string{"text", "csv", "table", "key-value"}[f]
+}
+
+// responseSaved is the file a response was written to
+type responseSaved string
+
+var keyValueLine = regexp.MustCompile(`^[^:\s][^:]*:( |$)`)
+
+func NewResponseViewer() ResponseViewer {
+	input := textinput.New()
+	input.PromptStyle = styles.ComplementStyle
+
+	return ResponseViewer{
+		keys: responseViewerKeyMap{
+			Scroll: key.NewBinding(
+				key.WithKeys("pgup", "pgdown", "shift+up", "shift+down"),
+				key.WithHelp("pgup/pgdown", "scroll"),
+			),
+			Pan: key.NewBinding(
+				key.WithKeys("shift+left", "shift+right"),
+				key.WithHelp("shift+←/→", "pan"),
+			),
+			Search: key.NewBinding(
+				key.WithKeys("ctrl+f"),
+				key.WithHelp("ctrl+f", "search"),
+			),
+			NextMatch: key.NewBinding(
+				key.WithKeys("ctrl+g"),
+				key.WithHelp("ctrl+g", "next match"),
+				key.WithDisabled(),
+			),
+			Plain: key.NewBinding(
+				key.WithKeys("ctrl+t"),
+				key.WithHelp("ctrl+t", "raw"),
+				key.WithDisabled(),
+			),
+			Copy: key.NewBinding(
+				key.WithKeys("ctrl+y"),
+				key.WithHelp("ctrl+y", "copy"),
+			),
+			Save: key.NewBinding(
+				key.WithKeys("ctrl+s"),
+				key.WithHelp("ctrl+s", "save"),
+			),
+			Apply: key.NewBinding(
+				key.WithKeys("enter"),
+				key.WithHelp("enter", "apply"),
+			),
+			Cancel: key.NewBinding(
+				key.WithKeys("esc"),
+				key.WithHelp("esc", "cancel"),
+			),
+			Overwrite: key.NewBinding(
+				key.WithKeys("y", "enter"),
+				key.WithHelp("y", "overwrite"),
+			),
+			Keep: key.NewBinding(
+				key.WithKeys("n", "esc"),
+				key.WithHelp("n", "cancel"),
+			),
+		},
+		help:     help.New(),
+		viewport: viewport.New(80, 20),
+		input:    input,
+	}
+}
+
+// SetResponse shows a new response, a running search is applied to it
+func (v *ResponseViewer) SetResponse(response string) {
+	v.raw = response
+	v.format = detectFormat(response)
+	v.message = ""
+	v.keys.Plain.SetEnabled(v.format != plainFormat)
+	v.render()
+	v.viewport.GotoTop()
+	v.viewport.ResetIndent()
+}
+
+func (v *ResponseViewer) SetSize(width int, height int) {
+	v.viewport.Width = width
+	v.viewport.Height = max(height, 1)
+}
+
+// Handles tells if a key is meant for the viewer, in the search or save mode it takes every key
+func (v ResponseViewer) Handles(msg tea.KeyMsg) bool {
+	if v.mode != viewing {
+		return true
+	}
+
+	return key.Matches(msg, v.keys.Scroll, v.keys.Pan, v.keys.Search, v.keys.NextMatch, v.keys.Plain, v.keys.Copy, v.keys.Save)
+}
+
+func (v ResponseViewer) Update(msg tea.Msg) (ResponseViewer, tea.Cmd) {
+	switch msg := msg.(type) {
+	case responseSaved:
+		v.message = "saved to " + string(msg)
+	case tea.KeyMsg:
+		if v.mode == overwriting {
+			return v.updateOverwrite(msg)
+		}
+		if v.mode != viewing {
+			return v.updateInput(msg)
+		}
+
+		switch {
+		case key.Matches(msg, v.keys.Scroll):
+			switch msg.String() {
+			case "pgup":
+				v.viewport.ViewUp()
+			case "pgdown":
+				v.viewport.ViewDown()
+			case "shift+up":
+				v.viewport.LineUp(1)
+			case "shift+down":
+				v.viewport.LineDown(1)
+			}
+		case key.Matches(msg, v.keys.Pan):
+			if msg.String() == "shift+left" {
+				v.viewport.MoveLeft(8)
+			} else {
+				v.viewport.MoveRight(8)
+			}
+		case key.Matches(msg, v.keys.Search):
+			v.mode = searching
+			v.input.Prompt = "search: "
+			v.input.SetValue(v.query)
+			v.input.CursorEnd()
+			return v, v.input.Focus()
+		case key.Matches(msg, v.keys.NextMatch):
+			v.match = (v.match + 1) % len(v.matches)
+			v.viewport.SetYOffset(v.matches[v.match])
+		case key.Matches(msg, v.keys.Plain):
+			v.plain = !v.plain
+			v.render()
+		case key.Matches(msg, v.keys.Copy):
+			v.message = "copied"
+			return v, copyToClipboard(v.raw)
+		case key.Matches(msg, v.keys.Save):
+			v.mode = saving
+			v.input.Prompt = "save to: "
+			v.input.SetValue("response.txt")
+			v.input.CursorEnd()
+			return v, v.input.Focus()
+		}
+	}
+
+	return v, nil
+}
+
+// updateInput searches while typing, or saves the response to the typed file
+func (v ResponseViewer) updateInput(msg tea.KeyMsg) (ResponseViewer, tea.Cmd) {
+	mode := v.mode
+
+	switch {
+	case key.Matches(msg, v.keys.Apply):
+		v.input.Blur()
+		if mode == saving {
+			if _, err := os.Stat(v.input.Value()); err == nil {
+				v.mode = overwriting
+				return v, nil
+			}
+			v.mode = viewing
+			return v, saveResponse(v.input.Value(), v.raw)
+		}
+		v.mode = viewing
+		if len(v.matches) > 0 {
+			v.viewport.SetYOffset(v.matches[v.match])
+		}
+		return v, nil
+	case key.Matches(msg, v.keys.Cancel):
+		v.mode = viewing
+		v.input.Blur()
+		if mode == searching {
+			v.query = ""
+			v.render()
+		}
+		return v, nil
+	}
+
+	var cmd tea.Cmd
+	v.input, cmd = v.input.Update(msg)
+	if mode == searching {
+		v.query = v.input.Value()
+		v.render()
+	}
+
+	return v, cmd
+}
+
+// updateOverwrite saves the response over the existing file once confirmed, otherwise asks for another file
+func (v ResponseViewer) updateOverwrite(msg tea.KeyMsg) (ResponseViewer, tea.Cmd) {
+	switch {
+	case key.Matches(msg, v.keys.Overwrite):
+		v.mode = viewing
+		return v, saveResponse(v.input.Value(), v.raw)
+	case key.Matches(msg, v.keys.Keep):
+		v.mode = saving
+		return v, v.input.Focus()
+	}
+
+	return v, nil
+}
+
+func (v ResponseViewer) View() string {
+	var status []string
+	if v.format != plainFormat && !v.plain {
+		status = append(status, v.format.String())
+	}
+	if v.query != "" {
+		status = append(status, fmt.Sprintf("%d matching lines", len(v.matches)))
+	}
+	if v.message != "" {
+		status = append(status, v.message)
+	}
+
+	footer := v.help.ShortHelpView([]key.Binding{v.keys.Scroll, v.keys.Pan, v.keys.Search, v.keys.NextMatch, v.keys.Plain, v.keys.Copy, v.keys.Save})
+	switch v.mode {
+	case overwriting:
+		footer = styles.ActiveStyle.Render(v.input.Value()+" exists, overwrite it?") + "  " + v.help.ShortHelpView([]key.Binding{v.keys.Overwrite, v.keys.Keep})
+	case searching, saving:
+		footer = v.input.View() + "  " + v.help.ShortHelpView([]key.Binding{v.keys.Apply, v.keys.Cancel})
+	}
+	if len(status) > 0 {
+		footer = styles.ComplementStyle.Render(strings.Join(status, " · ")) + "\n" + footer
+	}
+
+	return v.viewport.View() + "\n" + footer
+}
+
+// render lays out the response and highlights the matches of the query
+func (v *ResponseViewer) render() {
+	v.lines, v.header = strings.Split(strings.TrimRight(v.raw, "\n"), "\n"), 0
+	if !v.plain {
+		v.lines, v.header = formatResponse(v.raw, v.format)
+	}
+
+	v.matches = nil
+	query := regexp.MustCompile("(?i)" + regexp.QuoteMeta(v.query))
+	content := make([]string, len(v.lines))
+	for i, line := range v.lines {
+		switch {
+		case v.query != "" && query.MatchString(line):
+			v.matches = append(v.matches, i)
+			content[i] = highlight(line, query)
+		case i < v.header:
+			content[i] = styles.ActiveStyle.Render(line)
+		default:
+			content[i] = line
+		}
+	}
+
+	v.match = 0
+	v.keys.NextMatch.SetEnabled(len(v.matches) > 0)
+	v.viewport.SetContent(strings.Join(content, "\n"))
+}
+
+// highlight marks every match of the query, the offsets of the matches are the ones of the line itself
+// as case folding may change the length of a string (e.g. `Ⱥ`)
+func highlight(line string, query *regexp.Regexp) string {
+	return query.ReplaceAllStringFunc(line, func(m string) string {
+		return styles.HighlightStyle.Render(m)
+	})
+}
+
+// detectFormat recognises the csv of `show stat`, tables with a `# ` header like `show servers state`
+// and key-value lists like `show info`
+func detectFormat(response string) responseFormat {
+	lines := nonEmptyLines(response)
+	if len(lines) < 2 {
+		return plainFormat
+	}
+
+	header, rows := tableHeader(lines)
+	switch {
+	case header != "" && strings.Contains(header, ","):
+		return csvFormat
+	case header != "" && len(rows) > 0:
+		columns := len(strings.Fields(header))
+		for _, r := range rows {
+			if len(strings.Fields(r)) != columns {
+				return plainFormat
+			}
+		}
+		return tableFormat
+	}
+
+	matching := 0
+	for _, l := range lines {
+		if keyValueLine.MatchString(l) {
+			matching++
+		}
+	}
+	if matching*10 >= len(lines)*8 {
+		return keyValueFormat
+	}
+
+	return plainFormat
+}
+
+// tableHeader finds a `# ` header, preceded by at most a format version like in `show servers state`
+func tableHeader(lines []string) (string, []string) {
+	for i, l := range lines[:min(2, len(lines))] {
+		if strings.HasPrefix(l, "# ") {
+			return strings.TrimPrefix(l, "# "), lines[i+1:]
+		}
+	}
+
+	return "", nil
+}
+
+// formatResponse aligns the columns of the response, returning its lines and the number of heading lines
+func formatResponse(response string, format responseFormat) ([]string, int) {
+	lines := nonEmptyLines(response)
+
+	switch format {
+	case csvFormat, tableFormat:
+		var intro []string
+		if !strings.HasPrefix(lines[0], "# ") {
+			intro, lines = lines[:1], lines[1:]
+		}
+
+		split := strings.Fields
+		if format == csvFormat {
+			split = func(s string) []string { return strings.Split(strings.TrimSuffix(s, ","), ",") }
+		}
+		var rows [][]string
+		for _, l := range lines {
+			rows = append(rows, split(strings.TrimPrefix(l, "# ")))
+		}
+
+		return append(intro, alignColumns(rows)...), len(intro) + 1
+	case keyValueFormat:
+		width := 0
+		for _, l := range lines {
+			if k, _, ok := strings.Cut(l, ":"); ok && keyValueLine.MatchString(l) {
+				width = max(width, lipgloss.Width(k)+1)
+			}
+		}
+		res := make([]string, len(lines))
+		for i, l := range lines {
+			if k, value, ok := strings.Cut(l, ":"); ok && keyValueLine.MatchString(l) {
+				l = fillRight(k+":", width) + " " + strings.TrimSpace(value)
+			}
+			res[i] = l
+		}
+		return res, 0
+	}
+
+	return strings.Split(strings.TrimRight(response, "\n"), "\n"), 0
+}
+
+func alignColumns(rows [][]string) []string {
+	var widths []int
+	for _, r := range rows {
+		for i, c := range r {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], lipgloss.Width(c))
+		}
+	}
+
+	res := make([]string, len(rows))
+	for i, r := range rows {
+		cells := make([]string, len(r))
+		for j, c := range r {
+			cells[j] = fillRight(c, widths[j])
+		}
+		res[i] = strings.TrimRight(strings.Join(cells, "  "), " ")
+	}
+
+	return res
+}
+
+func fillRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-lipgloss.Width(s), 0))
+}
+
+func nonEmptyLines(s string) []string {
+	var res []string
+	for _, l := range strings.Split(s, "\n") {
+		if strings.TrimSpace(l) != "" {
+			res = append(res, l)
+		}
+	}
+
+	return res
+}
+
+func saveResponse(path string, response string) tea.Cmd {
+	return func() tea.Msg {
+		if err := os.WriteFile(path, []byte(response), 0o644); err != nil {
+			return err
+		}
+
+		return responseSaved(path)
+	}
+}
@@ -0,0 +1,242 @@
+package components
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const statResponse = `# pxname,svname,qcur,status,
+http,FRONTEND,,OPEN,
+default,apache,0,UP,
+default,BACKEND,0,UP,
+`
+
+const serversStateResponse = `1
+# be_id be_name srv_id srv_name
+4 default 2 apache
+4 default 3 nginx
+`
+
+const infoResponse = `Name: HAProxy
+Version: 2.9.4
+Uptime_sec: 1234
+`
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		response string
+		format   responseFormat
+	}{
+		{"", plainFormat},
+		{"Unknown command", plainFormat},
+		{statResponse, csvFormat},
+		{serversStateResponse, tableFormat},
+		{"# a b\n1 2\n3\n", plainFormat},
+		{infoResponse, keyValueFormat},
+		{"one line\nanother line\n", plainFormat},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.format, detectFormat(tt.response), tt.response)
+	}
+}
+
+func TestFormatResponse(t *testing.T) {
+	lines, header := formatResponse(statResponse, csvFormat)
+	assert.Equal(t, 1, header)
+	assert.Equal(t, []string{
+		"pxname   svname    qcur  status",
+		"http     FRONTEND        OPEN",
+		"default  apache    0     UP",
+		"default  BACKEND   0     UP",
+	}, lines)
+
+	lines, header = formatResponse(serversStateResponse, tableFormat)
+	assert.Equal(t, 2, header)
+	assert.Equal(t, []string{
+		"1",
+		"be_id  be_name  srv_id  srv_name",
+		"4      default  2       apache",
+		"4      default  3       nginx",
+	}, lines)
+
+	lines, header = formatResponse(infoResponse, keyValueFormat)
+	assert.Equal(t, 0, header)
+	assert.Equal(t, []string{
+		"Name:       HAProxy",
+		"Version:    2.9.4",
+		"Uptime_sec: 1234",
+	}, lines)
+}
+
+func TestResponseViewer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("View", func(t *testing.T) {
+		v := NewResponseViewer()
+		v.SetResponse(statResponse)
+
+		res := v.View()
+		assert.Contains(t, res, "default  apache    0     UP")
+		assert.Contains(t, res, "csv")
+		assert.Contains(t, res, "ctrl+t raw")
+	})
+
+	t.Run("View Plain", func(t *testing.T) {
+		v := NewResponseViewer()
+		v.SetResponse("Unknown command")
+
+		assert.Contains(t, v.View(), "Unknown command")
+		assert.NotContains(t, v.View(), "ctrl+t raw")
+	})
+
+	t.Run("Update Raw", func(t *testing.T) {
+		v := NewResponseViewer()
+		v.SetResponse(statResponse)
+
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+
+		assert.Contains(t, v.View(), "default,apache,0,UP,")
+		assert.NotContains(t, v.View(), "csv")
+	})
+
+	t.Run("Update Scroll", func(t *testing.T) {
+		v := NewResponseViewer()
+		v.SetSize(20, 2)
+		v.SetResponse("a\nb\nc\nd\ne\n")
+
+		assert.True(t, v.Handles(tea.KeyMsg{Type: tea.KeyPgDown}))
+		assert.False(t, v.Handles(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}))
+
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+		assert.Equal(t, 2, v.viewport.YOffset)
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyShiftUp})
+		assert.Equal(t, 1, v.viewport.YOffset)
+
+		// a new response starts at the top again
+		v.SetResponse("a\nb\nc\nd\ne\n")
+		assert.Equal(t, 0, v.viewport.YOffset)
+	})
+
+	t.Run("Update Search", func(t *testing.T) {
+		v := NewResponseViewer()
+		v.SetSize(40, 1)
+		v.SetResponse(statResponse)
+
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+		assert.True(t, v.Handles(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")}))
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("DEFAULT")})
+		assert.Equal(t, []int{2, 3}, v.matches)
+		assert.Contains(t, v.View(), "2 matching lines")
+
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, 2, v.viewport.YOffset)
+		assert.False(t, v.Handles(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")}))
+
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+		assert.Equal(t, 3, v.viewport.YOffset)
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+		assert.Equal(t, 2, v.viewport.YOffset)
+
+		// esc while searching clears the search
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.Empty(t, v.matches)
+		assert.NotContains(t, v.View(), "matching lines")
+	})
+
+	t.Run("Update Save", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "stat.csv")
+		v := NewResponseViewer()
+		v.SetResponse(statResponse)
+
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+		assert.Contains(t, v.View(), "save to: response.txt")
+		v.input.SetValue(path)
+		v, cmd := v.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		msg := cmd()
+		assert.Equal(t, responseSaved(path), msg)
+		saved, err := os.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, statResponse, string(saved))
+
+		v, _ = v.Update(msg)
+		assert.Contains(t, v.View(), "saved to "+path)
+	})
+
+	t.Run("Update Save Overwrite", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "stat.csv")
+		assert.Nil(t, os.WriteFile(path, []byte("kept"), 0o644))
+		v := NewResponseViewer()
+		v.SetResponse(statResponse)
+
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+		v.input.SetValue(path)
+		v, cmd := v.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Nil(t, cmd)
+		assert.Contains(t, v.View(), path+" exists, overwrite it?")
+
+		// declining asks for another file
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+		assert.Contains(t, v.View(), "save to: "+path)
+		saved, _ := os.ReadFile(path)
+		assert.Equal(t, "kept", string(saved))
+
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		_, cmd = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		assert.Equal(t, responseSaved(path), cmd())
+		saved, _ = os.ReadFile(path)
+		assert.Equal(t, statResponse, string(saved))
+	})
+
+	t.Run("Update Save Error", func(t *testing.T) {
+		v := NewResponseViewer()
+		v.SetResponse(statResponse)
+
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+		v.input.SetValue(filepath.Join(t.TempDir(), "missing", "stat.csv"))
+		_, cmd := v.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.Error(t, cmd().(error))
+	})
+
+	t.Run("Update Copy", func(t *testing.T) {
+		v := NewResponseViewer()
+		v.SetResponse(statResponse)
+
+		v, cmd := v.Update(tea.KeyMsg{Type: tea.KeyCtrlY})
+
+		assert.NotNil(t, cmd)
+		assert.Contains(t, v.View(), "copied")
+	})
+
+	t.Run("Highlight", func(t *testing.T) {
+		res := highlight("Default default", regexp.MustCompile("(?i)default"))
+
+		assert.Equal(t, 2, strings.Count(res, "efault"))
+		assert.Contains(t, res, "Default")
+
+		// lower casing changes the length of Ⱥ and İ
+		assert.Contains(t, highlight("Ⱥx", regexp.MustCompile("(?i)x")), "Ⱥ")
+		res = highlight("İstanbul", regexp.MustCompile("(?i)stan"))
+		assert.True(t, strings.HasPrefix(res, "İ"))
+		assert.True(t, strings.HasSuffix(res, "bul"))
+	})
+
+	t.Run("Update Search Unicode", func(t *testing.T) {
+		v := NewResponseViewer()
+		v.SetResponse("Ⱥx\nİstanbul\nother")
+
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+		v, _ = v.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+
+		assert.Equal(t, []int{0}, v.matches)
+		assert.Contains(t, v.View(), "Ⱥ")
+	})
+}
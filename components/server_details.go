@@ -0,0 +1,224 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"haproxy-runtime-cli/styles"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServerDetails shows every field of a server next to its `show stat` counters
+type ServerDetails struct {
+	socket  *socket.Client
+	keys    serverDetailsKeyMap
+	help    help.Model
+	backend haproxy.Backend
+	// server is nil while the details are closed
+	server *haproxy.Server
+	stat   *haproxy.Stat
+	copied bool
+}
+
+type serverDetailsKeyMap struct {
+	Close key.Binding
+	Copy  key.Binding
+}
+
+// serverStat is the `show stat` line of a server
+type serverStat struct {
+	backend string
+	server  string
+	stats   []haproxy.Stat
+}
+
+func NewServerDetails(socket *socket.Client) ServerDetails {
+	return ServerDetails{
+		socket: socket,
+		keys: serverDetailsKeyMap{
+			Close: key.NewBinding(
+				key.WithKeys("esc", "backspace", "i"),
+				key.WithHelp("esc", "close"),
+			),
+			Copy: key.NewBinding(
+				key.WithKeys("y"),
+				key.WithHelp("y", "copy raw line"),
+			),
+		},
+		help: help.New(),
+	}
+}
+
+// Open shows the details of a server and fetches its counters
+func (d ServerDetails) Open(backend haproxy.Backend, server haproxy.Server) (ServerDetails, tea.Cmd) {
+	d.backend = backend
+	d.server = &server
+	d.stat = nil
+	d.copied = false
+
+	return d, fetchServerStat(d.socket, backend, server)
+}
+
+func (d ServerDetails) IsOpen() bool {
+	return d.server != nil
+}
+
+func (d ServerDetails) Update(msg tea.Msg) (ServerDetails, tea.Cmd) {
+	if !d.IsOpen() {
+		return d, nil
+	}
+
+	switch msg := msg.(type) {
+	case []haproxy.Backend:
+		// follow the refreshes of the status page
+		for _, b := range msg {
+			for _, s := range b.Servers {
+				if b.Name == d.backend.Name && s.Name == d.server.Name {
+					d.backend, d.server = b, &s
+					return d, fetchServerStat(d.socket, b, s)
+				}
+			}
+		}
+	case serverStat:
+		if msg.backend != d.backend.Name || msg.server != d.server.Name {
+			return d, nil
+		}
+		for _, s := range msg.stats {
+			if s.Type == haproxy.SERVER && s.ProxyName == msg.backend && s.ServiceName == msg.server {
+				d.stat = &s
+			}
+		}
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, d.keys.Close):
+			d.server = nil
+		case key.Matches(msg, d.keys.Copy):
+			d.copied = true
+			return d, copyToClipboard(d.server.Raw)
+		}
+	}
+
+	return d, nil
+}
+
+func (d ServerDetails) View() string {
+	if !d.IsOpen() {
+		return ""
+	}
+
+	s := d.server
+	fields := detailFields{
+		{"Backend", fmt.Sprintf("%s (%d)", d.backend.Name, d.backend.Id)},
+		{"Server", fmt.Sprintf("%s (%d)", s.Name, s.Id)},
+		{"Address", fmt.Sprintf("%s:%d", orDash(s.Address.String()), s.Port)},
+		{"FQDN", s.Fqdn},
+		{"SRV record", s.SrvRecord},
+		{"SSL", strconv.FormatBool(s.UseSSL)},
+		{"State", s.State},
+		{"Admin state", s.AdminState},
+		{"Last change", since(s.LastStateChanged)},
+		{"Weight", fmt.Sprintf("%d (initial %d)", s.UserWeight, s.CalculatedWeight)},
+		{"Check", fmt.Sprintf("%s, status %s, result %s", s.CheckState, s.SrvCheckStatus, s.SrvCheckResult)},
+		{"Checks succeeded", strconv.Itoa(s.ChecksSucceeded)},
+		{"Check address", fmt.Sprintf("%s:%d", orDash(s.CheckAddr), s.CheckPort)},
+		{"Agent", s.SrvAgentState},
+		{"Agent address", fmt.Sprintf("%s:%d", orDash(s.AgentAddr), s.AgentPort)},
+		{"Forced ids", fmt.Sprintf("backend %d, server %d", s.BkFForcedID, s.SrvFForcedID)},
+	}
+
+	counters := detailFields{{"Counters", "loading"}}
+	if st := d.stat; st != nil {
+		counters = detailFields{
+			{"Sessions", fmt.Sprintf("%d current, %d max, %d limit", st.CurrentSessions, st.MaxSessions, st.SessionLimit)},
+			{"Total sessions", strconv.FormatInt(st.TotalSessions, 10)},
+			{"Session rate", fmt.Sprintf("%d/s", st.SessionRate)},
+			{"Queue", fmt.Sprintf("%d current, %d max, %d limit", st.CurrentQueue, st.MaxQueue, st.QueueLimit)},
+			{"Bytes", fmt.Sprintf("%d in, %d out", st.BytesIn, st.BytesOut)},
+			{"Errors", fmt.Sprintf("%d connection, %d response", st.ConnectionErrors, st.ResponseErrors)},
+			{"Retries", fmt.Sprintf("%d, %d redispatches", st.Retries, st.Redispatches)},
+			{"Responses", fmt.Sprintf("1xx %d, 2xx %d, 3xx %d, 4xx %d, 5xx %d, other %d",
+				st.Responses.Informational, st.Responses.Success, st.Responses.Redirection,
+				st.Responses.ClientError, st.Responses.ServerError, st.Responses.Other)},
+			{"Times", fmt.Sprintf("queue %dms, connect %dms, response %dms, total %dms", st.QueueTime, st.ConnectTime, st.ResponseTime, st.TotalTime)},
+			{"Last check", fmt.Sprintf("%s %s", st.CheckStatus, st.LastCheck)},
+			{"Check failures", fmt.Sprintf("%d, %d downs", st.CheckFailures, st.CheckDowns)},
+			{"Downtime", (time.Duration(st.Downtime) * time.Second).String()},
+		}
+	}
+
+	status := ""
+	if d.copied {
+		status = styles.ComplementStyle.Render("copied") + " "
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, fields.render(), "    ", counters.render()) + "\n\n" +
+		styles.ComplementStyle.Render("raw: "+s.Raw) + "\n\n" +
+		status + d.help.ShortHelpView([]key.Binding{d.keys.Copy, d.keys.Close})
+}
+
+type detailFields [][2]string
+
+func (f detailFields) render() string {
+	width := 0
+	for _, field := range f {
+		width = max(width, len(field[0]))
+	}
+
+	var lines []string
+	for _, field := range f {
+		lines = append(lines, styles.ActiveStyle.Render(fmt.Sprintf("%-*s", width+2, field[0]))+field[1])
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// since is the human readable time since t, like "3d4h ago" or "1m20s ago"
+func since(t time.Time) string {
+	d := time.Since(t).Round(time.Second)
+	if d < 0 {
+		d = 0
+	}
+
+	days := d / (24 * time.Hour)
+	if days == 0 {
+		return d.String() + " ago"
+	}
+
+	return fmt.Sprintf("%dd%dh ago", days, (d%(24*time.Hour))/time.Hour)
+}
+
+func orDash(s string) string {
+	if s == "" || s == "<nil>" {
+		return "-"
+	}
+
+	return s
+}
+
+// copyToClipboard copies with an OSC 52 escape sequence, which works through ssh if the terminal supports it
+func copyToClipboard(s string) tea.Cmd {
+	return func() tea.Msg {
+		termenv.Copy(s)
+		return nil
+	}
+}
+
+func fetchServerStat(s *socket.Client, backend haproxy.Backend, server haproxy.Server) tea.Cmd {
+	return socket.ExecCmd[serverStat](
+		context.Background(),
+		s,
+		fmt.Sprintf("show stat %d 4 %d", backend.Id, server.Id),
+		func(out *string) (serverStat, error) {
+			stats, err := haproxy.ParseStat(*out)
+			return serverStat{backend: backend.Name, server: server.Name, stats: stats}, err
+		},
+	)
+}
@@ -0,0 +1,81 @@
+package components
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/haproxy"
+	"testing"
+	"time"
+)
+
+func TestServerDetails(t *testing.T) {
+	t.Parallel()
+
+	// fakeDetails opens the details of default/apache
+	fakeDetails := func(t *testing.T) ServerDetails {
+		client := fakeClient(t)
+
+		backends := fetchBackends(client)().([]haproxy.Backend)
+		d, cmd := NewServerDetails(client).Open(backends[0], backends[0].Servers[1])
+		d, _ = d.Update(cmd())
+
+		return d
+	}
+
+	t.Run("Closed", func(t *testing.T) {
+		d := NewServerDetails(nil)
+
+		assert.False(t, d.IsOpen())
+		assert.Empty(t, d.View())
+	})
+
+	t.Run("Open", func(t *testing.T) {
+		d := fakeDetails(t)
+		assert.True(t, d.IsOpen())
+
+		res := d.View()
+		assert.Contains(t, res, "default (4)")
+		assert.Contains(t, res, "apache (2)")
+		assert.Contains(t, res, "151.101.2.132:443")
+		assert.Contains(t, res, "2 current, 4 max")
+		assert.Contains(t, res, "1191204 in, 24099101 out")
+		assert.Contains(t, res, "raw: 4 default 2 apache 151.101.2.132")
+	})
+
+	t.Run("Follow Refresh", func(t *testing.T) {
+		d := fakeDetails(t)
+
+		d, cmd := d.Update([]haproxy.Backend{{Id: 4, Name: "default", Servers: []haproxy.Server{{Id: 2, Name: "apache", UserWeight: 10}}}})
+		assert.NotNil(t, cmd)
+		assert.Equal(t, 10, d.server.UserWeight)
+	})
+
+	t.Run("Ignore Other Server", func(t *testing.T) {
+		d := fakeDetails(t)
+		stat := d.stat
+
+		d, _ = d.Update(serverStat{backend: "other", server: "apache"})
+		assert.Equal(t, stat, d.stat)
+	})
+
+	t.Run("Copy", func(t *testing.T) {
+		d := fakeDetails(t)
+
+		d, cmd := d.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		assert.NotNil(t, cmd)
+		assert.Contains(t, d.View(), "copied")
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		d := fakeDetails(t)
+
+		d, _ = d.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.False(t, d.IsOpen())
+	})
+
+	t.Run("Since", func(t *testing.T) {
+		assert.Equal(t, "1m20s ago", since(time.Now().Add(-80*time.Second)))
+		assert.Equal(t, "2d3h ago", since(time.Now().Add(-51*time.Hour)))
+		assert.Equal(t, "0s ago", since(time.Now().Add(time.Hour)))
+	})
+}
@@ -0,0 +1,400 @@
+package components
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"haproxy-runtime-cli/styles"
+	"slices"
+	"strings"
+	"time"
+)
+
+// sessionOrders are the columns the sessions can be sorted by, the oldest sessions come first
+var sessionOrders = []string{"age", "frontend", "backend", "server", "source"}
+
+// SessionsPage lists the active sessions, shows the details of one and kills them
+type SessionsPage struct {
+	socket   *socket.Client
+	keys     sessionsPageKeyMap
+	help     help.Model
+	loaded   bool
+	sessions []haproxy.Session
+	// visible are the sessions in the table, sorted by sortedBy or in the order of HAProxy
+	visible  []haproxy.Session
+	sortedBy string
+	table    table.Model
+	// details is the session whose `show sess <id>` dump is shown, empty while listing the sessions
+	details      string
+	viewer       ResponseViewer
+	confirmation Confirmation
+	message      string
+}
+
+type sessionsPageKeyMap struct {
+	listKeyMap
+	Sort       key.Binding
+	Kill       key.Binding
+	KillServer key.Binding
+}
+
+type ActivateSessionsPage bool
+
+// SessionKilled describes the sessions which were shut down
+type SessionKilled string
+
+type sessionList []haproxy.Session
+
+type sessionDetails struct {
+	id   string
+	dump string
+}
+
+func NewSessionsPage(socket *socket.Client) SessionsPage {
+	km := createSessionsKeyMap()
+
+	return SessionsPage{
+		socket:       socket,
+		keys:         km,
+		help:         help.New(),
+		confirmation: NewConfirmation(),
+		viewer:       NewResponseViewer(),
+		table: newTable([]table.Column{
+			{Title: "Id", Width: 14},
+			{Title: "Frontend", Width: 12},
+			{Title: "Backend", Width: 12},
+			{Title: "Server", Width: 12},
+			{Title: "Source", Width: 22},
+			{Title: "Age", Width: 8},
+			{Title: "State", Width: 5},
+			{Title: "Flags (req/res)", Width: 20},
+		}, []key.Binding{km.Open, km.Sort, km.Kill, km.KillServer, km.Refresh, km.GotoStatusPage}),
+	}
+}
+
+func (p SessionsPage) Init() tea.Cmd {
+	return nil
+}
+
+func (p SessionsPage) Update(msg tea.Msg) (SessionsPage, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ActivateSessionsPage:
+		// the sessions are fetched when needed, as `show sess` requires the operator level
+		return p, p.refresh()
+	case WorkerSelected:
+		if !p.loaded {
+			return p, nil
+		}
+		p.details = ""
+		return p, p.refresh()
+	case sessionList:
+		p.loaded = true
+		p.sessions = msg
+		p.updateRows()
+		return p, nil
+	case sessionDetails:
+		if msg.id == p.details {
+			p.viewer.SetResponse(msg.dump)
+		}
+		return p, nil
+	case SessionKilled:
+		// the killed session or the ones of its server are gone, so are their details
+		p.details = ""
+		p.message = string(msg)
+		return p, p.refresh()
+	case responseSaved:
+		var cmd tea.Cmd
+		p.viewer, cmd = p.viewer.Update(msg)
+		return p, cmd
+	case tea.WindowSizeMsg:
+		height := msg.Height - styles.PageStyle.GetVerticalMargins() - 3 - 3 - 2
+		p.table.SetWidth(msg.Width - styles.PageStyle.GetHorizontalMargins())
+		p.table.SetHeight(height - 1)
+		// the viewer has a status and a help line of its own
+		p.viewer.SetSize(msg.Width-styles.PageStyle.GetHorizontalMargins(), height-3)
+	case tea.KeyMsg:
+		if p.confirmation.Asking() {
+			var cmd tea.Cmd
+			p.confirmation, cmd = p.confirmation.Update(msg)
+			return p, cmd
+		}
+		if p.details != "" {
+			return p.updateDetails(msg)
+		}
+
+		session := p.selected()
+		switch {
+		case key.Matches(msg, p.keys.GotoStatusPage):
+			return p, ActivateStatusPageCmd()
+		case key.Matches(msg, p.keys.Refresh):
+			return p, p.refresh()
+		case key.Matches(msg, p.keys.Sort):
+			p.sortedBy = p.nextOrder()
+			p.updateRows()
+			return p, nil
+		case session == nil:
+			// the other actions need a selected session
+		case key.Matches(msg, p.keys.Open):
+			p.details = session.Id
+			p.message = ""
+			p.viewer.SetResponse("")
+			return p, fetchSessionDetails(p.socket, session.Id)
+		case key.Matches(msg, p.keys.Kill):
+			return p.confirmKill(*session), nil
+		case key.Matches(msg, p.keys.KillServer):
+			return p.confirmKillServer(*session), nil
+		}
+
+		var cmd tea.Cmd
+		p.table, cmd = p.table.Update(msg)
+		return p, cmd
+	}
+
+	return p, nil
+}
+
+// updateDetails scrolls the details of a session, which can be killed from there as well
+func (p SessionsPage) updateDetails(msg tea.KeyMsg) (SessionsPage, tea.Cmd) {
+	if p.viewer.Handles(msg) {
+		var cmd tea.Cmd
+		p.viewer, cmd = p.viewer.Update(msg)
+		return p, cmd
+	}
+
+	session := p.session(p.details)
+	switch {
+	case key.Matches(msg, p.keys.Close):
+		p.details = ""
+	case key.Matches(msg, p.keys.Refresh):
+		return p, p.refresh()
+	case session == nil:
+		// the session is gone since the last refresh
+	case key.Matches(msg, p.keys.Kill):
+		return p.confirmKill(*session), nil
+	case key.Matches(msg, p.keys.KillServer):
+		return p.confirmKillServer(*session), nil
+	}
+
+	return p, nil
+}
+
+func (p SessionsPage) confirmKill(s haproxy.Session) SessionsPage {
+	p.confirmation = p.confirmation.Ask(
+		fmt.Sprintf("kill session %s from %s", s.Id, s.Source),
+		changeCmd[SessionKilled](p.socket, "shutdown session "+s.Id, "killed session "+s.Id),
+	)
+
+	return p
+}
+
+func (p SessionsPage) confirmKillServer(s haproxy.Session) SessionsPage {
+	if !s.HasServer() {
+		p.message = "session " + s.Id + " has no server"
+		return p
+	}
+
+	server := s.Backend + "/" + s.Server
+	count := 0
+	for _, o := range p.sessions {
+		if o.Backend == s.Backend && o.Server == s.Server {
+			count++
+		}
+	}
+	p.confirmation = p.confirmation.Ask(
+		fmt.Sprintf("kill the %d sessions on %s", count, server),
+		changeCmd[SessionKilled](p.socket, "shutdown sessions server "+server, "killed the sessions on "+server),
+	)
+
+	return p
+}
+
+func (p SessionsPage) View() string {
+	if p.details != "" {
+		title := styles.ActiveStyle.Render(p.details)
+		if s := p.session(p.details); s != nil {
+			title += styles.ComplementStyle.Render(fmt.Sprintf("  %s → %s/%s, %s", s.Source, s.Backend, s.Server, formatAge(s.Age)))
+		}
+		return title + "\n" + styles.ResponseStyle.Render(p.viewer.View()) + "\n" +
+			p.footer(p.help.ShortHelpView([]key.Binding{p.keys.Kill, p.keys.KillServer, p.keys.Refresh, p.keys.Close}))
+	}
+
+	title := styles.ActiveStyle.Render(fmt.Sprintf("%d sessions", len(p.sessions)))
+	if p.sortedBy != "" {
+		title += styles.ComplementStyle.Render(", by " + p.sortedBy)
+	}
+
+	return title + "\n" + tblStyle.Render(p.table.View()) + "\n" + p.footer(p.table.HelpView())
+}
+
+func (p SessionsPage) Supports(msg tea.Msg, isActive bool) bool {
+	switch msg.(type) {
+	case ActivateSessionsPage, sessionList, sessionDetails, SessionKilled, WorkerSelected, tea.WindowSizeMsg:
+		return true
+	case responseSaved, tea.KeyMsg:
+		if isActive {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p SessionsPage) footer(help string) string {
+	switch {
+	case p.confirmation.Asking():
+		return p.confirmation.View()
+	case p.message != "":
+		return styles.ComplementStyle.Render(p.message) + "\n" + help
+	}
+
+	return help
+}
+
+// refresh fetches the sessions and the details of the shown one
+func (p SessionsPage) refresh() tea.Cmd {
+	if p.details == "" {
+		return fetchSessions(p.socket)
+	}
+
+	return tea.Batch(fetchSessions(p.socket), fetchSessionDetails(p.socket, p.details))
+}
+
+// nextOrder is the column to sort by after the current one, cycling back to the order of HAProxy
+func (p SessionsPage) nextOrder() string {
+	i := slices.Index(sessionOrders, p.sortedBy)
+	if i+1 >= len(sessionOrders) {
+		return ""
+	}
+
+	return sessionOrders[i+1]
+}
+
+// updateRows sorts the sessions into the table, the cursor stays on the selected session
+func (p *SessionsPage) updateRows() {
+	var selected string
+	if s := p.selected(); s != nil {
+		selected = s.Id
+	}
+
+	p.visible = slices.Clone(p.sessions)
+	slices.SortStableFunc(p.visible, func(a, b haproxy.Session) int {
+		switch p.sortedBy {
+		case "age":
+			return cmp.Compare(b.Age, a.Age)
+		case "frontend":
+			return strings.Compare(a.Frontend, b.Frontend)
+		case "backend":
+			return strings.Compare(a.Backend, b.Backend)
+		case "server":
+			return strings.Compare(a.Backend+"/"+a.Server, b.Backend+"/"+b.Server)
+		case "source":
+			return strings.Compare(a.Source, b.Source)
+		}
+		return 0
+	})
+	p.keys.Sort.SetHelp("s", "sort by "+cmp.Or(p.nextOrder(), "haproxy"))
+	table.WithAdditionalShortHelpKeys([]key.Binding{p.keys.Open, p.keys.Sort, p.keys.Kill, p.keys.KillServer, p.keys.Refresh, p.keys.GotoStatusPage})(&p.table)
+
+	p.table.SetRows(sessionsToRows(p.visible))
+	p.table = recalculateTableSize(p.table)
+	cursor := slices.IndexFunc(p.visible, func(s haproxy.Session) bool { return s.Id == selected })
+	if cursor < 0 {
+		cursor = p.table.Cursor()
+	}
+	// the table leaves the cursor at -1 while it is empty
+	p.table.SetCursor(min(max(cursor, 0), len(p.visible)-1))
+}
+
+// selected is the session of the selected row
+func (p SessionsPage) selected() *haproxy.Session {
+	cursor := p.table.Cursor()
+	if cursor < 0 || cursor >= len(p.visible) {
+		return nil
+	}
+
+	return &p.visible[cursor]
+}
+
+func (p SessionsPage) session(id string) *haproxy.Session {
+	for i := range p.sessions {
+		if p.sessions[i].Id == id {
+			return &p.sessions[i]
+		}
+	}
+
+	return nil
+}
+
+func ActivateSessionsPageCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ActivateSessionsPage(true)
+	}
+}
+
+func createSessionsKeyMap() sessionsPageKeyMap {
+	km := sessionsPageKeyMap{
+		listKeyMap: createListKeyMap("sessions", "details"),
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort"),
+		),
+		Kill: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "kill"),
+		),
+		KillServer: key.NewBinding(
+			key.WithKeys("X"),
+			key.WithHelp("X", "kill all on server"),
+		),
+	}
+	// the details close with esc as well
+	km.Close.SetKeys("backspace", "esc")
+
+	return km
+}
+
+// formatAge rounds the age of a session to seconds, like `1m12s` or `27h03m`
+func formatAge(age time.Duration) string {
+	age = age.Round(time.Second)
+	switch {
+	case age >= time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(age.Hours()), int(age.Minutes())%60)
+	case age >= time.Minute:
+		return fmt.Sprintf("%dm%02ds", int(age.Minutes()), int(age.Seconds())%60)
+	}
+
+	return age.String()
+}
+
+func sessionsToRows(sessions []haproxy.Session) []table.Row {
+	rows := make([]table.Row, len(sessions))
+	for i, s := range sessions {
+		rows[i] = table.Row{s.Id, s.Frontend, s.Backend, s.Server, s.Source, formatAge(s.Age), s.State, s.RequestFlags + "/" + s.ResponseFlags}
+	}
+
+	return rows
+}
+
+func fetchSessions(s *socket.Client) tea.Cmd {
+	return socket.ExecCmd[sessionList](
+		context.Background(),
+		s,
+		"show sess",
+		func(out *string) (sessionList, error) { return haproxy.ParseSessions(*out) },
+	)
+}
+
+func fetchSessionDetails(s *socket.Client, id string) tea.Cmd {
+	return socket.ExecCmd[sessionDetails](
+		context.Background(),
+		s,
+		"show sess "+id,
+		func(out *string) (sessionDetails, error) { return sessionDetails{id: id, dump: *out}, nil },
+	)
+}
@@ -0,0 +1,161 @@
+package components
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSessionsPage(t *testing.T) {
+	t.Parallel()
+
+	// fakeModel lists the three sessions of the fake, from 10.0.0.1 to default/apache and from 10.0.0.2 to default/haproxy
+	fakeModel := func(t *testing.T) SessionsPage {
+		m, cmd := NewSessionsPage(fakeClient(t)).Update(ActivateSessionsPage(true))
+		m, _ = m.Update(cmd())
+
+		return m
+	}
+
+	// apply runs the kill and the refresh following it
+	t.Run("Update Activate", func(t *testing.T) {
+		m := NewSessionsPage(nil)
+		assert.Nil(t, m.Init())
+
+		m, _ = m.Update(sessionList{{Id: "0x55d7e1c00020", Frontend: "http-in", Backend: "default", Server: "apache", Source: "10.0.0.1:51234", Age: 72 * time.Second}})
+
+		res := m.View()
+		assert.Contains(t, res, "1 sessions")
+		assert.Contains(t, res, "10.0.0.1:51234")
+		assert.Contains(t, res, "1m12s")
+	})
+
+	t.Run("Update Sort", func(t *testing.T) {
+		m, _ := NewSessionsPage(nil).Update(sessionList{
+			{Id: "0x1", Frontend: "https-in", Backend: "default", Source: "10.0.0.2:1", Age: time.Second},
+			{Id: "0x2", Frontend: "http-in", Backend: "other", Source: "10.0.0.1:1", Age: time.Minute},
+		})
+		assert.Contains(t, m.View(), "s sort by age")
+
+		m, _ = m.Update(keyMsg('s'))
+		assert.Equal(t, "0x2", m.visible[0].Id)
+		// the cursor stays on the selected session
+		assert.Equal(t, "0x1", m.selected().Id)
+		assert.Contains(t, m.View(), "2 sessions, by age")
+
+		m, _ = m.Update(keyMsg('s'))
+		m, _ = m.Update(keyMsg('s'))
+		assert.Equal(t, "backend", m.sortedBy)
+		assert.Equal(t, "0x1", m.visible[0].Id)
+
+		for range 3 {
+			m, _ = m.Update(keyMsg('s'))
+		}
+		assert.Empty(t, m.sortedBy)
+		assert.Equal(t, "0x1", m.visible[0].Id)
+	})
+
+	t.Run("Update Details", func(t *testing.T) {
+		m := fakeModel(t)
+		assert.Len(t, m.sessions, 3)
+
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, _ = m.Update(cmd())
+
+		res := m.View()
+		assert.Contains(t, res, m.sessions[0].Id+"  10.0.0.1:51234 → default/apache")
+		assert.Contains(t, res, "server=apache (id=2)")
+
+		// details of a previously shown session are ignored
+		m, _ = m.Update(sessionDetails{id: "0x1", dump: "other"})
+		assert.Contains(t, m.View(), "server=apache (id=2)")
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		assert.Empty(t, m.details)
+	})
+
+	t.Run("Update Kill", func(t *testing.T) {
+		m := fakeModel(t)
+		id := m.sessions[0].Id
+
+		// k moves the cursor like in every table
+		m, _ = m.Update(keyMsg('k'))
+		assert.False(t, m.confirmation.Asking())
+
+		m, cmd := m.Update(keyMsg('x'))
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.View(), "kill session "+id+" from 10.0.0.1:51234?")
+
+		m, cmd = m.Update(keyMsg('y'))
+		m = apply(m, cmd)
+
+		assert.Contains(t, m.View(), "killed session "+id)
+		assert.Len(t, m.sessions, 2)
+		assert.NotEqual(t, id, m.sessions[0].Id)
+	})
+
+	t.Run("Update Kill From Details", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, _ = m.Update(cmd())
+		m, _ = m.Update(keyMsg('X'))
+		assert.Contains(t, m.View(), "kill the 2 sessions on default/apache?")
+
+		m, cmd = m.Update(keyMsg('y'))
+		m = apply(m, cmd)
+
+		assert.Empty(t, m.details)
+		assert.Contains(t, m.View(), "killed the sessions on default/apache")
+		assert.Len(t, m.sessions, 1)
+		assert.Equal(t, "haproxy", m.sessions[0].Server)
+	})
+
+	t.Run("Update Kill Without Server", func(t *testing.T) {
+		m, _ := NewSessionsPage(nil).Update(sessionList{{Id: "0x1", Frontend: "GLOBAL", Backend: "<NONE>", Server: "<none>"}})
+
+		m, cmd := m.Update(keyMsg('X'))
+
+		assert.Nil(t, cmd)
+		assert.False(t, m.confirmation.Asking())
+		assert.Contains(t, m.View(), "session 0x1 has no server")
+	})
+
+	t.Run("Update Cancel", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('x'))
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Nil(t, cmd)
+		assert.False(t, m.confirmation.Asking())
+	})
+
+	t.Run("Update Goto Status Page", func(t *testing.T) {
+		_, cmd := NewSessionsPage(nil).Update(tea.KeyMsg{Type: tea.KeyBackspace})
+
+		assert.IsType(t, ActivateStatusPageCmd(), cmd)
+	})
+
+	t.Run("Format Age", func(t *testing.T) {
+		assert.Equal(t, "0s", formatAge(0))
+		assert.Equal(t, "5s", formatAge(5400*time.Millisecond))
+		assert.Equal(t, "1m12s", formatAge(72*time.Second))
+		assert.Equal(t, "27h03m", formatAge(27*time.Hour+3*time.Minute))
+	})
+
+	t.Run("Supports", func(t *testing.T) {
+		m := NewSessionsPage(nil)
+
+		assert.True(t, m.Supports(ActivateSessionsPage(true), false))
+		assert.True(t, m.Supports(sessionList{}, false))
+		assert.True(t, m.Supports(sessionDetails{}, false))
+		assert.True(t, m.Supports(SessionKilled(""), false))
+		assert.True(t, m.Supports(WorkerSelected{}, false))
+		assert.True(t, m.Supports(tea.WindowSizeMsg{}, false))
+		assert.True(t, m.Supports(tea.KeyMsg{}, true))
+		assert.False(t, m.Supports(tea.KeyMsg{}, false))
+		assert.False(t, m.Supports(responseSaved(""), false))
+	})
+}
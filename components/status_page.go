@@ -1,68 +1,322 @@
 package components
 
 import (
+	"context"
 	"fmt"
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"haproxy-runtime-cli/haproxy"
 	"haproxy-runtime-cli/socket"
 	"haproxy-runtime-cli/styles"
 	"net"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// DefaultRefreshInterval is used when the auto refresh is switched on without a configured interval
+const DefaultRefreshInterval = 5 * time.Second
+
 var tblStyle = lipgloss.NewStyle().
 	BorderStyle(lipgloss.NormalBorder()).
 	BorderForeground(lipgloss.Color("240"))
 
 type StatusPage struct {
-	socket   func() net.Conn
+	socket   *socket.Client
 	keys     statusPageKeyMap
+	help     help.Model
 	backends []haproxy.Backend
-	table    table.Model
+	// visible are the backends and servers in the table, after filtering and sorting
+	visible []haproxy.Backend
+	table   table.Model
+	// filter takes a fuzzy search over the backends and servers
+	filter   textinput.Model
+	downOnly bool
+	sortedBy serverOrder
+	// input takes the new weight or address of a server
+	input textinput.Model
+	// editing is the setting of the server the input is for, empty if not editing
+	editing      string
+	confirmation Confirmation
+	message      string
+
+	// interval of the auto refresh, which is running if autoRefresh is set
+	interval    time.Duration
+	autoRefresh bool
+	// ticks counts the started auto refreshes, so the ticks of a stopped one are ignored
+	ticks int
+	// changed holds the servers (backend/server) whose state changed with the last refresh
+	changed map[string]bool
+	// collapsed backends only show their header row
+	collapsed map[string]bool
+	// details of the selected server, shown instead of the table while open
+	details ServerDetails
 }
 
 type statusPageKeyMap struct {
-	GotoCommands key.Binding
-	Reload       key.Binding
-	Quit         key.Binding
+	GotoCommands  key.Binding
+	GotoProcesses key.Binding
+	GotoFrontends key.Binding
+	GotoConsole   key.Binding
+	GotoMaps      key.Binding
+	GotoAcls      key.Binding
+	GotoTables    key.Binding
+	GotoSessions  key.Binding
+	GotoCerts     key.Binding
+	Reload        key.Binding
+	AutoRefresh   key.Binding
+	Filter        key.Binding
+	DownOnly      key.Binding
+	Sort          key.Binding
+	Collapse      key.Binding
+	FoldHealthy   key.Binding
+	ExpandAll     key.Binding
+	Quit          key.Binding
+
+	// actions on the selected server
+	Ready   key.Binding
+	Drain   key.Binding
+	Maint   key.Binding
+	Weight  key.Binding
+	Health  key.Binding
+	Agent   key.Binding
+	Address key.Binding
+	Details key.Binding
+	Apply   key.Binding
+	Cancel  key.Binding
 }
 
 type ActivateStatusPage bool
 
-func NewStatusPage(socket func() net.Conn) StatusPage {
+// ServerChanged is the response of a command changing a server
+type ServerChanged string
+
+// serverOrder is a column the servers of each backend are sorted by
+type serverOrder int
+
+const (
+	unsorted serverOrder = iota
+	byState
+	byWeight
+	byLastChange
+)
+
+func (o serverOrder) String() string {
+	return [...]string{"none", "state", "weight", "last change"}[o]
+}
+
+// next is the order to switch to, cycling back to unsorted
+func (o serverOrder) next() serverOrder {
+	return (o + 1) % (byLastChange + 1)
+}
+
+// refreshTick triggers the auto refresh with the given number
+type refreshTick int
+
+func NewStatusPage(socket *socket.Client) StatusPage {
 	km := createStatusKeyMap()
+	input := textinput.New()
+	input.PromptStyle = styles.ComplementStyle
+	input.CharLimit = 64
+
+	filter := textinput.New()
+	filter.Prompt = "filter: "
+	filter.PromptStyle = styles.ComplementStyle
+
 	return StatusPage{
-		socket: socket,
-		keys:   km,
-		table:  createTable(km),
+		socket:       socket,
+		keys:         km,
+		help:         help.New(),
+		confirmation: NewConfirmation(),
+		filter:       filter,
+		input:        input,
+		table:        createTable(km),
+		interval:     DefaultRefreshInterval,
+		collapsed:    map[string]bool{},
+		details:      NewServerDetails(socket),
 	}
 }
 
+// SetRefreshInterval configures the auto refresh, which is switched off with 0
+func (s *StatusPage) SetRefreshInterval(interval time.Duration) {
+	s.autoRefresh = interval > 0
+	if s.autoRefresh {
+		s.interval = interval
+	}
+	s.ticks++
+	s.updateRefreshHelp()
+}
+
 func (s StatusPage) Init() tea.Cmd {
+	if s.autoRefresh {
+		return tea.Batch(fetchBackends(s.socket), s.tick())
+	}
+
 	return fetchBackends(s.socket)
 }
 
 func (s StatusPage) Update(msg tea.Msg) (StatusPage, tea.Cmd) {
 	switch msg := msg.(type) {
 	case []haproxy.Backend:
+		s.changed = changedServers(s.backends, msg)
 		s.backends = msg
-		s.table.SetRows(backendsToRows(s.backends))
-		s.table = recalculateTableSize(s.table)
+		s.updateRows(s.selectedRow())
+		var cmd tea.Cmd
+		s.details, cmd = s.details.Update(msg)
+		return s, cmd
+	case serverStat:
+		s.details, _ = s.details.Update(msg)
+		return s, nil
+	case MasterDetected:
+		s.keys.GotoProcesses.SetEnabled(true)
+		// the table keeps copies of the help keys, so the enabled key must be handed over again
+		table.WithAdditionalShortHelpKeys(statusHelpKeys(s.keys))(&s.table)
+		return s, nil
+	case ServerChanged:
+		s.message = string(msg)
+		return s, fetchBackends(s.socket)
+	case WorkerSelected:
+		return s, fetchBackends(s.socket)
+	case refreshTick:
+		if !s.autoRefresh || int(msg) != s.ticks {
+			return s, nil
+		}
+		return s, tea.Batch(fetchBackends(s.socket), s.tick())
 	case tea.WindowSizeMsg:
 		s.table.UpdateViewport()
 		s.table.SetWidth(msg.Width - styles.PageStyle.GetHorizontalMargins())
-		s.table.SetHeight(msg.Height - styles.PageStyle.GetVerticalMargins() - 3 - 3)
+		s.table.SetHeight(msg.Height - styles.PageStyle.GetVerticalMargins() - 3 - 3 - 1)
 	case tea.KeyMsg:
+		if s.details.IsOpen() {
+			var cmd tea.Cmd
+			s.details, cmd = s.details.Update(msg)
+			return s, cmd
+		}
+		if s.editing != "" {
+			return s.updateInput(msg)
+		}
+		if s.confirmation.Asking() {
+			var cmd tea.Cmd
+			s.confirmation, cmd = s.confirmation.Update(msg)
+			return s, cmd
+		}
+		if s.filter.Focused() {
+			return s.updateFilter(msg)
+		}
+
+		backend, server := s.selectedServer()
 		switch {
 		case key.Matches(msg, s.keys.Quit):
 			return s, tea.Quit
 		case key.Matches(msg, s.keys.GotoCommands):
 			return s, ActivateCommandsPageCmd()
+		case key.Matches(msg, s.keys.GotoProcesses):
+			return s, ActivateProcessesPageCmd()
+		case key.Matches(msg, s.keys.GotoFrontends):
+			return s, ActivateFrontendsPageCmd()
+		case key.Matches(msg, s.keys.GotoConsole):
+			return s, ActivateConsolePageCmd()
+		case key.Matches(msg, s.keys.GotoMaps):
+			return s, ActivateMapsPageCmd()
+		case key.Matches(msg, s.keys.GotoAcls):
+			return s, ActivateAclsPageCmd()
+		case key.Matches(msg, s.keys.GotoTables):
+			return s, ActivateTablesPageCmd()
+		case key.Matches(msg, s.keys.GotoSessions):
+			return s, ActivateSessionsPageCmd()
+		case key.Matches(msg, s.keys.GotoCerts):
+			return s, ActivateCertsPageCmd()
 		case key.Matches(msg, s.keys.Reload):
 			return s, fetchBackends(s.socket)
+		case key.Matches(msg, s.keys.AutoRefresh):
+			s.autoRefresh = !s.autoRefresh
+			s.ticks++
+			s.updateRefreshHelp()
+			if s.autoRefresh {
+				return s, tea.Batch(fetchBackends(s.socket), s.tick())
+			}
+			return s, nil
+		case key.Matches(msg, s.keys.Filter):
+			return s, s.filter.Focus()
+		case key.Matches(msg, s.keys.Cancel) && s.filter.Value() != "":
+			s.filter.SetValue("")
+			s.updateRows(s.selectedRow())
+			return s, nil
+		case key.Matches(msg, s.keys.DownOnly):
+			s.downOnly = !s.downOnly
+			s.updateRows(s.selectedRow())
+			return s, nil
+		case key.Matches(msg, s.keys.Collapse):
+			if backend != "" {
+				selected := s.selectedRow()
+				s.collapsed[backend] = !s.collapsed[backend]
+				s.updateRows(selected)
+			}
+			return s, nil
+		case key.Matches(msg, s.keys.FoldHealthy):
+			// only the backends which need attention stay expanded
+			selected := s.selectedRow()
+			for _, b := range s.backends {
+				s.collapsed[b.Name] = backendHealth(b).healthy()
+			}
+			s.updateRows(selected)
+			return s, nil
+		case key.Matches(msg, s.keys.ExpandAll):
+			selected := s.selectedRow()
+			clear(s.collapsed)
+			s.updateRows(selected)
+			return s, nil
+		case key.Matches(msg, s.keys.Sort):
+			s.sortedBy = s.sortedBy.next()
+			// the help names the order of the next press
+			s.keys.Sort.SetHelp("s", "sort by "+s.sortedBy.next().String())
+			table.WithAdditionalShortHelpKeys(statusHelpKeys(s.keys))(&s.table)
+			s.updateRows(s.selectedRow())
+			return s, nil
+		case server == nil:
+			// the actions need a selected server
+		case key.Matches(msg, s.keys.Ready):
+			command := fmt.Sprintf("set server %s/%s state ready", backend, server.Name)
+			s.confirmation = s.confirmation.Ask(command, changeServer(s.socket, command))
+			return s, nil
+		case key.Matches(msg, s.keys.Drain):
+			command := fmt.Sprintf("set server %s/%s state drain", backend, server.Name)
+			s.confirmation = s.confirmation.Ask(command, changeServer(s.socket, command))
+			return s, nil
+		case key.Matches(msg, s.keys.Maint):
+			command := fmt.Sprintf("set server %s/%s state maint", backend, server.Name)
+			s.confirmation = s.confirmation.Ask(command, changeServer(s.socket, command))
+			return s, nil
+		case key.Matches(msg, s.keys.Health):
+			command := fmt.Sprintf("%s health %s/%s", toggle(server.CheckState), backend, server.Name)
+			s.confirmation = s.confirmation.Ask(command, changeServer(s.socket, command))
+			return s, nil
+		case key.Matches(msg, s.keys.Agent):
+			command := fmt.Sprintf("%s agent %s/%s", toggle(server.SrvAgentState), backend, server.Name)
+			s.confirmation = s.confirmation.Ask(command, changeServer(s.socket, command))
+			return s, nil
+		case key.Matches(msg, s.keys.Weight):
+			return s.edit("weight", strconv.Itoa(server.UserWeight))
+		case key.Matches(msg, s.keys.Address):
+			addr := ""
+			if server.Address != nil {
+				addr = net.JoinHostPort(server.Address.String(), strconv.Itoa(server.Port))
+			}
+			return s.edit("addr", addr)
+		case key.Matches(msg, s.keys.Details):
+			for _, b := range s.visible {
+				if b.Name == backend {
+					var cmd tea.Cmd
+					s.details, cmd = s.details.Open(b, *server)
+					return s, cmd
+				}
+			}
 		}
 	}
 
@@ -70,12 +324,16 @@ func (s StatusPage) Update(msg tea.Msg) (StatusPage, tea.Cmd) {
 }
 
 func (s StatusPage) View() string {
-	return tblStyle.Render(s.table.View()) + "\n" + s.table.HelpView()
+	if s.details.IsOpen() {
+		return s.details.View()
+	}
+
+	return tblStyle.Render(s.table.View()) + "\n" + s.footer()
 }
 
 func (s StatusPage) Supports(msg tea.Msg, isActive bool) bool {
 	switch msg.(type) {
-	case []haproxy.Backend, tea.WindowSizeMsg:
+	case []haproxy.Backend, serverStat, ServerChanged, refreshTick, MasterDetected, WorkerSelected, tea.WindowSizeMsg:
 		return true
 	case tea.KeyMsg:
 		if isActive {
@@ -86,6 +344,199 @@ func (s StatusPage) Supports(msg tea.Msg, isActive bool) bool {
 	return false
 }
 
+func (s StatusPage) footer() string {
+	switch {
+	case s.editing != "":
+		return s.input.View() + "\n" + s.help.ShortHelpView([]key.Binding{s.keys.Apply, s.keys.Cancel})
+	case s.filter.Focused():
+		return s.filter.View() + "\n" + s.help.ShortHelpView([]key.Binding{s.keys.Apply, s.keys.Cancel})
+	case s.confirmation.Asking():
+		return s.confirmation.View()
+	}
+
+	var status []string
+	if s.message != "" {
+		status = append(status, s.message)
+	}
+	if s.filter.Value() != "" {
+		status = append(status, "filter: "+s.filter.Value())
+	}
+	if s.downOnly {
+		status = append(status, "only down/maint servers")
+	}
+	if s.sortedBy != unsorted {
+		status = append(status, "sorted by "+s.sortedBy.String())
+	}
+	if len(status) == 0 {
+		return s.table.HelpView()
+	}
+
+	return styles.ComplementStyle.Render(strings.Join(status, " · ")) + "\n" + s.table.HelpView()
+}
+
+// updateRows filters and sorts the backends into the table, the cursor stays on the
+// selected row (see selectedRow) even if rows were added or removed above
+func (s *StatusPage) updateRows(selected string) {
+	s.visible = filterBackends(s.backends, s.filter.Value(), s.downOnly)
+	sortServers(s.visible, s.sortedBy)
+
+	s.table.SetRows(backendsToRows(s.visible, s.changed, s.collapsed))
+	s.table = recalculateTableSize(s.table)
+	s.table.SetCursor(s.rowOf(selected))
+}
+
+// updateFilter filters the table while typing, enter keeps the filter and esc drops it
+func (s StatusPage) updateFilter(msg tea.KeyMsg) (StatusPage, tea.Cmd) {
+	switch {
+	case key.Matches(msg, s.keys.Apply):
+		s.filter.Blur()
+		return s, nil
+	case key.Matches(msg, s.keys.Cancel):
+		s.filter.Blur()
+		s.filter.SetValue("")
+		s.updateRows(s.selectedRow())
+		return s, nil
+	}
+
+	var cmd tea.Cmd
+	s.filter, cmd = s.filter.Update(msg)
+	s.updateRows(s.selectedRow())
+
+	return s, cmd
+}
+
+// edit asks for a new value of the given setting of the selected server
+func (s StatusPage) edit(setting string, value string) (StatusPage, tea.Cmd) {
+	s.editing = setting
+	s.input.Prompt = setting + ": "
+	s.input.SetValue(value)
+	s.input.CursorEnd()
+
+	return s, s.input.Focus()
+}
+
+func (s StatusPage) updateInput(msg tea.KeyMsg) (StatusPage, tea.Cmd) {
+	switch {
+	case key.Matches(msg, s.keys.Apply):
+		backend, server := s.selectedServer()
+		value := s.input.Value()
+		if server == nil || value == "" {
+			return s, nil
+		}
+
+		setting := s.editing
+		s.editing = ""
+		s.input.Blur()
+
+		return s, changeServer(s.socket, fmt.Sprintf("set server %s/%s %s", backend, server.Name, setValue(setting, value)))
+	case key.Matches(msg, s.keys.Cancel):
+		s.editing = ""
+		s.input.Blur()
+		return s, nil
+	}
+
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+
+	return s, cmd
+}
+
+func (s StatusPage) tick() tea.Cmd {
+	id := s.ticks
+	return tea.Tick(s.interval, func(time.Time) tea.Msg {
+		return refreshTick(id)
+	})
+}
+
+// updateRefreshHelp shows the state of the auto refresh in the help
+func (s *StatusPage) updateRefreshHelp() {
+	if s.autoRefresh {
+		s.keys.AutoRefresh.SetHelp("t", "auto refresh "+s.interval.String())
+	} else {
+		s.keys.AutoRefresh.SetHelp("t", "auto refresh off")
+	}
+	table.WithAdditionalShortHelpKeys(statusHelpKeys(s.keys))(&s.table)
+}
+
+// selectedRow returns the backend or server (backend/server) of the selected row
+func (s StatusPage) selectedRow() string {
+	backend, server := s.selectedServer()
+	if server == nil {
+		return backend
+	}
+
+	return backend + "/" + server.Name
+}
+
+// rowOf returns the row of a backend or server (backend/server). The row of its backend is
+// returned for a server of a collapsed backend, the current row if the backend is gone.
+func (s StatusPage) rowOf(name string) int {
+	backend, _, _ := strings.Cut(name, "/")
+
+	row := 0
+	for _, b := range s.visible {
+		if b.Name == name || (b.Name == backend && s.collapsed[b.Name]) {
+			return row
+		}
+		row++
+		if s.collapsed[b.Name] {
+			continue
+		}
+
+		for _, srv := range b.Servers {
+			if b.Name+"/"+srv.Name == name {
+				return row
+			}
+			row++
+		}
+	}
+
+	return s.table.Cursor()
+}
+
+// selectedServer returns the backend and server of the selected row, the server is nil on backend rows
+func (s StatusPage) selectedServer() (string, *haproxy.Server) {
+	row := 0
+	for _, b := range s.visible {
+		if row == s.table.Cursor() {
+			return b.Name, nil
+		}
+		row++
+		if s.collapsed[b.Name] {
+			continue
+		}
+
+		for i := range b.Servers {
+			if row == s.table.Cursor() {
+				return b.Name, &b.Servers[i]
+			}
+			row++
+		}
+	}
+
+	return "", nil
+}
+
+// toggle returns the command to flip a check of the given state
+func toggle(state string) string {
+	if state == haproxy.ENABLED {
+		return "disable"
+	}
+
+	return "enable"
+}
+
+// setValue builds the arguments of `set server`, an address may carry a port like 10.0.0.1:8080 or [::1]:8080
+func setValue(setting string, value string) string {
+	if setting == "addr" {
+		if host, port, err := net.SplitHostPort(value); err == nil {
+			return fmt.Sprintf("addr %s port %s", host, port)
+		}
+	}
+
+	return setting + " " + value
+}
+
 func ActivateStatusPageCmd() tea.Cmd {
 	return func() tea.Msg {
 		return ActivateStatusPage(true)
@@ -98,14 +549,117 @@ func createStatusKeyMap() statusPageKeyMap {
 			key.WithKeys("backspace"),
 			key.WithHelp("backspace", "command list"),
 		),
+		// only enabled when connected to a master cli
+		GotoProcesses: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "processes"),
+			key.WithDisabled(),
+		),
+		GotoFrontends: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "frontends"),
+		),
+		GotoConsole: key.NewBinding(
+			key.WithKeys(":"),
+			key.WithHelp(":", "console"),
+		),
+		GotoMaps: key.NewBinding(
+			key.WithKeys("M"),
+			key.WithHelp("M", "maps"),
+		),
+		GotoAcls: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "acls"),
+		),
+		GotoTables: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "stick tables"),
+		),
+		GotoSessions: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "sessions"),
+		),
+		GotoCerts: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "certificates"),
+		),
 		Reload: key.NewBinding(
 			key.WithKeys("r"),
 			key.WithHelp("r", "reload"),
 		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter"),
+		),
+		DownOnly: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "only down/maint"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort by state"),
+		),
+		Details: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "details"),
+		),
+		Collapse: key.NewBinding(
+			key.WithKeys("enter", " "),
+			key.WithHelp("enter", "collapse/expand"),
+		),
+		FoldHealthy: key.NewBinding(
+			key.WithKeys("z"),
+			key.WithHelp("z", "collapse healthy"),
+		),
+		ExpandAll: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "expand all"),
+		),
+		AutoRefresh: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "auto refresh off"),
+		),
 		Quit: key.NewBinding(
 			key.WithKeys("q"),
 			key.WithHelp("q", "quit"),
 		),
+		// u and d scroll the table by half a page
+		Ready: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "ready"),
+		),
+		Drain: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "drain"),
+		),
+		Maint: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "maint"),
+		),
+		Weight: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "weight"),
+		),
+		Health: key.NewBinding(
+			key.WithKeys("h"),
+			key.WithHelp("h", "toggle health check"),
+		),
+		Agent: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "toggle agent check"),
+		),
+		Address: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "change address"),
+		),
+		Apply: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "apply"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+		),
 	}
 }
 
@@ -141,6 +695,32 @@ func recalculateTableSize(model table.Model) table.Model {
 }
 
 func createTable(km statusPageKeyMap) table.Model {
+	//TODO responsive table, calculate max width based on values
+	return newTable([]table.Column{
+		{Title: "", Width: 1},
+		{Title: "Backend", Width: 25},
+		{Title: "Name", Width: 25},
+		{Title: "Weight", Width: 6},
+		{Title: "State", Width: 8},
+		{Title: "IP", Width: 16},
+		{Title: "CHECK", Width: 8},
+		{Title: "", Width: 8},
+		{Title: "FQDN", Width: 30},
+		{Title: "SSL", Width: 5},
+		// the colored health is the last column, as the table counts the color codes into the width
+		{Title: "Health", Width: 16},
+	}, statusHelpKeys(km))
+}
+
+func statusHelpKeys(km statusPageKeyMap) []key.Binding {
+	return []key.Binding{
+		km.Ready, km.Drain, km.Maint, km.Weight, km.Health, km.Agent, km.Address, km.Details,
+		km.Collapse, km.FoldHealthy, km.ExpandAll, km.Filter, km.DownOnly, km.Sort,
+		km.GotoCommands, km.GotoProcesses, km.GotoFrontends, km.GotoConsole, km.GotoMaps, km.GotoAcls, km.GotoTables, km.GotoSessions, km.GotoCerts, km.Reload, km.AutoRefresh, km.Quit,
+	}
+}
+
+func newTable(columns []table.Column, helpKeys []key.Binding) table.Model {
 	s := table.DefaultStyles()
 	//selected := s.Selected
 	s.Selected = styles.ActiveStyle
@@ -151,61 +731,259 @@ func createTable(km statusPageKeyMap) table.Model {
 		BorderBottom(true).
 		Bold(false)
 
-	//TODO responsive table, calculate max width based on values
-	tbl := table.New(
+	return table.New(
 		table.WithHeight(10),
-		table.WithColumns([]table.Column{
-			{Title: "Backend", Width: 25},
-			{Title: "Name", Width: 25},
-			{Title: "Weight", Width: 6},
-			{Title: "State", Width: 8},
-			{Title: "IP", Width: 16},
-			{Title: "CHECK", Width: 8},
-			{Title: "", Width: 8},
-			{Title: "FQDN", Width: 30},
-			{Title: "SSL", Width: 5},
-		}),
+		table.WithColumns(columns),
 		table.WithFocused(true),
 		table.WithStyles(s),
-		table.WithAdditionalShortHelpKeys([]key.Binding{km.GotoCommands, km.Reload, km.Quit}),
+		table.WithAdditionalShortHelpKeys(helpKeys),
 	)
+}
+
+// filterBackends keeps the servers matching the fuzzy search term (on backend, server, address and state)
+// and, with downOnly, which are down or in maintenance. Backends without servers left are dropped,
+// unless the term matches their name.
+func filterBackends(backends []haproxy.Backend, term string, downOnly bool) []haproxy.Backend {
+	var res []haproxy.Backend
+
+	for _, b := range backends {
+		var servers []haproxy.Server
+		for _, s := range b.Servers {
+			if !downOnly || isDown(s) {
+				servers = append(servers, s)
+			}
+		}
+
+		if term != "" {
+			targets := make([]string, len(servers))
+			for i, s := range servers {
+				targets[i] = serverFilterValue(b, s)
+			}
+
+			matched := make([]bool, len(servers))
+			for _, r := range list.DefaultFilter(term, targets) {
+				matched[r.Index] = true
+			}
+
+			// the servers keep their order, not the one of the ranking
+			var kept []haproxy.Server
+			for i, s := range servers {
+				if matched[i] {
+					kept = append(kept, s)
+				}
+			}
+			servers = kept
+		}
+
+		nameMatches := term != "" && !downOnly && len(list.DefaultFilter(term, []string{b.Name})) > 0
+		if len(servers) == 0 && (term != "" || downOnly) && !nameMatches {
+			continue
+		}
+
+		b.Servers = servers
+		res = append(res, b)
+	}
+
+	return res
+}
+
+func serverFilterValue(b haproxy.Backend, s haproxy.Server) string {
+	addr := ""
+	if s.Address != nil {
+		addr = s.Address.String()
+	}
+
+	return strings.Join([]string{b.Name, s.Name, addr, s.State, s.AdminState}, " ")
+}
 
-	return tbl
+// isDown reports whether the server is stopped or in maintenance
+func isDown(s haproxy.Server) bool {
+	return s.State == haproxy.STOPPED || s.AdminState == haproxy.MAINT
 }
 
-func backendsToRows(backends []haproxy.Backend) []table.Row {
+// sortServers sorts the servers within each backend, the backends keep their order
+func sortServers(backends []haproxy.Backend, order serverOrder) {
+	for _, b := range backends {
+		switch order {
+		case byState:
+			// the most severe state first
+			slices.SortStableFunc(b.Servers, func(x, y haproxy.Server) int { return severity(x) - severity(y) })
+		case byWeight:
+			slices.SortStableFunc(b.Servers, func(x, y haproxy.Server) int { return x.UserWeight - y.UserWeight })
+		case byLastChange:
+			// the latest change first
+			slices.SortStableFunc(b.Servers, func(x, y haproxy.Server) int { return y.LastStateChanged.Compare(x.LastStateChanged) })
+		}
+	}
+}
+
+// severity ranks down servers before draining or starting ones, before running ones
+func severity(s haproxy.Server) int {
+	switch {
+	case isDown(s):
+		return 0
+	case s.AdminState == haproxy.DRAIN || s.State != haproxy.RUNNING:
+		return 1
+	}
+
+	return 2
+}
+
+// changedServers returns the servers (backend/server) whose state, weight or check result differs from the previous refresh
+func changedServers(previous []haproxy.Backend, current []haproxy.Backend) map[string]bool {
+	before := map[string]haproxy.Server{}
+	for _, b := range previous {
+		for _, s := range b.Servers {
+			before[b.Name+"/"+s.Name] = s
+		}
+	}
+
+	changed := map[string]bool{}
+	for _, b := range current {
+		for _, s := range b.Servers {
+			p, ok := before[b.Name+"/"+s.Name]
+			if !ok {
+				continue
+			}
+			if p.State != s.State || p.AdminState != s.AdminState || p.UserWeight != s.UserWeight ||
+				p.CalculatedWeight != s.CalculatedWeight || p.SrvCheckResult != s.SrvCheckResult || p.CheckState != s.CheckState {
+				changed[b.Name+"/"+s.Name] = true
+			}
+		}
+	}
+
+	return changed
+}
+
+// health counts the servers of a backend by their state
+type health struct {
+	servers int
+	up      int
+	drain   int
+	maint   int
+	down    int
+	weight  int
+}
+
+func backendHealth(b haproxy.Backend) health {
+	h := health{servers: len(b.Servers)}
+	for _, s := range b.Servers {
+		h.weight += s.UserWeight
+
+		switch {
+		case s.AdminState == haproxy.MAINT:
+			h.maint++
+		case s.State == haproxy.STOPPED:
+			h.down++
+		default:
+			h.up++
+			if s.AdminState == haproxy.DRAIN {
+				h.drain++
+			}
+		}
+	}
+
+	return h
+}
+
+func (h health) healthy() bool {
+	return h.up == h.servers && h.drain == 0
+}
+
+// String summarizes the health like "7/8 UP, 1 MAINT"
+func (h health) String() string {
+	parts := []string{fmt.Sprintf("%d/%d UP", h.up, h.servers)}
+	for _, c := range []struct {
+		count int
+		state string
+	}{{h.drain, haproxy.DRAIN}, {h.maint, haproxy.MAINT}, {h.down, haproxy.DOWN}} {
+		if c.count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.count, c.state))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func (h health) render() string {
+	switch {
+	case h.healthy():
+		return styles.HealthyStyle.Render(h.String())
+	case h.up == 0:
+		return styles.DownStyle.Render(h.String())
+	}
+
+	return styles.DegradedStyle.Render(h.String())
+}
+
+// backendsToRows creates a header row for each backend, with its health and total weight, followed by its servers
+// unless the backend is collapsed. Changed servers are marked with a *.
+func backendsToRows(backends []haproxy.Backend, changed map[string]bool, collapsed map[string]bool) []table.Row {
 	var rows []table.Row
 
 	for _, b := range backends {
+		h := backendHealth(b)
+		fold := "▾ "
+		if collapsed[b.Name] {
+			fold = "▸ "
+		}
+
 		rows = append(rows, table.Row{
-			b.Name,
+			"",
+			fold + b.Name,
+			"",
+			strconv.Itoa(h.weight),
+			"", "", "", "", "", "",
+			h.render(),
 		})
+		if collapsed[b.Name] {
+			continue
+		}
+
 		for _, s := range b.Servers {
 			addr := ""
 			if s.Address != nil {
 				addr = s.Address.String()
 			}
 
+			marker := ""
+			if changed[b.Name+"/"+s.Name] {
+				marker = "*"
+			}
+
 			rows = append(rows, table.Row{
+				marker,
 				"",
 				s.Name,
-				strconv.Itoa(s.CalculatedWeight),
+				strconv.Itoa(s.UserWeight),
 				s.State,
 				addr,
 				s.CheckState,
 				s.SrvCheckResult,
 				fmt.Sprintf(`%s:%d`, s.Fqdn, s.Port),
-				strconv.FormatBool(s.UseSSL)})
+				strconv.FormatBool(s.UseSSL),
+				"",
+			})
 		}
 	}
 
 	return rows
 }
 
-func fetchBackends(s func() net.Conn) tea.Cmd {
+func changeServer(s *socket.Client, command string) tea.Cmd {
+	return socket.ExecCmd[ServerChanged](
+		context.Background(),
+		s,
+		command,
+		func(s *string) (ServerChanged, error) { return ServerChanged(*s), nil },
+	)
+}
+
+func fetchBackends(s *socket.Client) tea.Cmd {
 	return socket.ExecCmd[[]haproxy.Backend](
+		context.Background(),
 		s,
 		"show servers state",
-		func(s *string) []haproxy.Backend { return haproxy.ParseBackends(*s) },
+		func(s *string) ([]haproxy.Backend, error) { return haproxy.ParseBackends(*s) },
 	)
 }
@@ -6,7 +6,9 @@ import (
 	"haproxy-runtime-cli/haproxy"
 	"haproxy-runtime-cli/socket"
 	"net"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestStatusPage(t *testing.T) {
@@ -25,7 +27,7 @@ func TestStatusPage(t *testing.T) {
 			`),
 		}
 
-		return NewStatusPage(func() net.Conn { return conn })
+		return NewStatusPage(socket.NewClient(func() (net.Conn, error) { return conn, nil }))
 	}
 
 	t.Run("New", func(t *testing.T) {
@@ -70,7 +72,7 @@ func TestStatusPage(t *testing.T) {
 		nm, cmd := model().Update(tea.WindowSizeMsg{Width: 100, Height: 100})
 
 		assert.Nil(t, cmd)
-		assert.Equal(t, 92, nm.table.Height())
+		assert.Equal(t, 91, nm.table.Height())
 		assert.Equal(t, 96, nm.table.Width())
 	})
 
@@ -81,6 +83,49 @@ func TestStatusPage(t *testing.T) {
 		assert.IsType(t, ActivateCommandsPageCmd(), cmd)
 	})
 
+	t.Run("Update Goto Console Page", func(t *testing.T) {
+		_, cmd := socketModel().Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{':'}})
+
+		assert.Equal(t, ActivateConsolePage(true), cmd())
+	})
+
+	t.Run("Update Goto Maps Page", func(t *testing.T) {
+		_, cmd := socketModel().Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'M'}})
+
+		assert.Equal(t, ActivateMapsPage(true), cmd())
+	})
+
+	t.Run("Update Goto Acls Page", func(t *testing.T) {
+		_, cmd := socketModel().Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'A'}})
+
+		assert.Equal(t, ActivateAclsPage(true), cmd())
+	})
+
+	t.Run("Update Goto Tables Page", func(t *testing.T) {
+		_, cmd := socketModel().Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}})
+
+		assert.Equal(t, ActivateTablesPage(true), cmd())
+	})
+
+	t.Run("Update Goto Sessions Page", func(t *testing.T) {
+		_, cmd := socketModel().Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'S'}})
+
+		assert.Equal(t, ActivateSessionsPage(true), cmd())
+	})
+
+	t.Run("Update Goto Certs Page", func(t *testing.T) {
+		_, cmd := socketModel().Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'C'}})
+
+		assert.Equal(t, ActivateCertsPage(true), cmd())
+	})
+
+	t.Run("Update Goto Frontends Page", func(t *testing.T) {
+		_, cmd := socketModel().Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+
+		assert.NotNil(t, cmd)
+		assert.IsType(t, ActivateFrontendsPageCmd(), cmd)
+	})
+
 	t.Run("Update Reload", func(t *testing.T) {
 		_, cmd := socketModel().Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
 
@@ -108,10 +153,423 @@ func TestStatusPage(t *testing.T) {
 		assert.Contains(t, res, "foo.com:443")
 	})
 
+	t.Run("Fetch from fake HAProxy", func(t *testing.T) {
+		m := NewStatusPage(fakeClient(t))
+		m, _ = m.Update(m.Init()())
+
+		assert.Len(t, m.backends, 2)
+		assert.Contains(t, m.View(), "apache.org:443")
+	})
+
+	t.Run("Fetch with errors", func(t *testing.T) {
+		conn := &socket.DummySocket{Output: []byte("1\n4 default 1 haproxy 209.126.35.1 2 0 20 20 9 9 3 4 6 0 0 0 haproxy.com 443 - 1 0 - - 0\n4 default 2 broken")}
+		res := NewStatusPage(socket.NewClient(func() (net.Conn, error) { return conn, nil })).Init()()
+
+		// the parsed servers are shown next to the parse error
+		msgs := res.(tea.BatchMsg)
+		assert.Len(t, msgs[0]().([]haproxy.Backend), 1)
+		assert.ErrorAs(t, msgs[1]().(error), &haproxy.ParseError{})
+
+		conn = &socket.DummySocket{Output: []byte("Permission denied")}
+		res = NewStatusPage(socket.NewClient(func() (net.Conn, error) { return conn, nil })).Init()()
+		assert.ErrorIs(t, res.(error), haproxy.ErrPermissionDenied)
+	})
+
+	fakeModel := func(t *testing.T) StatusPage {
+		m := NewStatusPage(fakeClient(t))
+		m, _ = m.Update(m.Init()())
+		// select default/haproxy
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+		return m
+	}
+
+	// apply runs the command changing a server and the refresh of the backends afterwards
+	t.Run("Update Server State", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'D'}})
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.View(), "set server default/haproxy state drain?")
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		m = apply(m, cmd)
+		assert.Equal(t, haproxy.DRAIN, m.backends[0].Servers[0].AdminState)
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = apply(m, cmd)
+		assert.Equal(t, haproxy.MAINT, m.backends[0].Servers[0].AdminState)
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		m = apply(m, cmd)
+		assert.Equal(t, "0", m.backends[0].Servers[0].AdminState)
+	})
+
+	t.Run("Update Server State Cancel", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+
+		assert.Nil(t, cmd)
+		assert.False(t, m.confirmation.Asking())
+		assert.NotContains(t, m.View(), "state maint?")
+	})
+
+	t.Run("Update Half Page Keys", func(t *testing.T) {
+		m := fakeModel(t)
+
+		// u and d scroll the table, they don't change the selected server
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+		assert.Nil(t, cmd)
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+		assert.Nil(t, cmd)
+		assert.False(t, m.confirmation.Asking())
+	})
+
+	t.Run("Update Toggle Checks", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}})
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.View(), "disable health")
+
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		m = apply(m, cmd)
+		assert.Equal(t, haproxy.DISABLED, m.backends[0].Servers[0].CheckState)
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}})
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		m = apply(m, cmd)
+		assert.Equal(t, haproxy.ENABLED, m.backends[0].Servers[0].CheckState)
+
+		// no agent is configured
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+		_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		assert.Error(t, cmd().(error))
+	})
+
+	t.Run("Update Weight", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+		assert.Contains(t, m.View(), "weight: 20")
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("50")})
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = apply(m, cmd)
+
+		assert.Equal(t, 50, m.backends[0].Servers[0].UserWeight)
+		// the weight column shows the weight set at runtime, not the initial one
+		assert.Equal(t, "50", m.table.Rows()[1][3])
+	})
+
+	t.Run("Update Address", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+		assert.Contains(t, m.View(), "addr: 209.126.35.1:443")
+
+		m.input.SetValue("10.0.0.1:8080")
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = apply(m, cmd)
+
+		assert.Equal(t, "10.0.0.1", m.backends[0].Servers[0].Address.String())
+		assert.Equal(t, 8080, m.backends[0].Servers[0].Port)
+		assert.Contains(t, m.View(), "IP changed from '209.126.35.1' to '10.0.0.1'")
+	})
+
+	t.Run("Update Edit Cancel", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Nil(t, cmd)
+		assert.NotContains(t, m.View(), "weight:")
+	})
+
+	t.Run("Update Action on Backend", func(t *testing.T) {
+		m, _ := socketModel().Update([]haproxy.Backend{
+			{Name: "foo", Id: 1, Servers: []haproxy.Server{{Name: "foo", Id: 1}}},
+		})
+
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+		assert.Nil(t, cmd)
+	})
+
+	t.Run("Update Auto Refresh", func(t *testing.T) {
+		m := socketModel()
+		assert.Contains(t, m.View(), "auto refresh off")
+
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+		assert.IsType(t, tea.BatchMsg{}, cmd())
+		assert.Contains(t, m.View(), "auto refresh 5s")
+
+		// a tick fetches the backends and schedules the next one
+		_, cmd = m.Update(refreshTick(m.ticks))
+		assert.Len(t, cmd().(tea.BatchMsg), 2)
+
+		// ticks of a switched off auto refresh are dropped
+		stale := m.ticks
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.View(), "auto refresh off")
+
+		_, cmd = m.Update(refreshTick(stale))
+		assert.Nil(t, cmd)
+	})
+
+	t.Run("Set Refresh Interval", func(t *testing.T) {
+		m := socketModel()
+		m.SetRefreshInterval(time.Millisecond)
+
+		assert.Contains(t, m.View(), "auto refresh 1ms")
+		assert.Equal(t, refreshTick(m.ticks), m.tick()())
+		assert.IsType(t, tea.BatchMsg{}, m.Init()())
+
+		m.SetRefreshInterval(0)
+		assert.Contains(t, m.View(), "auto refresh off")
+		assert.IsType(t, []haproxy.Backend{}, m.Init()())
+	})
+
+	t.Run("Update Highlights Changes", func(t *testing.T) {
+		backends := func(state string) []haproxy.Backend {
+			return []haproxy.Backend{{Name: "foo", Servers: []haproxy.Server{{Name: "a", State: haproxy.RUNNING}, {Name: "b", State: state}}}}
+		}
+
+		m, _ := socketModel().Update(backends(haproxy.RUNNING))
+		assert.Empty(t, m.changed)
+
+		m, _ = m.Update(backends(haproxy.STOPPED))
+		assert.Equal(t, map[string]bool{"foo/b": true}, m.changed)
+		assert.Equal(t, "*", m.table.Rows()[2][0])
+		assert.Equal(t, "", m.table.Rows()[1][0])
+
+		m, _ = m.Update(backends(haproxy.STOPPED))
+		assert.Empty(t, m.changed)
+	})
+
+	t.Run("Update Keeps Cursor", func(t *testing.T) {
+		m, _ := socketModel().Update([]haproxy.Backend{
+			{Name: "foo", Servers: []haproxy.Server{{Name: "a"}, {Name: "b"}}},
+		})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		assert.Equal(t, "foo/b", m.selectedRow())
+
+		m, _ = m.Update([]haproxy.Backend{
+			{Name: "bar", Servers: []haproxy.Server{{Name: "c"}}},
+			{Name: "foo", Servers: []haproxy.Server{{Name: "a"}, {Name: "b"}}},
+		})
+		assert.Equal(t, "foo/b", m.selectedRow())
+		assert.Equal(t, 4, m.table.Cursor())
+	})
+
+	filterModel := func() StatusPage {
+		m, _ := socketModel().Update([]haproxy.Backend{
+			{Name: "web", Servers: []haproxy.Server{
+				{Name: "web1", State: haproxy.RUNNING, UserWeight: 20, Address: net.ParseIP("10.0.0.1"), LastStateChanged: time.Unix(100, 0)},
+				{Name: "web2", State: haproxy.STOPPED, UserWeight: 10, Address: net.ParseIP("10.0.0.2"), LastStateChanged: time.Unix(300, 0)},
+				{Name: "web3", State: haproxy.RUNNING, AdminState: haproxy.DRAIN, UserWeight: 0, Address: net.ParseIP("10.0.0.3"), LastStateChanged: time.Unix(200, 0)},
+			}},
+			{Name: "api", Servers: []haproxy.Server{
+				{Name: "api1", State: haproxy.RUNNING, AdminState: haproxy.MAINT, Address: net.ParseIP("10.0.1.1")},
+			}},
+		})
+
+		return m
+	}
+
+	names := func(m StatusPage) []string {
+		var res []string
+		for _, r := range m.table.Rows() {
+			// the backend or the server name
+			res = append(res, strings.TrimLeft(r[1]+r[2], "▾▸ "))
+		}
+
+		return res
+	}
+
+	t.Run("Update Filter", func(t *testing.T) {
+		m, cmd := filterModel().Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+		assert.NotNil(t, cmd)
+		assert.Contains(t, m.View(), "filter:")
+
+		// fuzzy over backend, server, address and state
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("10.0.1.1")})
+		assert.Equal(t, []string{"api", "api1"}, names(m))
+
+		m.filter.SetValue("")
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("STOPPED")})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, []string{"web", "web2"}, names(m))
+		assert.Contains(t, m.View(), "filter: STOPPED")
+
+		// keys reach the table again, esc drops the filter
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		assert.Equal(t, "web/web2", m.selectedRow())
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.Len(t, m.table.Rows(), 6)
+		assert.Equal(t, "web/web2", m.selectedRow())
+	})
+
+	t.Run("Update Down Only", func(t *testing.T) {
+		m, _ := filterModel().Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+
+		assert.Equal(t, []string{"web", "web2", "api", "api1"}, names(m))
+		assert.Contains(t, m.View(), "only down/maint servers")
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+		assert.Len(t, m.table.Rows(), 6)
+	})
+
+	t.Run("Update Sort", func(t *testing.T) {
+		m := filterModel()
+		assert.Contains(t, m.View(), "s sort by state")
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+		assert.Equal(t, []string{"web", "web2", "web3", "web1", "api", "api1"}, names(m))
+		assert.Contains(t, m.View(), "sorted by state")
+		assert.Contains(t, m.View(), "s sort by weight")
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+		assert.Equal(t, []string{"web", "web3", "web2", "web1", "api", "api1"}, names(m))
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+		assert.Equal(t, []string{"web", "web2", "web3", "web1", "api", "api1"}, names(m))
+		assert.Contains(t, m.View(), "sorted by last change")
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+		assert.Equal(t, []string{"web", "web1", "web2", "web3", "api", "api1"}, names(m))
+		assert.NotContains(t, m.View(), "sorted by")
+
+		// the fetched backends are not reordered
+		assert.Equal(t, "web1", m.backends[0].Servers[0].Name)
+	})
+
+	t.Run("Update Collapse", func(t *testing.T) {
+		m := filterModel()
+		assert.Contains(t, m.View(), "2/3 UP, 1 DRAIN, 1 DOWN")
+		assert.Contains(t, m.View(), "0/1 UP, 1 MAINT")
+
+		// on a server row its backend is collapsed
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Nil(t, cmd)
+		assert.Equal(t, []string{"web", "api", "api1"}, names(m))
+		assert.Equal(t, "▸ web", m.table.Rows()[0][1])
+		assert.Equal(t, "web", m.selectedRow())
+
+		// stays collapsed across refreshes
+		m, _ = m.Update(m.backends)
+		assert.Len(t, m.table.Rows(), 3)
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		_, server := m.selectedServer()
+		assert.Nil(t, server)
+		assert.Equal(t, "api", m.selectedRow())
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Len(t, m.table.Rows(), 6)
+	})
+
+	t.Run("Update Collapse Healthy", func(t *testing.T) {
+		m, _ := socketModel().Update([]haproxy.Backend{
+			{Name: "web", Servers: []haproxy.Server{{Name: "web1", State: haproxy.RUNNING}, {Name: "web2", State: haproxy.STOPPED}}},
+			{Name: "api", Servers: []haproxy.Server{{Name: "api1", State: haproxy.RUNNING}}},
+		})
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}})
+		assert.Equal(t, []string{"web", "web1", "web2", "api"}, names(m))
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+		assert.Len(t, m.table.Rows(), 5)
+	})
+
+	t.Run("Backend Health", func(t *testing.T) {
+		h := backendHealth(haproxy.Backend{Servers: []haproxy.Server{
+			{State: haproxy.RUNNING, UserWeight: 10},
+			{State: haproxy.RUNNING, AdminState: haproxy.DRAIN},
+			{State: haproxy.STOPPED, AdminState: haproxy.MAINT, UserWeight: 5},
+		}})
+
+		assert.Equal(t, "2/3 UP, 1 DRAIN, 1 MAINT", h.String())
+		assert.Equal(t, 15, h.weight)
+		assert.False(t, h.healthy())
+		assert.True(t, backendHealth(haproxy.Backend{}).healthy())
+	})
+
+	t.Run("Filter Backends", func(t *testing.T) {
+		backends := filterModel().backends
+
+		assert.Len(t, filterBackends(backends, "", false), 2)
+		// a matching backend is kept without servers
+		res := filterBackends(backends, "api", true)
+		assert.Len(t, res, 1)
+		assert.Len(t, res[0].Servers, 1)
+		assert.Empty(t, filterBackends(backends, "nothing", false))
+	})
+
+	t.Run("Set Value", func(t *testing.T) {
+		assert.Equal(t, "addr 10.0.0.1", setValue("addr", "10.0.0.1"))
+		assert.Equal(t, "addr 10.0.0.1 port 80", setValue("addr", "10.0.0.1:80"))
+		assert.Equal(t, "addr ::1 port 80", setValue("addr", "[::1]:80"))
+		assert.Equal(t, "weight 50%", setValue("weight", "50%"))
+	})
+
+	t.Run("Update Master Detected", func(t *testing.T) {
+		assert.NotContains(t, socketModel().View(), "processes")
+
+		m, cmd := socketModel().Update(MasterDetected{})
+
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.View(), "p processes")
+
+		_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+		assert.IsType(t, ActivateProcessesPageCmd(), cmd)
+	})
+
+	t.Run("Update Worker Selected", func(t *testing.T) {
+		_, cmd := socketModel().Update(WorkerSelected{})
+
+		assert.IsType(t, []haproxy.Backend{}, cmd())
+	})
+
+	t.Run("Update Details", func(t *testing.T) {
+		m := fakeModel(t)
+
+		// a backend row has no details
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+		assert.Nil(t, cmd)
+		assert.False(t, m.details.IsOpen())
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+		m, _ = m.Update(cmd())
+		assert.Contains(t, m.View(), "raw: 4 default 1 haproxy")
+
+		// keys go to the details until they are closed
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+		assert.False(t, m.details.IsOpen())
+		assert.Contains(t, m.View(), "Backend")
+	})
+
 	t.Run("Supports", func(t *testing.T) {
 		m := model()
 
 		assert.True(t, m.Supports([]haproxy.Backend{}, false))
+		assert.True(t, m.Supports(ServerChanged(""), false))
+		assert.True(t, m.Supports(serverStat{}, false))
+		assert.True(t, m.Supports(refreshTick(0), false))
+		assert.True(t, m.Supports(MasterDetected{}, false))
+		assert.True(t, m.Supports(WorkerSelected{}, false))
 		assert.True(t, m.Supports(tea.WindowSizeMsg{}, false))
 		assert.True(t, m.Supports(tea.KeyMsg{}, true))
 		assert.False(t, m.Supports(tea.KeyMsg{}, false))
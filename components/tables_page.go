@@ -0,0 +1,441 @@
+package components
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"haproxy-runtime-cli/styles"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TablesPage lists the stick tables and browses the entries of one of them
+type TablesPage struct {
+	socket *socket.Client
+	keys   tablesPageKeyMap
+	help   help.Model
+	tables []haproxy.Table
+	list   table.Model
+	// opened is the table whose entries are shown, empty while listing the tables
+	opened string
+	// filter is the expression HAProxy filters the entries with, like `data.http_req_rate gt 10`
+	filter  string
+	entries []haproxy.TableEntry
+	// visible are the entries in the table, sorted by sortedBy in descending order or in the order of HAProxy
+	visible []haproxy.TableEntry
+	rows    table.Model
+	// columns are the data types stored in the opened table
+	columns  []haproxy.TableData
+	sortedBy string
+	// input takes the filter or the data to set for an entry
+	input        textinput.Model
+	editing      tableEdit
+	confirmation Confirmation
+	message      string
+}
+
+type tablesPageKeyMap struct {
+	listKeyMap
+	Filter key.Binding
+	Sort   key.Binding
+	Set    key.Binding
+	Clear  key.Binding
+	confirmationKeyMap
+}
+
+// tableEdit is what the input of the tables page is taking
+type tableEdit int
+
+const (
+	notEditingTable tableEdit = iota
+	filteringEntries
+	settingData
+)
+
+type ActivateTablesPage bool
+
+// TableChanged describes a change of a stick table
+type TableChanged string
+
+type tableList []haproxy.Table
+
+type tableEntries struct {
+	name    string
+	filter  string
+	entries []haproxy.TableEntry
+}
+
+func NewTablesPage(socket *socket.Client) TablesPage {
+	km := createTablesKeyMap()
+	input := textinput.New()
+	input.PromptStyle = styles.ComplementStyle
+
+	return TablesPage{
+		socket:       socket,
+		keys:         km,
+		help:         help.New(),
+		confirmation: NewConfirmation(),
+		input:        input,
+		list: newTable([]table.Column{
+			{Title: "Table", Width: 20},
+			{Title: "Type", Width: 10},
+			{Title: "Size", Width: 10},
+			{Title: "Used", Width: 10},
+		}, []key.Binding{km.Open, km.Refresh, km.GotoStatusPage}),
+		rows: newTable(entryColumns(nil), []key.Binding{km.Filter, km.Sort, km.Set, km.Clear, km.Refresh, km.Close}),
+	}
+}
+
+func (p TablesPage) Init() tea.Cmd {
+	return nil
+}
+
+func (p TablesPage) Update(msg tea.Msg) (TablesPage, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ActivateTablesPage:
+		// the tables are fetched when needed, as `show table` requires the operator level
+		return p, p.refresh()
+	case WorkerSelected:
+		if p.tables == nil {
+			return p, nil
+		}
+		return p, p.refresh()
+	case tableList:
+		p.tables = msg
+		p.list.SetRows(tablesToRows(msg))
+		p.list = recalculateTableSize(p.list)
+		return p, nil
+	case tableEntries:
+		if msg.name == p.opened && msg.filter == p.filter {
+			p.entries = msg.entries
+			p.updateRows()
+		}
+		return p, nil
+	case TableChanged:
+		p.message = string(msg)
+		return p, p.refresh()
+	case tea.WindowSizeMsg:
+		height := msg.Height - styles.PageStyle.GetVerticalMargins() - 3 - 3 - 2
+		p.list.SetWidth(msg.Width - styles.PageStyle.GetHorizontalMargins())
+		p.list.SetHeight(height)
+		p.rows.SetWidth(msg.Width - styles.PageStyle.GetHorizontalMargins())
+		p.rows.SetHeight(height - 1)
+	case tea.KeyMsg:
+		if p.editing != notEditingTable {
+			return p.updateInput(msg)
+		}
+		if p.confirmation.Asking() {
+			var cmd tea.Cmd
+			p.confirmation, cmd = p.confirmation.Update(msg)
+			return p, cmd
+		}
+		if p.opened == "" {
+			return p.updateTables(msg)
+		}
+
+		entry := p.selected()
+		switch {
+		case key.Matches(msg, p.keys.Close):
+			p.opened = ""
+			p.message = ""
+			return p, nil
+		case key.Matches(msg, p.keys.Refresh):
+			return p, p.refresh()
+		case key.Matches(msg, p.keys.Filter):
+			return p.edit(filteringEntries, "filter: ", p.filter)
+		case key.Matches(msg, p.keys.Sort):
+			p.sortedBy = p.nextOrder()
+			p.updateRows()
+			return p, nil
+		case entry == nil:
+			// the other actions need a selected entry
+		case key.Matches(msg, p.keys.Set):
+			value := "data."
+			if p.sortedBy != "" {
+				value += p.sortedBy + " "
+			}
+			return p.edit(settingData, "set "+entry.Key+": ", value)
+		case key.Matches(msg, p.keys.Clear):
+			p.confirmation = p.confirmation.Ask(
+				fmt.Sprintf("clear %s from %s", entry.Key, p.opened),
+				changeCmd[TableChanged](p.socket, fmt.Sprintf("clear table %s key %s", p.opened, haproxy.EscapeArg(entry.Key)), "cleared "+entry.Key),
+			)
+			return p, nil
+		}
+
+		var cmd tea.Cmd
+		p.rows, cmd = p.rows.Update(msg)
+		return p, cmd
+	}
+
+	return p, nil
+}
+
+func (p TablesPage) updateTables(msg tea.KeyMsg) (TablesPage, tea.Cmd) {
+	switch {
+	case key.Matches(msg, p.keys.GotoStatusPage):
+		return p, ActivateStatusPageCmd()
+	case key.Matches(msg, p.keys.Refresh):
+		return p, p.refresh()
+	case key.Matches(msg, p.keys.Open):
+		cursor := p.list.Cursor()
+		if cursor < 0 || cursor >= len(p.tables) {
+			return p, nil
+		}
+		p.opened = p.tables[cursor].Name
+		p.filter = ""
+		p.entries = nil
+		p.sortedBy = ""
+		p.message = ""
+		p.updateRows()
+		return p, fetchTableEntries(p.socket, p.opened, p.filter)
+	}
+
+	var cmd tea.Cmd
+	p.list, cmd = p.list.Update(msg)
+
+	return p, cmd
+}
+
+func (p TablesPage) View() string {
+	if p.opened == "" {
+		return tblStyle.Render(p.list.View()) + "\n" + p.footer(p.list)
+	}
+
+	title := styles.ActiveStyle.Render(p.opened) + styles.ComplementStyle.Render(fmt.Sprintf("  %d entries", len(p.entries)))
+	if p.filter != "" {
+		title += styles.ComplementStyle.Render(" matching " + p.filter)
+	}
+	if p.sortedBy != "" {
+		title += styles.ComplementStyle.Render(", by " + p.sortedBy)
+	}
+
+	return title + "\n" + tblStyle.Render(p.rows.View()) + "\n" + p.footer(p.rows)
+}
+
+func (p TablesPage) Supports(msg tea.Msg, isActive bool) bool {
+	switch msg.(type) {
+	case ActivateTablesPage, tableList, tableEntries, TableChanged, WorkerSelected, tea.WindowSizeMsg:
+		return true
+	case tea.KeyMsg:
+		if isActive {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p TablesPage) footer(t table.Model) string {
+	switch {
+	case p.editing != notEditingTable:
+		return p.input.View() + "\n" + p.help.ShortHelpView([]key.Binding{p.keys.Confirm, p.keys.Cancel})
+	case p.confirmation.Asking():
+		return p.confirmation.View()
+	case p.message != "":
+		return styles.ComplementStyle.Render(p.message) + "\n" + t.HelpView()
+	}
+
+	return t.HelpView()
+}
+
+// refresh fetches the tables and the entries of the opened table
+func (p TablesPage) refresh() tea.Cmd {
+	if p.opened == "" {
+		return fetchTables(p.socket)
+	}
+
+	return tea.Batch(fetchTables(p.socket), fetchTableEntries(p.socket, p.opened, p.filter))
+}
+
+func (p TablesPage) edit(editing tableEdit, prompt string, value string) (TablesPage, tea.Cmd) {
+	p.editing = editing
+	p.input.Prompt = prompt
+	p.input.Placeholder = ""
+	if editing == filteringEntries {
+		p.input.Placeholder = "data.http_req_rate gt 10"
+	}
+	p.input.SetValue(value)
+	p.input.CursorEnd()
+
+	return p, p.input.Focus()
+}
+
+// updateInput fetches the entries matching the new filter, or sets the data of the selected entry
+func (p TablesPage) updateInput(msg tea.KeyMsg) (TablesPage, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		value := strings.Join(strings.Fields(p.input.Value()), " ")
+		editing := p.editing
+		p.editing = notEditingTable
+		p.input.Blur()
+
+		if editing == filteringEntries {
+			p.filter = value
+			return p, fetchTableEntries(p.socket, p.opened, p.filter)
+		}
+		entry := p.selected()
+		if entry == nil || value == "" {
+			return p, nil
+		}
+		command := fmt.Sprintf("set table %s key %s %s", p.opened, haproxy.EscapeArg(entry.Key), value)
+		return p, changeCmd[TableChanged](p.socket, command, fmt.Sprintf("set %s of %s", value, entry.Key))
+	case tea.KeyEsc:
+		p.editing = notEditingTable
+		p.input.Blur()
+		return p, nil
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+
+	return p, cmd
+}
+
+// nextOrder is the data type to sort by after the current one, cycling back to the order of HAProxy
+func (p TablesPage) nextOrder() string {
+	i := slices.IndexFunc(p.columns, func(d haproxy.TableData) bool { return d.Type() == p.sortedBy })
+	if i+1 >= len(p.columns) {
+		return ""
+	}
+
+	return p.columns[i+1].Type()
+}
+
+// updateRows shows the entries with a column per stored data type, sorted by the chosen one
+func (p *TablesPage) updateRows() {
+	p.columns = nil
+	for _, e := range p.entries {
+		for _, d := range e.Data {
+			if !slices.ContainsFunc(p.columns, func(c haproxy.TableData) bool { return c.Name == d.Name }) {
+				p.columns = append(p.columns, haproxy.TableData{Name: d.Name})
+			}
+		}
+	}
+
+	p.visible = slices.Clone(p.entries)
+	if p.sortedBy != "" {
+		slices.SortStableFunc(p.visible, func(a, b haproxy.TableEntry) int {
+			x, _ := a.Int(p.sortedBy)
+			y, _ := b.Int(p.sortedBy)
+			return cmp.Compare(y, x)
+		})
+	}
+	p.keys.Sort.SetHelp("s", "sort by "+cmp.Or(p.nextOrder(), "key"))
+	table.WithAdditionalShortHelpKeys([]key.Binding{p.keys.Filter, p.keys.Sort, p.keys.Set, p.keys.Clear, p.keys.Refresh, p.keys.Close})(&p.rows)
+
+	rows := make([]table.Row, len(p.visible))
+	for i, e := range p.visible {
+		rows[i] = table.Row{e.Key, strconv.Itoa(e.Use), (time.Duration(e.Expire) * time.Millisecond).Round(time.Second).String()}
+		for _, c := range p.columns {
+			value := ""
+			for _, d := range e.Data {
+				if d.Name == c.Name {
+					value = d.Value
+				}
+			}
+			rows[i] = append(rows[i], value)
+		}
+	}
+
+	// the rows have to fit the columns, which change with the table
+	p.rows.SetRows(nil)
+	p.rows.SetColumns(entryColumns(p.columns))
+	p.rows.SetRows(rows)
+	p.rows = recalculateTableSize(p.rows)
+	// the table leaves the cursor at -1 while it is empty
+	p.rows.SetCursor(min(max(p.rows.Cursor(), 0), len(rows)-1))
+}
+
+// selected is the entry of the selected row
+func (p TablesPage) selected() *haproxy.TableEntry {
+	cursor := p.rows.Cursor()
+	if cursor < 0 || cursor >= len(p.visible) {
+		return nil
+	}
+
+	return &p.visible[cursor]
+}
+
+func ActivateTablesPageCmd() tea.Cmd {
+	return func() tea.Msg {
+		return ActivateTablesPage(true)
+	}
+}
+
+func createTablesKeyMap() tablesPageKeyMap {
+	return tablesPageKeyMap{
+		listKeyMap: createListKeyMap("tables", "entries"),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort"),
+		),
+		Set: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "set data"),
+		),
+		Clear: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "clear entry"),
+		),
+		confirmationKeyMap: createConfirmationKeyMap(),
+	}
+}
+
+// entryColumns are the columns of the entries, followed by the given data types
+func entryColumns(data []haproxy.TableData) []table.Column {
+	columns := []table.Column{
+		{Title: "Key", Width: 20},
+		{Title: "Use", Width: 4},
+		{Title: "Expires", Width: 8},
+	}
+	for _, d := range data {
+		columns = append(columns, table.Column{Title: d.Name, Width: len(d.Name)})
+	}
+
+	return columns
+}
+
+func tablesToRows(tables []haproxy.Table) []table.Row {
+	rows := make([]table.Row, len(tables))
+	for i, t := range tables {
+		rows[i] = table.Row{t.Name, t.Type, strconv.Itoa(t.Size), strconv.Itoa(t.Used)}
+	}
+
+	return rows
+}
+
+func fetchTables(s *socket.Client) tea.Cmd {
+	return socket.ExecCmd[tableList](
+		context.Background(),
+		s,
+		"show table",
+		func(out *string) (tableList, error) { return haproxy.ParseTables(*out) },
+	)
+}
+
+// fetchTableEntries fetches the entries HAProxy filters with the expression, e.g. `data.conn_cur gt 0` or `key 10.0.0.1`
+func fetchTableEntries(s *socket.Client, name string, filter string) tea.Cmd {
+	return socket.ExecCmd[tableEntries](
+		context.Background(),
+		s,
+		strings.TrimSpace("show table "+name+" "+filter),
+		func(out *string) (tableEntries, error) {
+			entries, err := haproxy.ParseTableEntries(*out)
+			return tableEntries{name: name, filter: filter, entries: entries}, err
+		},
+	)
+}
@@ -0,0 +1,180 @@
+package components
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/haproxy"
+	"testing"
+)
+
+func TestTablesPage(t *testing.T) {
+	t.Parallel()
+
+	// fakeModel shows the entries of the http-in table of the fake
+	fakeModel := func(t *testing.T) TablesPage {
+		m, cmd := NewTablesPage(fakeClient(t)).Update(ActivateTablesPage(true))
+		m, _ = m.Update(cmd())
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, _ = m.Update(cmd())
+
+		return m
+	}
+
+	t.Run("Update Activate", func(t *testing.T) {
+		m := NewTablesPage(nil)
+		assert.Nil(t, m.Init())
+
+		m, _ = m.Update(tableList{{Name: "http-in", Type: "ip", Size: 102400, Used: 2}})
+
+		res := m.View()
+		assert.Contains(t, res, "http-in")
+		assert.Contains(t, res, "102400")
+	})
+
+	t.Run("Update Open", func(t *testing.T) {
+		m := fakeModel(t)
+
+		assert.Equal(t, "http-in", m.opened)
+		res := m.View()
+		assert.Contains(t, res, "http-in  2 entries")
+		assert.Contains(t, res, "http_req_rate(10000)")
+		assert.Contains(t, res, "28s")
+		assert.Equal(t, "10.0.0.1", m.selected().Key)
+
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		assert.Empty(t, m.opened)
+	})
+
+	t.Run("Update Sort", func(t *testing.T) {
+		m, _ := NewTablesPage(nil).Update(tableList{{Name: "http-in"}})
+		m.opened = "http-in"
+		m, _ = m.Update(tableEntries{name: "http-in", entries: []haproxy.TableEntry{
+			{Key: "a", Data: []haproxy.TableData{{Name: "conn_cur", Value: "1"}, {Name: "http_req_rate(10000)", Value: "3"}}},
+			{Key: "b", Data: []haproxy.TableData{{Name: "conn_cur", Value: "2"}, {Name: "http_req_rate(10000)", Value: "42"}}},
+		}})
+		assert.Contains(t, m.View(), "s sort by conn_cur")
+
+		m, _ = m.Update(keyMsg('s'))
+		assert.Equal(t, "conn_cur", m.sortedBy)
+		assert.Equal(t, "b", m.selected().Key)
+		assert.Contains(t, m.View(), "s sort by http_req_rate")
+
+		m, _ = m.Update(keyMsg('s'))
+		m, _ = m.Update(keyMsg('s'))
+		assert.Empty(t, m.sortedBy)
+		assert.Contains(t, m.View(), "s sort by conn_cur")
+		// the entries are back in the order of HAProxy
+		assert.Equal(t, "a", m.rows.Rows()[0][0])
+		assert.Equal(t, "b", m.rows.Rows()[1][0])
+		assert.Equal(t, "a", m.selected().Key)
+	})
+
+	t.Run("Update Filter", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('/'))
+		m.input.SetValue("data.http_req_rate  gt 10")
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, _ = m.Update(cmd())
+
+		assert.Equal(t, "data.http_req_rate gt 10", m.filter)
+		assert.Len(t, m.entries, 1)
+		assert.Contains(t, m.View(), "1 entries matching data.http_req_rate gt 10")
+
+		// entries of a previous filter are ignored
+		m, _ = m.Update(tableEntries{name: "http-in", entries: []haproxy.TableEntry{{}, {}, {}}})
+		assert.Len(t, m.entries, 1)
+	})
+
+	t.Run("Update Filter Invalid", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('/'))
+		m.input.SetValue("conn_cur gt 0")
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+		assert.ErrorContains(t, cmd().(error), "Optional argument only supports")
+	})
+
+	t.Run("Update Set", func(t *testing.T) {
+		m := fakeModel(t)
+		m, _ = m.Update(keyMsg('s'))
+
+		m, _ = m.Update(keyMsg('e'))
+		assert.Equal(t, "data.conn_cur ", m.input.Value())
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("7")})
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = apply(m, cmd)
+
+		assert.Contains(t, m.View(), "set data.conn_cur 7 of 10.0.0.1")
+		count, _ := m.entries[0].Int("conn_cur")
+		assert.Equal(t, int64(7), count)
+	})
+
+	t.Run("Update Clear", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, cmd := m.Update(keyMsg('x'))
+		assert.Nil(t, cmd)
+		assert.Contains(t, m.View(), "clear 10.0.0.1 from http-in?")
+
+		m, cmd = m.Update(keyMsg('y'))
+		m = apply(m, cmd)
+
+		assert.Len(t, m.entries, 1)
+		assert.Equal(t, "10.0.0.2", m.entries[0].Key)
+		assert.Equal(t, 1, m.tables[0].Used)
+	})
+
+	t.Run("Update Escaped Key", func(t *testing.T) {
+		srv := fakeServer(t)
+		srv.HAProxy.Exec(`set table http-in key a\;b data.conn_cur 9`)
+		m, cmd := NewTablesPage(dial(t, srv)).Update(ActivateTablesPage(true))
+		m, _ = m.Update(cmd())
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m, _ = m.Update(cmd())
+		m, _ = m.Update(keyMsg('s'))
+		assert.Equal(t, "a;b", m.selected().Key)
+
+		m, _ = m.Update(keyMsg('e'))
+		m.input.SetValue("data.conn_cur 10")
+		m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = apply(m, cmd)
+		count, _ := m.selected().Int("conn_cur")
+		assert.Equal(t, int64(10), count)
+
+		m, _ = m.Update(keyMsg('x'))
+		m, cmd = m.Update(keyMsg('y'))
+		m = apply(m, cmd)
+		assert.Len(t, m.entries, 2)
+		assert.NotEqual(t, "a;b", m.selected().Key)
+	})
+
+	t.Run("Update Cancel", func(t *testing.T) {
+		m := fakeModel(t)
+
+		m, _ = m.Update(keyMsg('x'))
+		m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+		assert.Nil(t, cmd)
+		assert.False(t, m.confirmation.Asking())
+	})
+
+	t.Run("Update Goto Status Page", func(t *testing.T) {
+		_, cmd := NewTablesPage(nil).Update(tea.KeyMsg{Type: tea.KeyBackspace})
+
+		assert.IsType(t, ActivateStatusPageCmd(), cmd)
+	})
+
+	t.Run("Supports", func(t *testing.T) {
+		m := NewTablesPage(nil)
+
+		assert.True(t, m.Supports(ActivateTablesPage(true), false))
+		assert.True(t, m.Supports(tableList{}, false))
+		assert.True(t, m.Supports(tableEntries{}, false))
+		assert.True(t, m.Supports(TableChanged(""), false))
+		assert.True(t, m.Supports(WorkerSelected{}, false))
+		assert.True(t, m.Supports(tea.KeyMsg{}, true))
+		assert.False(t, m.Supports(tea.KeyMsg{}, false))
+	})
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/socket"
+	"io"
+	"os"
+	"strings"
+)
+
+// exit codes of the exec subcommand
+const (
+	exitOk           = 0
+	exitCommandError = 1
+	exitUsageError   = 2
+	exitSocketError  = 3
+)
+
+// runExec executes runtime api commands without the ui, either given as arguments,
+// or line by line from a file (-f) or stdin, and returns the process exit code
+func runExec(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("exec", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	file := flags.String("f", "", "read commands line by line from `file` (- for stdin)")
+	keepGoing := flags.Bool("k", false, "keep going after a command failed")
+	tlsOptions := tlsFlags(flags)
+	timeout := timeoutFlag(flags)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintln(stderr, "Usage: haproxy-runtime-cli exec [flags] <socket> [command]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsageError
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsageError
+	}
+
+	commands, err := readCommands(flags.Args()[1:], *file, stdin)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsageError
+	}
+
+	endpoint, err := socket.ParseEndpoint(flags.Arg(0), *tlsOptions)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsageError
+	}
+
+	conn, err := endpoint.Dial()
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitSocketError
+	}
+	_ = conn.Close()
+
+	// commands are sent as given, on a master cli they can be routed with @<pid> prefixes
+	client := socket.NewClient(endpoint.Dial)
+	client.SetTimeout(*timeout)
+	defer client.Close()
+
+	code := exitOk
+	for _, command := range commands {
+		res, err := socket.Exec(context.Background(), client, command)
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return exitSocketError
+		}
+
+		if *res != "" {
+			_, _ = fmt.Fprintln(stdout, *res)
+		}
+
+		if err := haproxy.CheckResponse(command, *res); err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			code = exitCommandError
+			if !*keepGoing {
+				return code
+			}
+		}
+	}
+
+	return code
+}
+
+// readCommands returns the command given as argument, or the non-empty, non-comment lines of the input
+func readCommands(args []string, file string, stdin io.Reader) ([]string, error) {
+	switch {
+	case len(args) > 1:
+		return nil, fmt.Errorf("the command is a single argument, quote it: %q", strings.Join(args, " "))
+	case len(args) == 1 && file != "":
+		return nil, errors.New("the command is given as argument or read with -f, not both")
+	case len(args) == 1:
+		return args, nil
+	}
+
+	var r io.Reader
+	switch file {
+	case "", "-":
+		r = stdin
+	default:
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var commands []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		commands = append(commands, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(commands) == 0 {
+		return nil, errors.New("no commands given")
+	}
+
+	return commands, nil
+}
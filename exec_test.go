@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/fake"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExec(t *testing.T) {
+	srv, err := fake.NewServer()
+	assert.Nil(t, err)
+	defer srv.Close()
+
+	run := func(stdin string, args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := runExec(args, strings.NewReader(stdin), &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	t.Run("Command from arguments", func(t *testing.T) {
+		code, out, errOut := run("", srv.Addr, "show backend")
+
+		assert.Equal(t, exitOk, code)
+		assert.Equal(t, "# name\ndefault\nother\n", out)
+		assert.Empty(t, errOut)
+	})
+
+	t.Run("Commands from stdin", func(t *testing.T) {
+		code, out, _ := run("# drain\nset server other/apache weight 5\n\nget weight other/apache\n", srv.Addr)
+
+		assert.Equal(t, exitOk, code)
+		assert.Equal(t, "5 (initial 1)\n", out)
+	})
+
+	t.Run("Commands from file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "commands.txt")
+		assert.Nil(t, os.WriteFile(file, []byte("show cli level\n"), 0o600))
+
+		code, out, _ := run("", "-f", file, srv.Addr)
+
+		assert.Equal(t, exitOk, code)
+		assert.Equal(t, "admin\n", out)
+	})
+
+	t.Run("Stops at the first error", func(t *testing.T) {
+		code, out, errOut := run("get weight default/foo\nshow cli level\n", srv.Addr)
+
+		assert.Equal(t, exitCommandError, code)
+		assert.Equal(t, "No such server.\n", out)
+		assert.Equal(t, "get weight default/foo: No such server.\n", errOut)
+	})
+
+	t.Run("Keeps going after an error", func(t *testing.T) {
+		code, out, _ := run("get weight default/foo\nshow cli level\n", "-k", srv.Addr)
+
+		assert.Equal(t, exitCommandError, code)
+		assert.Equal(t, "No such server.\nadmin\n", out)
+	})
+
+	t.Run("Quoted response", func(t *testing.T) {
+		code, out, errOut := run("", srv.Addr, "echo 'x'")
+
+		assert.Equal(t, exitOk, code)
+		assert.Equal(t, "'x'\n", out)
+		assert.Empty(t, errOut)
+	})
+
+	t.Run("Usage", func(t *testing.T) {
+		code, _, errOut := run("")
+
+		assert.Equal(t, exitUsageError, code)
+		assert.Contains(t, errOut, "Usage: haproxy-runtime-cli exec")
+
+		code, _, errOut = run("", srv.Addr)
+		assert.Equal(t, exitUsageError, code)
+		assert.Equal(t, "no commands given\n", errOut)
+
+		// a command split into several arguments would be sent as one
+		code, out, errOut := run("", srv.Addr, "show", "backend")
+		assert.Equal(t, exitUsageError, code)
+		assert.Empty(t, out)
+		assert.Equal(t, "the command is a single argument, quote it: \"show backend\"\n", errOut)
+
+		code, out, errOut = run("", "-f", "-", srv.Addr, "show backend")
+		assert.Equal(t, exitUsageError, code)
+		assert.Empty(t, out)
+		assert.Equal(t, "the command is given as argument or read with -f, not both\n", errOut)
+	})
+
+	t.Run("Unreachable socket", func(t *testing.T) {
+		code, _, _ := run("", filepath.Join(t.TempDir(), "missing.sock"), "help")
+
+		assert.Equal(t, exitSocketError, code)
+	})
+}
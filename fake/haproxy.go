@@ -0,0 +1,292 @@
+// Package fake implements an in-process HAProxy runtime api with mutable state,
+// so the socket layer and the ui can be exercised without a real HAProxy.
+package fake
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const Version = "3.1.0-fake"
+
+type level int
+
+const (
+	levelUser level = iota
+	levelOperator
+	levelAdmin
+)
+
+func (l level) String() string {
+	switch l {
+	case levelUser:
+		return "user"
+	case levelOperator:
+		return "operator"
+	default:
+		return "admin"
+	}
+}
+
+// HAProxy holds the runtime state and answers runtime api commands
+type HAProxy struct {
+	mu        sync.Mutex
+	refs      int
+	backends  []*backend
+	frontends []*frontend
+	maps      []*patternList
+	acls      []*patternList
+	tables    []*stickTable
+	streams   []*stream
+	certs     []*certFile
+	// certTransaction is the uncommitted replacement of a certificate file, HAProxy allows one at a time
+	certTransaction *certFile
+}
+
+// Session is the per connection state of a cli client
+type Session struct {
+	level level
+}
+
+type handler struct {
+	name  string
+	level level
+	fn    handlerFunc
+}
+
+type handlerFunc func(h *HAProxy, s *Session, args []string, payload string) string
+
+var handlers []handler
+
+func init() {
+	handlers = []handler{
+		{"help", levelUser, (*HAProxy).help},
+		{"echo", levelUser, (*HAProxy).echo},
+		{"operator", levelUser, lowerLevel(levelOperator)},
+		{"user", levelUser, lowerLevel(levelUser)},
+		{"show cli level", levelUser, (*HAProxy).showCliLevel},
+		{"show version", levelUser, (*HAProxy).showVersion},
+		{"show info", levelUser, (*HAProxy).showInfo},
+		{"show backend", levelUser, (*HAProxy).showBackend},
+		{"show servers state", levelUser, (*HAProxy).showServersState},
+		{"show stat", levelUser, (*HAProxy).showStat},
+		{"get weight", levelUser, (*HAProxy).getWeight},
+		{"set server", levelAdmin, (*HAProxy).setServer},
+		{"set maxconn server", levelAdmin, (*HAProxy).setMaxconnServer},
+		{"enable health", levelAdmin, toggleCheck(false, true)},
+		{"disable health", levelAdmin, toggleCheck(false, false)},
+		{"enable agent", levelAdmin, toggleCheck(true, true)},
+		{"disable agent", levelAdmin, toggleCheck(true, false)},
+		{"enable frontend", levelAdmin, (*HAProxy).enableFrontend},
+		{"disable frontend", levelAdmin, (*HAProxy).disableFrontend},
+		{"shutdown frontend", levelAdmin, (*HAProxy).shutdownFrontend},
+		{"set maxconn frontend", levelAdmin, (*HAProxy).setMaxconnFrontend},
+		{"show map", levelOperator, showPatterns(true)},
+		{"get map", levelOperator, getPattern(true)},
+		{"add map", levelAdmin, addPatterns(true)},
+		{"set map", levelAdmin, (*HAProxy).setMap},
+		{"del map", levelAdmin, delPattern(true)},
+		{"clear map", levelAdmin, clearPatterns(true)},
+		{"prepare map", levelAdmin, preparePatterns(true)},
+		{"commit map", levelAdmin, commitPatterns(true)},
+		{"show acl", levelOperator, showPatterns(false)},
+		{"get acl", levelOperator, getPattern(false)},
+		{"add acl", levelAdmin, addPatterns(false)},
+		{"del acl", levelAdmin, delPattern(false)},
+		{"clear acl", levelAdmin, clearPatterns(false)},
+		{"prepare acl", levelAdmin, preparePatterns(false)},
+		{"commit acl", levelAdmin, commitPatterns(false)},
+		{"show table", levelOperator, (*HAProxy).showTable},
+		{"clear table", levelOperator, (*HAProxy).clearTable},
+		{"set table", levelAdmin, (*HAProxy).setTable},
+		{"show sess", levelOperator, (*HAProxy).showSess},
+		{"shutdown session", levelAdmin, (*HAProxy).shutdownSession},
+		{"shutdown sessions server", levelAdmin, (*HAProxy).shutdownSessionsServer},
+		{"show ssl cert", levelOperator, (*HAProxy).showSSLCert},
+		{"set ssl cert", levelAdmin, (*HAProxy).setSSLCert},
+		{"commit ssl cert", levelAdmin, (*HAProxy).commitSSLCert},
+		{"abort ssl cert", levelAdmin, (*HAProxy).abortSSLCert},
+	}
+}
+
+// New creates a HAProxy prefilled with a demo state
+func New() *HAProxy {
+	h := &HAProxy{}
+	h.demoState()
+
+	return h
+}
+
+// NewSession creates the state of a fresh admin level cli connection
+func NewSession() *Session {
+	return &Session{level: levelAdmin}
+}
+
+// Exec runs a command line (multiple commands can be separated by `;`) in a fresh session
+func (h *HAProxy) Exec(line string) string {
+	return h.ExecSession(NewSession(), line, "")
+}
+
+// ExecSession runs a command line with an optional payload (passed with `<<`) in the given session
+func (h *HAProxy) ExecSession(s *Session, line string, payload string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out := ""
+	for _, cmd := range splitCommands(line) {
+		out += h.dispatch(s, splitWords(cmd), payload)
+	}
+
+	return out
+}
+
+func (h *HAProxy) dispatch(s *Session, words []string, payload string) string {
+	if len(words) == 0 {
+		return ""
+	}
+
+	var match *handler
+	var matched int
+	for i := range handlers {
+		name := strings.Fields(handlers[i].name)
+		if len(name) > len(words) || len(name) <= matched || !slices.Equal(name, words[:len(name)]) {
+			continue
+		}
+		match = &handlers[i]
+		matched = len(name)
+	}
+
+	if match == nil {
+		return fmt.Sprintf("Unknown command: '%s'. Please enter one of the following commands only:\n\n", words[0])
+	}
+
+	if s.level < match.level {
+		return "Permission denied\n\n"
+	}
+
+	return terminate(match.fn(h, s, words[matched:], payload))
+}
+
+// terminate ensures every response ends with an empty line, as HAProxy does
+func terminate(out string) string {
+	if out == "" {
+		return "\n"
+	}
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+
+	return out + "\n"
+}
+
+// splitCommands splits a command line on unescaped semicolons, the escapes are kept for splitWords
+func splitCommands(line string) []string {
+	var cmds []string
+	cur := ""
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line):
+			cur += line[i : i+2]
+			i++
+		case line[i] == ';':
+			cmds = append(cmds, cur)
+			cur = ""
+		default:
+			cur += string(line[i])
+		}
+	}
+
+	return append(cmds, cur)
+}
+
+// splitWords splits a command into its arguments at unescaped spaces and tabs, a backslash escapes
+// the following character
+func splitWords(cmd string) []string {
+	var words []string
+	var cur strings.Builder
+	inWord := false
+	for i := 0; i < len(cmd); i++ {
+		switch c := cmd[i]; {
+		case c == '\\' && i+1 < len(cmd):
+			cur.WriteByte(cmd[i+1])
+			inWord = true
+			i++
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+
+	return words
+}
+
+func (h *HAProxy) nextRef() string {
+	h.refs++
+	return fmt.Sprintf("0x55d7e1c%05x", h.refs*0x20)
+}
+
+func (h *HAProxy) help(_ *Session, args []string, _ string) string {
+	if len(args) == 0 {
+		return helpText
+	}
+
+	prefix := strings.Join(args, " ")
+	lines := strings.Split(helpText, "\n")
+	out := lines[0] + "\n"
+	for _, l := range lines[1:] {
+		if strings.HasPrefix(strings.TrimSpace(l), prefix) {
+			out += l + "\n"
+		}
+	}
+
+	return out
+}
+
+func (h *HAProxy) echo(_ *Session, args []string, _ string) string {
+	return strings.Join(args, " ")
+}
+
+func lowerLevel(l level) handlerFunc {
+	return func(_ *HAProxy, s *Session, _ []string, _ string) string {
+		if l < s.level {
+			s.level = l
+		}
+
+		return ""
+	}
+}
+
+func (h *HAProxy) showCliLevel(s *Session, _ []string, _ string) string {
+	return s.level.String()
+}
+
+func (h *HAProxy) showVersion(_ *Session, _ []string, _ string) string {
+	return Version
+}
+
+func (h *HAProxy) showInfo(_ *Session, _ []string, _ string) string {
+	return "Name: HAProxy\n" +
+		"Version: " + Version + "\n" +
+		"Release_date: 2024/11/26\n" +
+		"Nbthread: 4\n" +
+		"Nbproc: 1\n" +
+		"Process_num: 1\n" +
+		"Pid: 8\n" +
+		"Uptime: 0d 0h01m07s\n" +
+		"Uptime_sec: 67\n" +
+		"Maxconn: 3000\n" +
+		"CurrConns: 0\n" +
+		"CumConns: 1\n" +
+		"Stopping: 0\n"
+}
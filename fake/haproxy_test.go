@@ -0,0 +1,254 @@
+package fake
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHelp(t *testing.T) {
+	h := New()
+
+	assert.Equal(t, helpText+"\n", h.Exec("help"))
+	assert.Contains(t, h.Exec("help show map"), "show map [@ver] [map]")
+	assert.NotContains(t, h.Exec("help show map"), "show acl")
+}
+
+func TestUnknownCommand(t *testing.T) {
+	assert.Contains(t, New().Exec("foo bar"), "Unknown command: 'foo'")
+}
+
+func TestMultipleCommands(t *testing.T) {
+	assert.Equal(t, "foo\n\nbar;baz\n\n", New().Exec(`echo foo;echo bar\;baz`))
+	assert.Equal(t, "a\\\n\nb\n\n", New().Exec(`echo a\\;echo b`))
+}
+
+func TestPermissions(t *testing.T) {
+	h := New()
+
+	assert.Equal(t, "admin\n\n", h.Exec("show cli level"))
+	assert.Equal(t, "\nuser\n\n", h.Exec("user; show cli level"))
+	assert.Equal(t, "\nPermission denied\n\n", h.Exec("operator; set server default/apache state maint"))
+}
+
+func TestServersState(t *testing.T) {
+	h := New()
+
+	out := h.Exec("show servers state")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	assert.Len(t, lines, 6)
+	assert.Equal(t, "1", lines[0])
+	assert.Equal(t, strings.TrimSpace(serversStateHeader), lines[1])
+	assert.Len(t, strings.Fields(lines[2]), 25)
+
+	assert.Len(t, strings.Split(strings.TrimSpace(h.Exec("show servers state other")), "\n"), 4)
+	assert.Equal(t, "Can't find backend.\n\n", h.Exec("show servers state unknown"))
+}
+
+func TestSetServer(t *testing.T) {
+	h := New()
+
+	assert.Equal(t, "\n", h.Exec("set server default/apache state maint"))
+	assert.Contains(t, h.Exec("show servers state default"), "4 default 2 apache 151.101.2.132 0 1 80")
+
+	assert.Equal(t, "\n", h.Exec("set server default/apache state drain"))
+	assert.Contains(t, h.Exec("show servers state default"), "4 default 2 apache 151.101.2.132 2 8 80")
+
+	assert.Equal(t, "\n", h.Exec("set server default/apache weight 10"))
+	assert.Equal(t, "10 (initial 80)\n\n", h.Exec("get weight default/apache"))
+
+	assert.Contains(t, h.Exec("set server default/apache addr 10.0.0.1 port 8080"), "IP changed from '151.101.2.132' to '10.0.0.1', port changed from '443' to '8080'")
+	assert.Contains(t, h.Exec("show servers state default"), "4 default 2 apache 10.0.0.1 2 8 10")
+
+	assert.Equal(t, "\n", h.Exec("disable health default/apache"))
+	assert.Contains(t, h.Exec("show servers state default"), " 3 4 2 0 0 0 apache.org 8080 ")
+
+	assert.Equal(t, "No such server.\n\n", h.Exec("set server default/unknown state ready"))
+	assert.Equal(t, "No such backend.\n\n", h.Exec("set server unknown/apache state ready"))
+	assert.Equal(t, "Require 'backend/server'.\n\n", h.Exec("get weight apache"))
+	assert.Contains(t, h.Exec("set server default/apache state foo"), "expects 'ready', 'drain' and 'maint'")
+}
+
+func TestFrontends(t *testing.T) {
+	h := New()
+
+	assert.Equal(t, "\n", h.Exec("disable frontend http-in"))
+	assert.Equal(t, "Frontend is already disabled.\n\n", h.Exec("disable frontend http-in"))
+	assert.Equal(t, "\n", h.Exec("enable frontend http-in"))
+	assert.Equal(t, "\n", h.Exec("set maxconn frontend http-in 100"))
+	assert.Equal(t, 100, h.frontend("http-in").maxconn)
+	assert.Equal(t, "Frontend http-in was stopped.\n\n", h.Exec("shutdown frontend http-in"))
+	assert.Equal(t, "Frontend was already shut down.\n\n", h.Exec("enable frontend http-in"))
+	assert.Equal(t, "No such frontend.\n\n", h.Exec("enable frontend unknown"))
+}
+
+func TestStat(t *testing.T) {
+	h := New()
+
+	assert.True(t, strings.HasPrefix(h.Exec("show stat"), "# pxname,svname,qcur,qmax,scur,smax,slim,stot,bin,bout,"))
+	assert.Contains(t, h.Exec("show stat"), "\nhttp-in,FRONTEND,,,3,12,3000,1542,2384511,48211037,4,,7,0,0,,,OPEN,")
+	assert.Contains(t, h.Exec("show stat"), "\nhttps-in,sock-2,,,0,0,,0,0,0,0,,0,0,0,,,OPEN,")
+	assert.Contains(t, h.Exec("show stat"), ",[::]:81,")
+	assert.Contains(t, h.Exec("show stat"), "\nother,apache,,,0,1,,3,4102,98312,0,,0,2,0,,,DOWN,1,")
+
+	h.Exec("disable frontend http-in;set server default/apache state maint")
+	assert.Contains(t, h.Exec("show stat"), "\nhttp-in,FRONTEND,,,3,12,3000,1542,2384511,48211037,4,,7,0,0,,,PAUSED,")
+	assert.Contains(t, h.Exec("show stat"), "\ndefault,apache,,,2,4,,770,1191204,24099101,0,,0,0,0,,,MAINT,")
+
+	// narrowed down to a server, by backend id and by name
+	for _, filter := range []string{"4 4 2", "default 4 2"} {
+		lines := strings.Split(strings.TrimSpace(h.Exec("show stat "+filter)), "\n")
+		assert.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[1], "default,apache,"))
+	}
+	assert.Len(t, strings.Split(strings.TrimSpace(h.Exec("show stat -1 1 -1")), "\n"), 6)
+	assert.Equal(t, "Require <iid|proxy> <type> <sid>.\n\n", h.Exec("show stat 4"))
+}
+
+func TestMaps(t *testing.T) {
+	h := New()
+	const hosts = "/etc/haproxy/maps/hosts.map"
+
+	assert.Contains(t, h.Exec("show map"), "-1 ("+hosts+") pattern loaded from file")
+	assert.Contains(t, h.Exec("show map"), "curr_ver=0 next_ver=0 entry_cnt=2")
+
+	assert.Equal(t, "\n", h.Exec("add map "+hosts+" foo.com default"))
+	assert.Contains(t, h.Exec("show map #-1"), "foo.com default")
+	assert.Equal(t, "\n", h.Exec("set map "+hosts+" foo.com other"))
+	assert.Contains(t, h.Exec("get map "+hosts+" foo.com"), `found=yes, idx=tree, key="foo.com", value="other"`)
+	assert.Equal(t, "\n", h.Exec("del map "+hosts+" foo.com"))
+	assert.Contains(t, h.Exec("get map "+hosts+" foo.com"), "found=no")
+	assert.Equal(t, "Key not found.\n\n", h.Exec("del map "+hosts+" foo.com"))
+	assert.Contains(t, h.Exec("show map unknown.map"), "Unknown map identifier")
+
+	// words are split at unescaped spaces, further words are ignored
+	assert.Equal(t, "\n", h.Exec(`add map `+hosts+` a.com b\ c\;d\\ ignored`))
+	assert.Contains(t, h.Exec("get map "+hosts+" a.com"), `value="b c;d\"`)
+}
+
+func TestMapPayload(t *testing.T) {
+	h := New()
+	const hosts = "/etc/haproxy/maps/hosts.map"
+
+	assert.Equal(t, "\n", h.ExecSession(NewSession(), "add map "+hosts, "a.com default\nb.com other\n"))
+	assert.Len(t, h.maps[0].current(), 4)
+}
+
+func TestMapTransaction(t *testing.T) {
+	h := New()
+	const hosts = "/etc/haproxy/maps/hosts.map"
+
+	assert.Equal(t, "New version created: 1\n\n", h.Exec("prepare map "+hosts))
+	assert.Equal(t, "\n", h.Exec("add map @1 "+hosts+" new.com default"))
+	assert.Equal(t, "Unknown version: 2.\n\n", h.Exec("add map @2 "+hosts+" new.com default"))
+
+	// still serving the old version
+	assert.NotContains(t, h.Exec("show map "+hosts), "new.com")
+	assert.Contains(t, h.Exec("show map @1 "+hosts), "new.com")
+
+	assert.Equal(t, "\n", h.Exec("commit map @1 "+hosts))
+	assert.Equal(t, "Unknown version: 1.\n\n", h.Exec("commit map @1 "+hosts))
+	out := h.Exec("show map " + hosts)
+	assert.Contains(t, out, "new.com default")
+	assert.NotContains(t, out, "example.com")
+}
+
+func TestAcls(t *testing.T) {
+	h := New()
+
+	assert.Contains(t, h.Exec("show acl"), "1 () acl 'path_beg' file")
+	assert.Contains(t, h.Exec("get acl #1 /admin/users"), `match=yes, idx=list, pattern="/admin"`)
+	assert.Contains(t, h.Exec("get acl #1 /public"), "match=no")
+	assert.Equal(t, "\n", h.Exec("add acl #1 /debug"))
+	assert.Contains(t, h.Exec("show acl #1"), "/debug")
+	assert.Equal(t, "\n", h.Exec("clear acl #1"))
+	assert.Equal(t, "\n", h.Exec("show acl #1"))
+}
+
+func TestTables(t *testing.T) {
+	h := New()
+
+	assert.Equal(t, "# table: http-in, type: ip, size:102400, used:2\n\n", h.Exec("show table"))
+
+	out := h.Exec("show table http-in data.http_req_rate gt 10")
+	assert.Contains(t, out, "key=10.0.0.1 use=0 exp=28000 shard=0 conn_cur=1 http_req_rate(10000)=42")
+	assert.NotContains(t, out, "10.0.0.2")
+
+	assert.Equal(t, "\n", h.Exec("set table http-in key 10.0.0.3 data.http_req_rate 7"))
+	assert.Contains(t, h.Exec("show table http-in key 10.0.0.3"), "http_req_rate(10000)=7")
+
+	assert.Equal(t, "\n", h.Exec("clear table http-in key 10.0.0.3"))
+	assert.NotContains(t, h.Exec("show table http-in"), "10.0.0.3")
+
+	assert.Equal(t, "No such table\n\n", h.Exec("show table unknown"))
+	assert.Equal(t, "Data type not stored in this table\n\n", h.Exec("show table http-in data.bytes_in_rate gt 1"))
+}
+
+func TestSSLCert(t *testing.T) {
+	h := New()
+	const site = "/usr/local/etc/haproxy/certs/site.pem"
+
+	assert.Equal(t, "# filename\n"+site+"\n/usr/local/etc/haproxy/certs/api.pem\n\n", h.Exec("show ssl cert"))
+	details := h.Exec("show ssl cert " + site)
+	assert.Contains(t, details, "Filename: "+site+"\nStatus: Used\nSerial: D933C1B\n")
+	assert.Contains(t, details, "Subject Alternative Name: DNS:example.com, DNS:www.example.com\nAlgorithm: EC256\n")
+	assert.Contains(t, details, "Subject: /O=Example/CN=example.com\nIssuer: /C=US/O=Fake CA/CN=Fake CA R1\n")
+	assert.Contains(t, details, "Chain Subject: /C=US/O=Fake CA/CN=Fake CA R1\n")
+	assert.Regexp(t, `notAfter: [A-Z][a-z]{2} [ 0-9]{2} \d{2}:\d{2}:\d{2} \d{4} GMT`, details)
+	assert.Contains(t, h.Exec("show ssl cert unknown.pem"), "Can't display the certificate")
+}
+
+func TestSSLCertTransaction(t *testing.T) {
+	h := New()
+	const site = "/usr/local/etc/haproxy/certs/site.pem"
+	cert := string(CertPEM([]string{"new.example.com"}, time.Now().Add(90*24*time.Hour)))
+
+	assert.Equal(t, "Transaction created for certificate "+site+"!\n\n", h.ExecSession(NewSession(), "set ssl cert "+site, cert))
+	assert.Equal(t, "Transaction updated for certificate "+site+"!\n\n", h.ExecSession(NewSession(), "set ssl cert "+site, cert))
+	assert.Contains(t, h.ExecSession(NewSession(), "set ssl cert /usr/local/etc/haproxy/certs/api.pem", cert), "The ongoing transaction is about")
+	assert.Contains(t, h.ExecSession(NewSession(), "set ssl cert unknown.pem", cert), "Can't replace a certificate")
+	assert.Contains(t, h.ExecSession(NewSession(), "set ssl cert "+site, "garbage\n"), "Can't load the payload")
+
+	assert.True(t, strings.HasPrefix(h.Exec("show ssl cert"), "# transaction\n*"+site+"\n# filename\n"))
+	assert.Contains(t, h.Exec("show ssl cert *"+site), "Status: Unused\n")
+	assert.Contains(t, h.Exec("show ssl cert *"+site), "Subject: /CN=new.example.com\n")
+	assert.Contains(t, h.Exec("show ssl cert "+site), "Subject: /O=Example/CN=example.com\n")
+
+	assert.Equal(t, "Committing "+site+".\nSuccess!\n\n", h.Exec("commit ssl cert "+site))
+	assert.Contains(t, h.Exec("show ssl cert "+site), "Subject: /CN=new.example.com\n")
+	assert.Equal(t, "No ongoing transaction!\n\n", h.Exec("commit ssl cert "+site))
+
+	h.ExecSession(NewSession(), "set ssl cert "+site, cert)
+	assert.Equal(t, "Transaction aborted for certificate '"+site+"'!\n\n", h.Exec("abort ssl cert "+site))
+	assert.Equal(t, "No ongoing transaction!\n\n", h.Exec("abort ssl cert "+site))
+}
+
+func TestSessions(t *testing.T) {
+	h := New()
+
+	lines := strings.Split(strings.TrimSpace(h.Exec("show sess")), "\n")
+	assert.Len(t, lines, 3)
+	assert.Regexp(t, `^0x[0-9a-f]+: proto=tcpv4 src=10.0.0.1:51234 fe=http-in be=default srv=apache ts=00 epoch=0 age=1m1[0-9]s .* rq\[f=848000h,`, lines[0])
+
+	ref, _, _ := strings.Cut(lines[1], ":")
+	assert.Contains(t, h.Exec("show sess "+ref), "  server=haproxy (id=1) addr=209.126.35.1:443\n")
+	assert.Equal(t, "Session not found.\n\n", h.Exec("show sess 0x1"))
+
+	assert.Equal(t, "\n", h.Exec("shutdown session "+ref))
+	assert.Equal(t, "No such session (use 'show sess').\n\n", h.Exec("shutdown session "+ref))
+	assert.Equal(t, 0, h.backends[0].servers[0].scur)
+	assert.Equal(t, 2, h.frontends[0].scur)
+
+	assert.Equal(t, "\n", h.Exec("shutdown sessions server default/apache"))
+	assert.Equal(t, "\n", h.Exec("show sess"))
+	assert.Equal(t, "No such server.\n\n", h.Exec("shutdown sessions server default/unknown"))
+}
+
+func TestHumanTime(t *testing.T) {
+	assert.Equal(t, "0s", humanTime(0))
+	assert.Equal(t, "10s", humanTime(10*time.Second))
+	assert.Equal(t, "1m12s", humanTime(72*time.Second))
+	assert.Equal(t, "2h", humanTime(2*time.Hour+3*time.Second))
+	assert.Equal(t, "1d3h", humanTime(27*time.Hour+5*time.Minute))
+}
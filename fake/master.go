@@ -0,0 +1,177 @@
+package fake
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// masterHelpText mirrors the `help` output of a HAProxy 3.1 master cli
+const masterHelpText = `The following commands are valid at this level:
+  @!<pid>                                 : send a command to the <pid> process
+  @<relative pid>                         : send a command to the <relative pid> process
+  @master                                 : send a command to the master process
+  help [<command>]                        : list matching or all commands
+  prompt [timed]                          : toggle interactive mode with prompt
+  quit                                    : disconnect
+  reload                                  : achieve a soft-reload (-sf) of haproxy
+  show proc                               : show processes status
+  show startup-logs                       : report logs emitted during HAProxy startup
+`
+
+const startupLogs = "[NOTICE]   (1) : haproxy version is " + Version + "\n" +
+	"[NOTICE]   (1) : path to executable is /usr/local/sbin/haproxy\n"
+
+type process struct {
+	pid     int
+	reloads int
+	started time.Time
+	old     bool
+}
+
+// Master is the master cli of a master-worker HAProxy, all workers share the state of one HAProxy
+type Master struct {
+	HAProxy *HAProxy
+	// FailReload makes reloads fail, like an invalid configuration would
+	FailReload bool
+	mu         sync.Mutex
+	started    time.Time
+	nextPid    int
+	reloads    int
+	failed     int
+	workers    []*process
+}
+
+func NewMaster(h *HAProxy) *Master {
+	now := time.Now()
+
+	return &Master{
+		HAProxy: h,
+		started: now,
+		nextPid: 9,
+		workers: []*process{{pid: 8, started: now}},
+	}
+}
+
+// ExecSession runs a command line, routing `@<relative pid>`, `@!<pid>` and `@master` prefixed commands
+func (m *Master) ExecSession(s *Session, line string, payload string) string {
+	out := ""
+	for _, cmd := range splitCommands(line) {
+		words := strings.Fields(cmd)
+		if len(words) > 0 && strings.HasPrefix(words[0], "@") {
+			out += m.route(s, words[0], strings.Join(words[1:], " "), payload)
+			continue
+		}
+		out += m.exec(words)
+	}
+
+	return out
+}
+
+func (m *Master) route(s *Session, target string, cmd string, payload string) string {
+	if target == "@master" {
+		return m.exec(strings.Fields(cmd))
+	}
+
+	m.mu.Lock()
+	var worker *process
+	if pid, ok := strings.CutPrefix(target, "@!"); ok {
+		for _, w := range m.workers {
+			if strconv.Itoa(w.pid) == pid {
+				worker = w
+			}
+		}
+	} else if n, err := strconv.Atoi(target[1:]); err == nil {
+		current := m.current()
+		if n > 0 && n <= len(current) {
+			worker = current[n-1]
+		}
+	}
+	m.mu.Unlock()
+
+	if worker == nil {
+		return fmt.Sprintf("Can't find the target PID matching the prefix '%s'\n\n", target)
+	}
+
+	return m.HAProxy.ExecSession(s, cmd, payload)
+}
+
+func (m *Master) exec(words []string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch strings.Join(words, " ") {
+	case "":
+		return ""
+	case "help":
+		return terminate(masterHelpText)
+	case "show proc":
+		return terminate(m.showProc())
+	case "show startup-logs":
+		return terminate(startupLogs)
+	case "reload":
+		return terminate(m.reload())
+	}
+
+	return fmt.Sprintf("Unknown command: '%s'. Please enter one of the following commands only:\n\n", words[0])
+}
+
+func (m *Master) current() []*process {
+	var res []*process
+	for _, w := range m.workers {
+		if !w.old {
+			res = append(res, w)
+		}
+	}
+
+	return res
+}
+
+func (m *Master) showProc() string {
+	line := func(pid int, typ string, reloads string, started time.Time) string {
+		return fmt.Sprintf("%-15d %-15s %-15s %-15s %s\n", pid, typ, reloads, uptime(started), Version)
+	}
+
+	out := "#<PID>          <type>          <reloads>       <uptime>        <version>\n"
+	out += line(1, "master", fmt.Sprintf("%d [failed: %d]", m.reloads, m.failed), m.started)
+	out += "# workers\n"
+	for _, w := range m.workers {
+		if !w.old {
+			out += line(w.pid, "worker", strconv.Itoa(w.reloads), w.started)
+		}
+	}
+	out += "# old workers\n"
+	for _, w := range m.workers {
+		if w.old {
+			out += line(w.pid, "worker", strconv.Itoa(w.reloads), w.started)
+		}
+	}
+
+	return out + "# programs\n"
+}
+
+func (m *Master) reload() string {
+	if m.FailReload {
+		m.failed++
+		return "Success=0\n--\n" + startupLogs +
+			"[ALERT]    (1) : config : parsing [/usr/local/etc/haproxy/haproxy.cfg:12] : unknown keyword 'foo' in 'global' section\n"
+	}
+
+	for _, w := range m.workers {
+		w.old = true
+		w.reloads++
+	}
+	m.workers = append(m.workers, &process{pid: m.nextPid, started: time.Now()})
+	m.nextPid++
+	m.reloads++
+
+	return "Success=1\n--\n" + startupLogs
+}
+
+func uptime(since time.Time) string {
+	d := time.Since(since)
+
+	return fmt.Sprintf("%dd%02dh%02dm%02ds", int(d.Hours())/24, int(d.Hours())%24, int(d.Minutes())%60, int(d.Seconds())%60)
+}
@@ -0,0 +1,44 @@
+package fake
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestMasterHelp(t *testing.T) {
+	m := NewMaster(New())
+
+	assert.Equal(t, masterHelpText+"\n", m.ExecSession(NewSession(), "help", ""))
+	assert.Contains(t, m.ExecSession(NewSession(), "show servers state", ""), "Unknown command: 'show'")
+}
+
+func TestMasterRouting(t *testing.T) {
+	m := NewMaster(New())
+
+	assert.Equal(t, "admin\n\n", m.ExecSession(NewSession(), "@1 show cli level", ""))
+	assert.Equal(t, "admin\n\n", m.ExecSession(NewSession(), "@!8 show cli level", ""))
+	assert.Equal(t, masterHelpText+"\n", m.ExecSession(NewSession(), "@master help", ""))
+	assert.Equal(t, "Can't find the target PID matching the prefix '@!99'\n\n", m.ExecSession(NewSession(), "@!99 show info", ""))
+	assert.Equal(t, "foo\n\nbar\n\n", m.ExecSession(NewSession(), "@1 echo foo;@!8 echo bar", ""))
+}
+
+func TestMasterReload(t *testing.T) {
+	m := NewMaster(New())
+
+	assert.Equal(t, "Success=1\n--\n"+startupLogs+"\n", m.ExecSession(NewSession(), "reload", ""))
+
+	proc := m.ExecSession(NewSession(), "show proc", "")
+	workers, old, _ := strings.Cut(proc, "# old workers")
+	assert.Contains(t, workers, "master          1 [failed: 0]")
+	assert.Contains(t, workers, "9               worker          0")
+	assert.Contains(t, old, "8               worker          1")
+
+	// old workers are still reachable by pid, the relative pid points to the new generation
+	assert.Equal(t, "admin\n\n", m.ExecSession(NewSession(), "@!8 show cli level", ""))
+	assert.Equal(t, "Can't find the target PID matching the prefix '@2'\n\n", m.ExecSession(NewSession(), "@2 show cli level", ""))
+
+	m.FailReload = true
+	assert.Contains(t, m.ExecSession(NewSession(), "reload", ""), "Success=0\n--\n")
+	assert.Contains(t, m.ExecSession(NewSession(), "show proc", ""), "master          1 [failed: 1]")
+}
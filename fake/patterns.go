@@ -0,0 +1,306 @@
+package fake
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func (h *HAProxy) patternLists(isMap bool) []*patternList {
+	if isMap {
+		return h.maps
+	}
+
+	return h.acls
+}
+
+// lookupPatterns finds a map or acl by its file name or by `#<id>`
+func (h *HAProxy) lookupPatterns(isMap bool, name string) (*patternList, string) {
+	for _, p := range h.patternLists(isMap) {
+		if (p.file != "" && p.file == name) || "#"+strconv.Itoa(p.id) == name {
+			return p, ""
+		}
+	}
+
+	if isMap {
+		return nil, "Unknown map identifier. Please use #<id> or <file>."
+	}
+
+	return nil, "Unknown ACL identifier. Please use #<id> or <file>."
+}
+
+// parseVersion consumes an optional leading `@<ver>` argument
+func parseVersion(args []string) (int, bool, []string, string) {
+	if len(args) == 0 || !strings.HasPrefix(args[0], "@") {
+		return 0, false, args, ""
+	}
+
+	v, err := strconv.Atoi(args[0][1:])
+	if err != nil {
+		return 0, false, args, "Malformed version: " + args[0] + "."
+	}
+
+	return v, true, args[1:], ""
+}
+
+func (h *HAProxy) addPattern(p *patternList, version int, key, value string) {
+	p.entries = append(p.entries, &pattern{ref: h.nextRef(), version: version, key: key, value: value})
+}
+
+func showPatterns(isMap bool) handlerFunc {
+	return func(h *HAProxy, _ *Session, args []string, _ string) string {
+		v, versioned, args, err := parseVersion(args)
+		if err != "" {
+			return err
+		}
+
+		if len(args) == 0 {
+			out := "# id (file) description\n"
+			for _, p := range h.patternLists(isMap) {
+				out += fmt.Sprintf("%d (%s) %s. curr_ver=%d next_ver=%d entry_cnt=%d\n",
+					p.id, p.file, p.description, p.currVersion, p.nextVersion, len(p.current()))
+			}
+			return out
+		}
+
+		p, err := h.lookupPatterns(isMap, args[0])
+		if err != "" {
+			return err
+		}
+		if !versioned {
+			v = p.currVersion
+		}
+
+		out := ""
+		for _, e := range p.version(v) {
+			if isMap {
+				out += fmt.Sprintf("%s %s %s\n", e.ref, e.key, e.value)
+			} else {
+				out += fmt.Sprintf("%s %s\n", e.ref, e.key)
+			}
+		}
+
+		return out
+	}
+}
+
+func getPattern(isMap bool) handlerFunc {
+	return func(h *HAProxy, _ *Session, args []string, _ string) string {
+		if len(args) < 2 {
+			if isMap {
+				return "'get map' expects two parameters: map identifier and key."
+			}
+			return "'get acl' expects two parameters: ACL identifier and sample."
+		}
+
+		p, err := h.lookupPatterns(isMap, args[0])
+		if err != "" {
+			return err
+		}
+
+		value := args[1]
+		for _, e := range p.current() {
+			if !p.matches(e, value) {
+				continue
+			}
+			if isMap {
+				return fmt.Sprintf(`type=%s, case=sensitive, found=yes, idx=tree, key="%s", value="%s", type="str"`, p.match, e.key, e.value)
+			}
+			return fmt.Sprintf(`type=%s, case=sensitive, match=yes, idx=list, pattern="%s"`, p.match, e.key)
+		}
+
+		if isMap {
+			return fmt.Sprintf("type=%s, case=sensitive, found=no", p.match)
+		}
+
+		return fmt.Sprintf("type=%s, case=sensitive, match=no", p.match)
+	}
+}
+
+// addPatterns adds a single key (and value for maps) or every line of the payload
+func addPatterns(isMap bool) handlerFunc {
+	return func(h *HAProxy, _ *Session, args []string, payload string) string {
+		v, versioned, args, err := parseVersion(args)
+		if err != "" {
+			return err
+		}
+		if len(args) == 0 {
+			return "'add' expects a map or acl identifier."
+		}
+
+		p, err := h.lookupPatterns(isMap, args[0])
+		if err != "" {
+			return err
+		}
+		if !versioned {
+			v = p.currVersion
+		} else if v <= p.currVersion || v > p.nextVersion {
+			return fmt.Sprintf("Unknown version: %d.", v)
+		}
+
+		if len(args) > 1 {
+			// like HAProxy further words are ignored, spaces within a key or value are escaped
+			if isMap && len(args) < 3 {
+				return "'add map' expects three parameters: map identifier, key and value."
+			}
+			value := ""
+			if isMap {
+				value = args[2]
+			}
+			h.addPattern(p, v, args[1], value)
+			return ""
+		}
+
+		for _, l := range strings.Split(strings.TrimSpace(payload), "\n") {
+			l = strings.TrimSpace(l)
+			if l == "" {
+				continue
+			}
+			// a map entry is the key and the rest of the line, an acl pattern is the whole line
+			key, value := l, ""
+			if isMap {
+				key, value, _ = strings.Cut(l, " ")
+				if value == "" {
+					return "'add map' expects three parameters: map identifier, key and value."
+				}
+			}
+			h.addPattern(p, v, key, strings.TrimSpace(value))
+		}
+
+		return ""
+	}
+}
+
+func (h *HAProxy) setMap(_ *Session, args []string, _ string) string {
+	if len(args) < 3 {
+		return "'set map' expects three parameters: map identifier, key and value."
+	}
+
+	p, err := h.lookupPatterns(true, args[0])
+	if err != "" {
+		return err
+	}
+
+	found := false
+	for _, e := range p.current() {
+		if e.key == args[1] || "#"+e.ref == args[1] {
+			e.value = args[2]
+			found = true
+		}
+	}
+	if !found {
+		return "Key not found."
+	}
+
+	return ""
+}
+
+func delPattern(isMap bool) handlerFunc {
+	return func(h *HAProxy, _ *Session, args []string, _ string) string {
+		if len(args) < 2 {
+			return "This command expects two parameters: map identifier and key."
+		}
+
+		p, err := h.lookupPatterns(isMap, args[0])
+		if err != "" {
+			return err
+		}
+
+		found := false
+		var kept []*pattern
+		for _, e := range p.entries {
+			if e.version == p.currVersion && (e.key == args[1] || "#"+e.ref == args[1]) {
+				found = true
+				continue
+			}
+			kept = append(kept, e)
+		}
+		if !found {
+			return "Key not found."
+		}
+		p.entries = kept
+
+		return ""
+	}
+}
+
+func clearPatterns(isMap bool) handlerFunc {
+	return func(h *HAProxy, _ *Session, args []string, _ string) string {
+		v, versioned, args, err := parseVersion(args)
+		if err != "" {
+			return err
+		}
+		if len(args) == 0 {
+			return "Missing map identifier."
+		}
+
+		p, err := h.lookupPatterns(isMap, args[0])
+		if err != "" {
+			return err
+		}
+		if !versioned {
+			v = p.currVersion
+		}
+
+		var kept []*pattern
+		for _, e := range p.entries {
+			if e.version != v {
+				kept = append(kept, e)
+			}
+		}
+		p.entries = kept
+
+		return ""
+	}
+}
+
+func preparePatterns(isMap bool) handlerFunc {
+	return func(h *HAProxy, _ *Session, args []string, _ string) string {
+		if len(args) == 0 {
+			return "Missing map identifier."
+		}
+
+		p, err := h.lookupPatterns(isMap, args[0])
+		if err != "" {
+			return err
+		}
+
+		if p.nextVersion <= p.currVersion {
+			p.nextVersion = p.currVersion
+		}
+		p.nextVersion++
+
+		return fmt.Sprintf("New version created: %d", p.nextVersion)
+	}
+}
+
+func commitPatterns(isMap bool) handlerFunc {
+	return func(h *HAProxy, _ *Session, args []string, _ string) string {
+		v, versioned, args, err := parseVersion(args)
+		if err != "" {
+			return err
+		}
+		if !versioned || len(args) == 0 {
+			return "'commit' expects a version number and a map identifier."
+		}
+
+		p, err := h.lookupPatterns(isMap, args[0])
+		if err != "" {
+			return err
+		}
+		if v <= p.currVersion || v > p.nextVersion {
+			return fmt.Sprintf("Unknown version: %d.", v)
+		}
+
+		var kept []*pattern
+		for _, e := range p.entries {
+			if e.version >= v {
+				kept = append(kept, e)
+			}
+		}
+		p.entries = kept
+		p.currVersion = v
+
+		return ""
+	}
+}
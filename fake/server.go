@@ -0,0 +1,228 @@
+package fake
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bufSize is the default tune.bufsize of HAProxy, a command line with its payload has to fit in
+const bufSize = 16384
+
+// Server exposes a HAProxy runtime api on a listening socket
+type Server struct {
+	HAProxy *HAProxy
+	// Master is set if the server is the master cli of a master-worker HAProxy
+	Master *Master
+	// Addr is the address of the socket, either unix:///path/to.sock or tcp://host:port
+	Addr     string
+	listener net.Listener
+	dir      string
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	conns    map[net.Conn]struct{}
+	timeout  time.Duration
+}
+
+// NewServer starts a fake HAProxy with demo state on a unix socket in a temporary directory
+func NewServer() (*Server, error) {
+	return newUnixServer(false)
+}
+
+// NewMasterServer starts the master cli of a fake master-worker HAProxy on a unix socket in a temporary directory
+func NewMasterServer() (*Server, error) {
+	return newUnixServer(true)
+}
+
+func newUnixServer(master bool) (*Server, error) {
+	dir, err := os.MkdirTemp("", "haproxy-runtime-cli")
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, "haproxy.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+
+	s := start(l, "unix://"+path)
+	s.dir = dir
+	if master {
+		s.Master = NewMaster(s.HAProxy)
+	}
+
+	return s, nil
+}
+
+// NewTCPServer starts a fake HAProxy with demo state on a local tcp port, tls terminated if a config is given
+func NewTCPServer(config *tls.Config) (*Server, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	if config != nil {
+		l = tls.NewListener(l, config)
+	}
+
+	return start(l, "tcp://"+l.Addr().String()), nil
+}
+
+func start(l net.Listener, addr string) *Server {
+	s := &Server{
+		HAProxy:  New(),
+		Addr:     addr,
+		listener: l,
+		conns:    map[net.Conn]struct{}{},
+	}
+	s.wg.Add(1)
+	go s.serve()
+
+	return s
+}
+
+// Close stops accepting connections, disconnects all clients and removes the socket
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	for c := range s.conns {
+		_ = c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	if s.dir != "" {
+		_ = os.RemoveAll(s.dir)
+	}
+
+	return err
+}
+
+// SetTimeout closes idle connections in interactive mode after the given duration, like `stats timeout`
+func (s *Server) SetTimeout(timeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.timeout = timeout
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		} else if err != nil {
+			continue
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// handle answers a single command line, like HAProxy does in non-interactive mode. After `prompt`
+// the connection is kept open and each response is followed by the prompt, until `quit` or the timeout.
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	session := NewSession()
+	reader := bufio.NewReader(conn)
+	interactive := false
+	for {
+		s.mu.Lock()
+		if s.timeout > 0 {
+			_ = conn.SetReadDeadline(time.Now().Add(s.timeout))
+		}
+		s.mu.Unlock()
+
+		line, payload, payloadLines, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		out := ""
+		switch {
+		case strings.TrimSpace(line) == "quit":
+			return
+		case strings.TrimSpace(line) == "prompt":
+			interactive = !interactive
+		case len(line)+len(payload) >= bufSize:
+			out = "The command is too big for the buffer size. Please change tune.bufsize in the configuration to use a bigger command.\n\n"
+		default:
+			out = s.exec(session, line, payload)
+		}
+
+		if !interactive {
+			_, _ = conn.Write([]byte(out))
+			return
+		}
+
+		// while a payload is read HAProxy prompts for each line, after the command line and every payload line
+		if payloadLines >= 0 {
+			out = strings.Repeat("+ ", payloadLines+1) + out
+		}
+		_, _ = conn.Write([]byte(out + s.prompt()))
+	}
+}
+
+func (s *Server) prompt() string {
+	if s.Master != nil {
+		return "master> "
+	}
+
+	return "> "
+}
+
+func (s *Server) exec(session *Session, line string, payload string) string {
+	if s.Master != nil {
+		return s.Master.ExecSession(session, line, payload)
+	}
+
+	return s.HAProxy.ExecSession(session, line, payload)
+}
+
+// readCommand reads a command line and, if it ends with `<<`, the payload up to the next empty line.
+// It returns the number of lines of the payload, -1 if the command has none.
+func readCommand(r *bufio.Reader) (string, string, int, error) {
+	line, err := r.ReadString('\n')
+	if err != nil && line == "" {
+		return "", "", -1, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+
+	if !strings.HasSuffix(line, "<<") {
+		return line, "", -1, nil
+	}
+
+	payload := ""
+	lines := 0
+	for {
+		l, err := r.ReadString('\n')
+		if strings.TrimRight(l, "\r\n") == "" || err != nil {
+			break
+		}
+		payload += l
+		lines++
+	}
+
+	return strings.TrimSpace(strings.TrimSuffix(line, "<<")), payload, lines, nil
+}
@@ -0,0 +1,369 @@
+package fake
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const serversStateHeader = "# be_id be_name srv_id srv_name srv_addr srv_op_state srv_admin_state srv_uweight srv_iweight srv_time_since_last_change srv_check_status srv_check_result srv_check_health srv_check_state srv_agent_state bk_f_forced_id srv_f_forced_id srv_fqdn srv_port srvrecord srv_use_ssl srv_check_port srv_check_addr srv_agent_addr srv_agent_port\n"
+
+func (h *HAProxy) backend(name string) *backend {
+	for _, b := range h.backends {
+		if b.name == name {
+			return b
+		}
+	}
+
+	return nil
+}
+
+func (h *HAProxy) frontend(name string) *frontend {
+	for _, f := range h.frontends {
+		if f.name == name {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// server resolves a <backend>/<server> argument, returning the HAProxy error message if it can't
+func (h *HAProxy) server(arg string) (*backend, *server, string) {
+	bk, srv, ok := strings.Cut(arg, "/")
+	if !ok {
+		return nil, nil, "Require 'backend/server'."
+	}
+
+	b := h.backend(bk)
+	if b == nil {
+		return nil, nil, "No such backend."
+	}
+
+	for _, s := range b.servers {
+		if s.name == srv {
+			return b, s, ""
+		}
+	}
+
+	return nil, nil, "No such server."
+}
+
+func (h *HAProxy) showBackend(_ *Session, _ []string, _ string) string {
+	out := "# name\n"
+	for _, b := range h.backends {
+		out += b.name + "\n"
+	}
+
+	return out
+}
+
+func (h *HAProxy) showServersState(_ *Session, args []string, _ string) string {
+	backends := h.backends
+	if len(args) > 0 {
+		b := h.backend(args[0])
+		if b == nil {
+			return "Can't find backend.\n"
+		}
+		backends = []*backend{b}
+	}
+
+	out := "1\n" + serversStateHeader
+	for _, b := range backends {
+		for _, s := range b.servers {
+			out += fmt.Sprintf("%d %s %d %s %s %d %d %d %d %d %d %d %d %d %d 0 0 %s %d - %d %d %s %s %d\n",
+				b.id, b.name, s.id, s.name, orDash(s.addr), s.opState, s.adminState, s.uweight, s.iweight,
+				int(time.Since(s.lastChange).Seconds()), s.checkStatus, s.checkResult, s.checkHealth, s.checkState, s.agentState,
+				orDash(s.fqdn), s.port, boolToInt(s.useSSL), s.checkPort, orDash(s.checkAddr), orDash(s.agentAddr), s.agentPort,
+			)
+		}
+	}
+
+	return out
+}
+
+func (h *HAProxy) getWeight(_ *Session, args []string, _ string) string {
+	if len(args) == 0 {
+		return "Require 'backend/server'."
+	}
+	_, s, err := h.server(args[0])
+	if err != "" {
+		return err
+	}
+
+	return fmt.Sprintf("%d (initial %d)", s.uweight, s.iweight)
+}
+
+func (h *HAProxy) setServer(_ *Session, args []string, _ string) string {
+	const usage = "usage: set server <backend>/<server> addr | agent | agent-addr | agent-port | agent-send | check-addr | check-port | fqdn | health | ssl | state | weight"
+
+	if len(args) == 0 {
+		return "Require 'backend/server'."
+	}
+	_, s, err := h.server(args[0])
+	if err != "" {
+		return err
+	}
+	if len(args) < 3 {
+		return usage
+	}
+
+	value := args[2]
+	switch args[1] {
+	case "state":
+		return h.setServerState(s, value)
+	case "weight":
+		w, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+		if err != nil || w < 0 || w > 256 {
+			return "Integer value is expected (between 0 and 256)."
+		}
+		if strings.HasSuffix(value, "%") {
+			w = s.iweight * w / 100
+		}
+		s.uweight = w
+	case "health":
+		switch value {
+		case "up":
+			s.checkHealth, s.checkResult = 4, 3
+			s.changeOpState(opRunning)
+		case "stopping":
+			s.changeOpState(opStopping)
+		case "down":
+			s.checkHealth, s.checkResult = 0, 2
+			s.changeOpState(opStopped)
+		default:
+			return "'set server <srv> health' expects 'up', 'stopping', or 'down'."
+		}
+	case "agent":
+		switch value {
+		case "up":
+			s.agentState |= checkAgent | checkConfigured
+		case "down":
+			s.agentState &^= checkEnabled
+		default:
+			return "'set server <srv> agent' expects 'up' or 'down'."
+		}
+	case "addr":
+		if net.ParseIP(value) == nil {
+			return "Invalid addr."
+		}
+		old, oldPort := s.addr, s.port
+		s.addr = value
+		out := fmt.Sprintf("IP changed from '%s' to '%s'", orDash(old), value)
+		if len(args) >= 5 && args[3] == "port" {
+			p, err := strconv.Atoi(args[4])
+			if err != nil {
+				return "Invalid port."
+			}
+			s.port = p
+			out += fmt.Sprintf(", port changed from '%d' to '%d'", oldPort, p)
+		} else {
+			out += ", no need to change the port"
+		}
+		return out + " by 'stats socket command'"
+	case "port":
+		p, err := strconv.Atoi(value)
+		if err != nil {
+			return "Invalid port."
+		}
+		old := s.port
+		s.port = p
+		return fmt.Sprintf("port changed from '%d' to '%d' by 'stats socket command'", old, p)
+	case "check-port":
+		p, err := strconv.Atoi(value)
+		if err != nil {
+			return "'set server <srv> check-port' expects an integer as argument."
+		}
+		s.checkPort = p
+		return "health check port updated."
+	case "check-addr":
+		s.checkAddr = value
+		return "health check addr updated."
+	case "agent-addr":
+		s.agentAddr = value
+		return "agent addr updated."
+	case "agent-port":
+		p, err := strconv.Atoi(value)
+		if err != nil {
+			return "'set server <srv> agent-port' expects an integer as argument."
+		}
+		s.agentPort = p
+		return "agent port updated."
+	case "fqdn":
+		old := s.fqdn
+		s.fqdn = value
+		return fmt.Sprintf("%s changed its FQDN from %s to %s", args[0], orDash(old), value)
+	case "ssl":
+		switch value {
+		case "on":
+			s.useSSL = true
+		case "off":
+			s.useSSL = false
+		default:
+			return "'set server <srv> ssl' expects 'on' or 'off'."
+		}
+		return "server ssl setting updated."
+	default:
+		return usage
+	}
+
+	return ""
+}
+
+func (h *HAProxy) setServerState(s *server, value string) string {
+	switch value {
+	case "ready":
+		s.adminState &^= adminForcedMaint | adminForcedDrain
+		s.changeOpState(opRunning)
+	case "drain":
+		s.adminState = (s.adminState &^ adminForcedMaint) | adminForcedDrain
+		s.changeOpState(opRunning)
+	case "maint":
+		s.adminState = (s.adminState &^ adminForcedDrain) | adminForcedMaint
+		s.changeOpState(opStopped)
+	default:
+		return "'set server <srv> state' expects 'ready', 'drain' and 'maint'."
+	}
+
+	return ""
+}
+
+func (s *server) changeOpState(state int) {
+	if s.opState != state {
+		s.opState = state
+		s.lastChange = time.Now()
+	}
+}
+
+func (h *HAProxy) setMaxconnServer(_ *Session, args []string, _ string) string {
+	if len(args) < 2 {
+		return "Require <backend>/<server> and <maxconn>."
+	}
+	_, s, err := h.server(args[0])
+	if err != "" {
+		return err
+	}
+	v, e := strconv.Atoi(args[1])
+	if e != nil || v < 0 {
+		return "Integer value is expected."
+	}
+	s.maxconn = v
+
+	return ""
+}
+
+func toggleCheck(agent bool, enable bool) handlerFunc {
+	return func(h *HAProxy, _ *Session, args []string, _ string) string {
+		if len(args) == 0 {
+			return "Require 'backend/server'."
+		}
+		_, s, err := h.server(args[0])
+		if err != "" {
+			return err
+		}
+
+		state := &s.checkState
+		if agent {
+			state = &s.agentState
+			if *state&checkConfigured == 0 {
+				return "Agent was not configured on this server, cannot enable."
+			}
+		} else if *state&checkConfigured == 0 {
+			return "Health checks are not configured on this server, cannot enable."
+		}
+
+		if enable {
+			*state |= checkEnabled
+		} else {
+			*state &^= checkEnabled
+		}
+
+		return ""
+	}
+}
+
+func (h *HAProxy) enableFrontend(_ *Session, args []string, _ string) string {
+	f, err := h.lookupFrontend(args)
+	if err != "" {
+		return err
+	}
+	if f.state == "OPEN" {
+		return "Frontend is already enabled."
+	}
+	f.state = "OPEN"
+
+	return ""
+}
+
+func (h *HAProxy) disableFrontend(_ *Session, args []string, _ string) string {
+	f, err := h.lookupFrontend(args)
+	if err != "" {
+		return err
+	}
+	if f.state == "PAUSED" {
+		return "Frontend is already disabled."
+	}
+	f.state = "PAUSED"
+
+	return ""
+}
+
+func (h *HAProxy) shutdownFrontend(_ *Session, args []string, _ string) string {
+	f, err := h.lookupFrontend(args)
+	if err != "" {
+		return err
+	}
+	f.state = "STOP"
+
+	return fmt.Sprintf("Frontend %s was stopped.", f.name)
+}
+
+func (h *HAProxy) setMaxconnFrontend(_ *Session, args []string, _ string) string {
+	f, err := h.lookupFrontend(args)
+	if err != "" {
+		return err
+	}
+	if len(args) < 2 {
+		return "Integer value is expected."
+	}
+	v, e := strconv.Atoi(args[1])
+	if e != nil || v < 0 {
+		return "Integer value is expected."
+	}
+	f.maxconn = v
+
+	return ""
+}
+
+func (h *HAProxy) lookupFrontend(args []string) (*frontend, string) {
+	if len(args) == 0 {
+		return nil, "A frontend name is expected."
+	}
+	f := h.frontend(args[0])
+	if f == nil {
+		return nil, "No such frontend."
+	}
+	if f.state == "STOP" {
+		return nil, "Frontend was already shut down."
+	}
+
+	return f, ""
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
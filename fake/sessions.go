@@ -0,0 +1,115 @@
+package fake
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// showSess lists the streams, or dumps the details of the one whose pointer is given
+func (h *HAProxy) showSess(_ *Session, args []string, _ string) string {
+	if len(args) > 0 && args[0] != "all" {
+		st := h.stream(args[0])
+		if st == nil {
+			return "Session not found."
+		}
+		return st.details()
+	}
+
+	out := ""
+	for _, st := range h.streams {
+		out += st.line()
+	}
+
+	return out
+}
+
+func (h *HAProxy) shutdownSession(_ *Session, args []string, _ string) string {
+	if len(args) == 0 {
+		return "Session pointer expected (use 'show sess')."
+	}
+
+	st := h.stream(args[0])
+	if st == nil {
+		return "No such session (use 'show sess')."
+	}
+	h.kill(st)
+
+	return ""
+}
+
+func (h *HAProxy) shutdownSessionsServer(_ *Session, args []string, _ string) string {
+	if len(args) == 0 {
+		return "Require 'backend/server'."
+	}
+
+	_, srv, err := h.server(args[0])
+	if err != "" {
+		return err
+	}
+	for _, st := range slices.Clone(h.streams) {
+		if st.server == srv {
+			h.kill(st)
+		}
+	}
+
+	return ""
+}
+
+func (h *HAProxy) stream(ref string) *stream {
+	for _, st := range h.streams {
+		if st.ref == ref {
+			return st
+		}
+	}
+
+	return nil
+}
+
+// kill closes a stream, which is no longer counted as current session of its frontend and server
+func (h *HAProxy) kill(st *stream) {
+	h.streams = slices.DeleteFunc(h.streams, func(s *stream) bool { return s == st })
+	st.frontend.scur = max(st.frontend.scur-1, 0)
+	st.server.scur = max(st.server.scur-1, 0)
+}
+
+func (st *stream) line() string {
+	return fmt.Sprintf("%s: proto=tcpv4 src=%s fe=%s be=%s srv=%s ts=00 epoch=0 age=%s calls=4 rate=0 cpu=0 lat=0 "+
+		"rq[f=%xh,i=0,an=00h,ax=] rp[f=%xh,i=0,an=00h,ax=] scf=[8,200h,fd=23,rex=1m,wex=] scb=[8,1h,fd=24,rex=,wex=] exp=1m rc=0 c_exp= uniq_id=%d\n",
+		st.ref, st.src, st.frontend.name, st.backend.name, st.server.name, humanTime(time.Since(st.started)), st.reqFlags, st.resFlags, st.uniqueID)
+}
+
+func (st *stream) details() string {
+	return fmt.Sprintf("%s: [%s] id=%d proto=tcpv4 source=%s\n", st.ref, st.started.Format("02/Jan/2006:15:04:05.000000"), st.uniqueID, st.src) +
+		"  flags=0x1ce, conn_retries=0, conn_exp=<NEVER> conn_et=0x000 srv_conn=" + st.ref + ", pend_pos=(nil) waiting=0 sigs=0\n" +
+		fmt.Sprintf("  frontend=%s (id=%d mode=http), listener=? (id=1) addr=%s\n", st.frontend.name, st.frontend.id, bindAddr(st.frontend.binds[0])) +
+		fmt.Sprintf("  backend=%s (id=%d mode=http) addr=10.0.0.10:41234\n", st.backend.name, st.backend.id) +
+		fmt.Sprintf("  server=%s (id=%d) addr=%s:%d\n", st.server.name, st.server.id, st.server.addr, st.server.port) +
+		fmt.Sprintf("  task=%s (state=0x00 nice=0 calls=4 rate=0 exp=1m tid=1(1/1) age=%s)\n", st.ref, humanTime(time.Since(st.started))) +
+		fmt.Sprintf("  req=%s (f=%#x an=0x0 tofwd=-1 total=%d)\n", st.ref, st.reqFlags, st.reqTotal) +
+		"      an_exp=<NEVER> buf=0x0 data=(nil) o=0 p=0 i=0 size=0\n" +
+		fmt.Sprintf("  res=%s (f=%#x an=0x0 tofwd=-1 total=%d)\n", st.ref, st.resFlags, st.resTotal) +
+		"      an_exp=<NEVER> buf=0x0 data=(nil) o=0 p=0 i=0 size=0\n"
+}
+
+// humanTime formats a duration like HAProxy does for ages, with the two largest units, e.g. `1m12s` or `1d3h`
+func humanTime(d time.Duration) string {
+	units := []struct {
+		name    string
+		seconds int
+	}{{"d", 86400}, {"h", 3600}, {"m", 60}, {"s", 1}}
+
+	t := int(d.Seconds())
+	for i, u := range units {
+		if t < u.seconds && u.name != "s" {
+			continue
+		}
+		out := fmt.Sprintf("%d%s", t/u.seconds, u.name)
+		if i+1 < len(units) && t%u.seconds >= units[i+1].seconds {
+			out += fmt.Sprintf("%d%s", t%u.seconds/units[i+1].seconds, units[i+1].name)
+		}
+		return out
+	}
+
+	return "0s"
+}
@@ -0,0 +1,283 @@
+package fake
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// certFile is a certificate file loaded by HAProxy, the leaf certificate followed by its chain
+type certFile struct {
+	name  string
+	certs []*x509.Certificate
+}
+
+// showSSLCert lists the certificate files and the one of the ongoing transaction, or the details of a
+// file, `*<file>` being the uncommitted one
+func (h *HAProxy) showSSLCert(_ *Session, args []string, _ string) string {
+	if len(args) > 0 {
+		if t := h.certTransaction; t != nil && args[0] == "*"+t.name {
+			return t.details("Unused")
+		}
+		if c := h.certFile(args[0]); c != nil {
+			return c.details("Used")
+		}
+		return "Can't display the certificate: Not found or the certificate is a bundle!"
+	}
+
+	out := ""
+	if h.certTransaction != nil {
+		out += "# transaction\n*" + h.certTransaction.name + "\n"
+	}
+	out += "# filename\n"
+	for _, c := range h.certs {
+		out += c.name + "\n"
+	}
+
+	return out
+}
+
+// setSSLCert starts or updates the transaction replacing a certificate file with the PEM payload
+func (h *HAProxy) setSSLCert(_ *Session, args []string, payload string) string {
+	if len(args) == 0 || payload == "" {
+		return "'set ssl cert' expects a filename and a certificate as a payload"
+	}
+	if h.certFile(args[0]) == nil {
+		return "Can't replace a certificate which is not referenced by the configuration!"
+	}
+	if t := h.certTransaction; t != nil && t.name != args[0] {
+		return fmt.Sprintf("The ongoing transaction is about '%s' but you are trying to set '%s'", t.name, args[0])
+	}
+
+	certs, err := parsePEM(payload)
+	if err != nil {
+		return fmt.Sprintf("Can't load the payload for '%s': %s", args[0], err)
+	}
+
+	action := "created"
+	if h.certTransaction != nil {
+		action = "updated"
+	}
+	h.certTransaction = &certFile{name: args[0], certs: certs}
+
+	return fmt.Sprintf("Transaction %s for certificate %s!", action, args[0])
+}
+
+func (h *HAProxy) commitSSLCert(_ *Session, args []string, _ string) string {
+	t, err := h.ongoingCertTransaction("commit", args)
+	if err != "" {
+		return err
+	}
+	h.certFile(t.name).certs = t.certs
+	h.certTransaction = nil
+
+	return fmt.Sprintf("Committing %s.\nSuccess!", t.name)
+}
+
+func (h *HAProxy) abortSSLCert(_ *Session, args []string, _ string) string {
+	t, err := h.ongoingCertTransaction("abort", args)
+	if err != "" {
+		return err
+	}
+	h.certTransaction = nil
+
+	return fmt.Sprintf("Transaction aborted for certificate '%s'!", t.name)
+}
+
+func (h *HAProxy) ongoingCertTransaction(action string, args []string) (*certFile, string) {
+	if len(args) == 0 {
+		return nil, fmt.Sprintf("'%s ssl cert' expects a filename", action)
+	}
+	t := h.certTransaction
+	if t == nil {
+		return nil, "No ongoing transaction!"
+	}
+	if t.name != args[0] {
+		return nil, fmt.Sprintf("The ongoing transaction is about '%s' but you are trying to set '%s'", t.name, args[0])
+	}
+
+	return t, ""
+}
+
+func (h *HAProxy) certFile(name string) *certFile {
+	for _, c := range h.certs {
+		if c.name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// details are formatted like the ones of HAProxy, dates and names the way OpenSSL prints them
+func (c *certFile) details(status string) string {
+	leaf := c.certs[0]
+	fingerprint := sha1.Sum(leaf.Raw)
+
+	var sans []string
+	for _, n := range leaf.DNSNames {
+		sans = append(sans, "DNS:"+n)
+	}
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, "IP Address:"+ip.String())
+	}
+
+	out := "Filename: " + c.name + "\n" +
+		"Status: " + status + "\n" +
+		fmt.Sprintf("Serial: %X\n", leaf.SerialNumber) +
+		"notBefore: " + leaf.NotBefore.UTC().Format("Jan _2 15:04:05 2006 GMT") + "\n" +
+		"notAfter: " + leaf.NotAfter.UTC().Format("Jan _2 15:04:05 2006 GMT") + "\n"
+	if len(sans) > 0 {
+		out += "Subject Alternative Name: " + strings.Join(sans, ", ") + "\n"
+	}
+	out += "Algorithm: " + algorithm(leaf) + "\n" +
+		fmt.Sprintf("SHA1 FingerPrint: %X\n", fingerprint) +
+		"Subject: " + distinguishedName(leaf.Subject) + "\n" +
+		"Issuer: " + distinguishedName(leaf.Issuer) + "\n"
+	for _, chain := range c.certs[1:] {
+		out += "Chain Subject: " + distinguishedName(chain.Subject) + "\n" +
+			"Chain Issuer: " + distinguishedName(chain.Issuer) + "\n"
+	}
+
+	return out
+}
+
+func algorithm(c *x509.Certificate) string {
+	switch k := c.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		return fmt.Sprintf("EC%d", k.Curve.Params().BitSize)
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA%d", k.N.BitLen())
+	}
+
+	return c.PublicKeyAlgorithm.String()
+}
+
+// distinguishedName formats a name like OpenSSL's one line format, e.g. `/C=US/O=Example/CN=example.com`
+func distinguishedName(n pkix.Name) string {
+	out := ""
+	for _, c := range n.Country {
+		out += "/C=" + c
+	}
+	for _, o := range n.Organization {
+		out += "/O=" + o
+	}
+	if n.CommonName != "" {
+		out += "/CN=" + n.CommonName
+	}
+
+	return out
+}
+
+// parsePEM parses the certificates of a payload, private keys are accepted but not checked
+func parsePEM(payload string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := []byte(payload)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, c)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate found")
+	}
+
+	return certs, nil
+}
+
+// demoCerts creates the certificate files of the demo state, issued by a fake CA
+func demoCerts(now time.Time) []*certFile {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Country: []string{"US"}, Organization: []string{"Fake CA"}, CommonName: "Fake CA R1"},
+		NotBefore:             now.Add(-365 * 24 * time.Hour),
+		NotAfter:              now.Add(5 * 365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caCert := demoCert(ca, ca, caKey)
+
+	site := demoCert(&x509.Certificate{
+		SerialNumber: big.NewInt(0x0d933c1b),
+		Subject:      pkix.Name{Organization: []string{"Example"}, CommonName: "example.com"},
+		DNSNames:     []string{"example.com", "www.example.com"},
+		NotBefore:    now.Add(-10 * 24 * time.Hour),
+		NotAfter:     now.Add(80 * 24 * time.Hour),
+	}, caCert, caKey)
+	// the api certificate is about to expire
+	api := demoCert(&x509.Certificate{
+		SerialNumber: big.NewInt(0x1a2b3c4d),
+		Subject:      pkix.Name{Organization: []string{"Example"}, CommonName: "api.example.com"},
+		DNSNames:     []string{"api.example.com"},
+		NotBefore:    now.Add(-80 * 24 * time.Hour),
+		NotAfter:     now.Add(10 * 24 * time.Hour),
+	}, caCert, caKey)
+
+	return []*certFile{
+		{name: "/usr/local/etc/haproxy/certs/site.pem", certs: []*x509.Certificate{site, caCert}},
+		{name: "/usr/local/etc/haproxy/certs/api.pem", certs: []*x509.Certificate{api, caCert}},
+	}
+}
+
+// demoCert signs a certificate with a fresh key, the parent and its key are the issuer
+func demoCert(template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) *x509.Certificate {
+	key := parentKey
+	if template != parent {
+		key, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		panic(err)
+	}
+	c, err := x509.ParseCertificate(der)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// CertPEM creates a self-signed certificate for the names with its key, in the PEM format of the
+// files uploaded with `set ssl cert`
+func CertPEM(names []string, notAfter time.Time) []byte {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(notAfter.Unix()),
+		Subject:      pkix.Name{CommonName: names[0]},
+		DNSNames:     names,
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		panic(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})...)
+}
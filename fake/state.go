@@ -0,0 +1,317 @@
+package fake
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// admin state bits of srv_admin_state
+const (
+	adminForcedMaint = 0x01
+	adminForcedDrain = 0x08
+)
+
+// check state bits of srv_check_state and srv_agent_state
+const (
+	checkConfigured = 0x02
+	checkEnabled    = 0x04
+	checkPaused     = 0x08
+	checkAgent      = 0x10
+)
+
+// operational state values of srv_op_state
+const (
+	opStopped  = 0
+	opStarting = 1
+	opRunning  = 2
+	opStopping = 3
+)
+
+type server struct {
+	id          int
+	name        string
+	addr        string
+	port        int
+	fqdn        string
+	opState     int
+	adminState  int
+	uweight     int
+	iweight     int
+	lastChange  time.Time
+	checkStatus int
+	checkResult int
+	checkHealth int
+	checkState  int
+	agentState  int
+	useSSL      bool
+	checkPort   int
+	checkAddr   string
+	agentAddr   string
+	agentPort   int
+	maxconn     int
+	counters
+}
+
+type backend struct {
+	id      int
+	name    string
+	servers []*server
+}
+
+type frontend struct {
+	id      int
+	name    string
+	binds   []string
+	state   string
+	maxconn int
+	counters
+}
+
+// counters are the traffic numbers reported by `show stat`
+type counters struct {
+	scur    int
+	smax    int
+	stot    int
+	bin     int
+	bout    int
+	reqRate int
+	reqTot  int
+	dreq    int
+	ereq    int
+	econ    int
+	eresp   int
+}
+
+type pattern struct {
+	ref     string
+	version int
+	key     string
+	value   string
+}
+
+// patternList is the shared storage of maps and acls, both support versioned
+// atomic replacement through prepare/commit
+type patternList struct {
+	id          int
+	file        string
+	description string
+	match       string
+	currVersion int
+	nextVersion int
+	entries     []*pattern
+}
+
+type tableEntry struct {
+	ref  string
+	key  string
+	exp  int
+	data map[string]int
+}
+
+type stickTable struct {
+	name    string
+	typ     string
+	size    int
+	columns []string
+	entries []*tableEntry
+}
+
+// stream is a client connection as listed by `show sess`
+type stream struct {
+	ref      string
+	uniqueID int
+	src      string
+	frontend *frontend
+	backend  *backend
+	server   *server
+	started  time.Time
+	reqFlags int
+	resFlags int
+	reqTotal int
+	resTotal int
+}
+
+func (p *patternList) current() []*pattern {
+	return p.version(p.currVersion)
+}
+
+func (p *patternList) version(v int) []*pattern {
+	var res []*pattern
+	for _, e := range p.entries {
+		if e.version == v {
+			res = append(res, e)
+		}
+	}
+
+	return res
+}
+
+func (p *patternList) matches(s *pattern, value string) bool {
+	switch p.match {
+	case "beg":
+		return strings.HasPrefix(value, s.key)
+	case "sub":
+		return strings.Contains(value, s.key)
+	default:
+		return value == s.key
+	}
+}
+
+// column returns the name of a stick table data column without its period, e.g. http_req_rate
+func column(c string) string {
+	if i := strings.Index(c, "("); i > 0 {
+		return c[:i]
+	}
+
+	return c
+}
+
+func (t *stickTable) column(name string) (string, bool) {
+	for _, c := range t.columns {
+		if column(c) == name {
+			return c, true
+		}
+	}
+
+	return "", false
+}
+
+func (t *stickTable) find(key string) *tableEntry {
+	for _, e := range t.entries {
+		if e.key == key {
+			return e
+		}
+	}
+
+	return nil
+}
+
+func (t *stickTable) header() string {
+	return fmt.Sprintf("# table: %s, type: %s, size:%d, used:%d\n", t.name, t.typ, t.size, len(t.entries))
+}
+
+func (t *stickTable) line(e *tableEntry) string {
+	out := fmt.Sprintf("%s: key=%s use=0 exp=%d shard=0", e.ref, e.key, e.exp)
+	for _, c := range t.columns {
+		out += " " + c + "=" + strconv.Itoa(e.data[column(c)])
+	}
+
+	return out + "\n"
+}
+
+// demoState is a small but realistic runtime state modelled after docker/haproxy.cfg
+func (h *HAProxy) demoState() {
+	now := time.Now()
+
+	h.backends = []*backend{
+		{id: 4, name: "default", servers: []*server{
+			newServer(1, "haproxy", "209.126.35.1", "haproxy.com", 20, now.Add(-9*time.Second)),
+			newServer(2, "apache", "151.101.2.132", "apache.org", 80, now.Add(-9*time.Second)),
+		}},
+		{id: 5, name: "other", servers: []*server{
+			newServer(1, "haproxy", "209.126.35.1", "haproxy.com", 1, now.Add(-9*time.Second)),
+			newServer(2, "apache", "151.101.2.132", "apache.org", 1, now.Add(-7*time.Second)),
+		}},
+	}
+	down := h.backends[1].servers[1]
+	down.opState = opStopped
+	down.checkStatus = 17
+	down.checkResult = 2
+	down.checkHealth = 0
+
+	h.backends[0].servers[0].counters = counters{scur: 1, smax: 6, stot: 771, bin: 1192001, bout: 24101312, eresp: 1}
+	h.backends[0].servers[1].counters = counters{scur: 2, smax: 4, stot: 770, bin: 1191204, bout: 24099101}
+	h.backends[1].servers[0].counters = counters{smax: 2, stot: 12, bin: 18211, bout: 402133}
+	h.backends[1].servers[1].counters = counters{smax: 1, stot: 3, bin: 4102, bout: 98312, econ: 2}
+
+	h.frontends = []*frontend{
+		{id: 2, name: "http-in", binds: []string{"*:80"}, state: "OPEN", maxconn: 3000,
+			counters: counters{scur: 3, smax: 12, stot: 1542, bin: 2384511, bout: 48211037, reqRate: 2, reqTot: 1535, dreq: 4, ereq: 7}},
+		{id: 3, name: "https-in", binds: []string{"*:81", "[::]:81"}, state: "OPEN", maxconn: 3000,
+			counters: counters{stot: 15, bin: 22313, bout: 500445, reqTot: 15}},
+	}
+
+	hosts := &patternList{
+		id:          -1,
+		file:        "/etc/haproxy/maps/hosts.map",
+		description: "pattern loaded from file '/etc/haproxy/maps/hosts.map' used by map at file '/usr/local/etc/haproxy/haproxy.cfg' line 28",
+		match:       "str",
+	}
+	h.addPattern(hosts, 0, "example.com", "default")
+	h.addPattern(hosts, 0, "api.example.com", "other")
+	h.maps = []*patternList{hosts}
+
+	blocklist := &patternList{
+		id:          0,
+		file:        "/etc/haproxy/acl/blocklist.acl",
+		description: "pattern loaded from file '/etc/haproxy/acl/blocklist.acl' used by acl at file '/usr/local/etc/haproxy/haproxy.cfg' line 24",
+		match:       "str",
+	}
+	h.addPattern(blocklist, 0, "10.0.0.66", "")
+	admin := &patternList{
+		id:          1,
+		description: "acl 'path_beg' file '/usr/local/etc/haproxy/haproxy.cfg' line 25",
+		match:       "beg",
+	}
+	h.addPattern(admin, 0, "/admin", "")
+	h.addPattern(admin, 0, "/internal", "")
+	h.acls = []*patternList{blocklist, admin}
+
+	rates := &stickTable{
+		name:    "http-in",
+		typ:     "ip",
+		size:    102400,
+		columns: []string{"conn_cur", "http_req_rate(10000)"},
+	}
+	rates.entries = []*tableEntry{
+		{ref: h.nextRef(), key: "10.0.0.1", exp: 28000, data: map[string]int{"conn_cur": 1, "http_req_rate": 42}},
+		{ref: h.nextRef(), key: "10.0.0.2", exp: 29500, data: map[string]int{"conn_cur": 0, "http_req_rate": 3}},
+	}
+	h.tables = []*stickTable{rates}
+
+	// the current sessions match the scur counters of the frontend and servers
+	h.streams = []*stream{
+		newStream(h.nextRef(), 12, "10.0.0.1:51234", h.frontends[0], h.backends[0], 1, now.Add(-72*time.Second)),
+		newStream(h.nextRef(), 15, "10.0.0.2:40112", h.frontends[0], h.backends[0], 0, now.Add(-5*time.Second)),
+		newStream(h.nextRef(), 16, "10.0.0.1:51240", h.frontends[0], h.backends[0], 1, now.Add(-3*time.Second)),
+	}
+
+	h.certs = demoCerts(now)
+}
+
+func newStream(ref string, id int, src string, f *frontend, b *backend, srv int, started time.Time) *stream {
+	return &stream{
+		ref:      ref,
+		uniqueID: id,
+		src:      src,
+		frontend: f,
+		backend:  b,
+		server:   b.servers[srv],
+		started:  started,
+		reqFlags: 0x848000,
+		resFlags: 0x80048000,
+		reqTotal: 321,
+		resTotal: 48211,
+	}
+}
+
+func newServer(id int, name, addr, fqdn string, weight int, changed time.Time) *server {
+	return &server{
+		id:          id,
+		name:        name,
+		addr:        addr,
+		port:        443,
+		fqdn:        fqdn,
+		opState:     opRunning,
+		uweight:     weight,
+		iweight:     weight,
+		lastChange:  changed,
+		checkStatus: 9,
+		checkResult: 3,
+		checkHealth: 4,
+		checkState:  checkConfigured | checkEnabled,
+		useSSL:      true,
+	}
+}
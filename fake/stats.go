@@ -0,0 +1,180 @@
+package fake
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// statFields are the columns of `show stat` of HAProxy 3.0
+var statFields = strings.Split("pxname,svname,qcur,qmax,scur,smax,slim,stot,bin,bout,dreq,dresp,ereq,econ,eresp,wretr,wredis,status,weight,act,bck,chkfail,chkdown,lastchg,downtime,qlimit,pid,iid,sid,throttle,lbtot,tracked,type,rate,rate_lim,rate_max,check_status,check_code,check_duration,hrsp_1xx,hrsp_2xx,hrsp_3xx,hrsp_4xx,hrsp_5xx,hrsp_other,hanafail,req_rate,req_rate_max,req_tot,cli_abrt,srv_abrt,comp_in,comp_out,comp_byp,comp_rsp,lastsess,last_chk,last_agt,qtime,ctime,rtime,ttime,agent_status,agent_code,agent_duration,check_desc,agent_desc,check_rise,check_fall,check_health,agent_rise,agent_fall,agent_health,addr,cookie,mode,algo,conn_rate,conn_rate_max,conn_tot,intercepted,dcon,dses,wrew,connect,reuse,cache_lookups,cache_hits,srv_icur,src_ilim,qtime_max,ctime_max,rtime_max,ttime_max,eint,idle_conn_cur,safe_conn_cur,used_conn_cur,need_conn_est,uweight,agg_server_status,agg_server_check_status,agg_check_status,srid,sess_other,h1sess,h2sess,h3sess,req_other,h1req,h2req,h3req,proto", ",")
+
+// showStat reports frontends with their listeners (as with `option socket-stats`), backends and servers in csv.
+// Like HAProxy the objects can be narrowed down with `show stat <iid|proxy> <type> <sid>`.
+func (h *HAProxy) showStat(_ *Session, args []string, _ string) string {
+	filter, err := parseStatFilter(args)
+	if err != "" {
+		return err
+	}
+
+	out := "# " + strings.Join(statFields, ",") + ",\n"
+	statLine := func(row map[string]string) string {
+		if !filter.matches(row) {
+			return ""
+		}
+
+		return statLine(row)
+	}
+
+	for _, f := range h.frontends {
+		row := f.counters.fields()
+		row["pxname"], row["svname"], row["status"], row["type"] = f.name, "FRONTEND", f.state, "0"
+		row["iid"], row["sid"], row["slim"], row["mode"] = strconv.Itoa(f.id), "0", strconv.Itoa(f.maxconn), "http"
+		out += statLine(row)
+
+		for i, bind := range f.binds {
+			row := counters{}.fields()
+			row["pxname"], row["svname"], row["status"], row["type"] = f.name, "sock-"+strconv.Itoa(i+1), f.state, "3"
+			row["iid"], row["sid"], row["addr"], row["mode"] = strconv.Itoa(f.id), strconv.Itoa(i+1), bindAddr(bind), "http"
+			out += statLine(row)
+		}
+	}
+
+	for _, b := range h.backends {
+		total := counters{}
+		for _, s := range b.servers {
+			row := s.counters.fields()
+			row["pxname"], row["svname"], row["status"], row["type"] = b.name, s.name, s.status(), "2"
+			row["iid"], row["sid"], row["weight"] = strconv.Itoa(b.id), strconv.Itoa(s.id), strconv.Itoa(s.iweight)
+			row["addr"], row["mode"], row["lastchg"] = s.addr+":"+strconv.Itoa(s.port), "http", strconv.Itoa(int(time.Since(s.lastChange).Seconds()))
+			row["check_status"], row["last_chk"] = s.checkDescription()
+			out += statLine(row)
+
+			total = total.add(s.counters)
+		}
+
+		row := total.fields()
+		row["pxname"], row["svname"], row["status"], row["type"] = b.name, "BACKEND", "UP", "1"
+		row["iid"], row["sid"], row["mode"], row["algo"] = strconv.Itoa(b.id), "0", "http", "roundrobin"
+		out += statLine(row)
+	}
+
+	return out
+}
+
+func (c counters) fields() map[string]string {
+	return map[string]string{
+		"scur":     strconv.Itoa(c.scur),
+		"smax":     strconv.Itoa(c.smax),
+		"stot":     strconv.Itoa(c.stot),
+		"bin":      strconv.Itoa(c.bin),
+		"bout":     strconv.Itoa(c.bout),
+		"req_rate": strconv.Itoa(c.reqRate),
+		"req_tot":  strconv.Itoa(c.reqTot),
+		"dreq":     strconv.Itoa(c.dreq),
+		"ereq":     strconv.Itoa(c.ereq),
+		"econ":     strconv.Itoa(c.econ),
+		"eresp":    strconv.Itoa(c.eresp),
+		"pid":      "1",
+	}
+}
+
+func (c counters) add(o counters) counters {
+	return counters{
+		scur:    c.scur + o.scur,
+		smax:    c.smax + o.smax,
+		stot:    c.stot + o.stot,
+		bin:     c.bin + o.bin,
+		bout:    c.bout + o.bout,
+		reqRate: c.reqRate + o.reqRate,
+		reqTot:  c.reqTot + o.reqTot,
+		dreq:    c.dreq + o.dreq,
+		ereq:    c.ereq + o.ereq,
+		econ:    c.econ + o.econ,
+		eresp:   c.eresp + o.eresp,
+	}
+}
+
+func (s *server) status() string {
+	switch {
+	case s.adminState&adminForcedMaint != 0:
+		return "MAINT"
+	case s.adminState&adminForcedDrain != 0:
+		return "DRAIN"
+	case s.opState == opStopped:
+		return "DOWN"
+	}
+
+	return "UP"
+}
+
+// checkDescription returns the check_status and last_chk of the last health check
+func (s *server) checkDescription() (string, string) {
+	if s.checkState&checkEnabled == 0 {
+		return "", ""
+	}
+	if s.checkResult == 2 {
+		return "L4CON", "Connection refused"
+	}
+
+	return "L4OK", ""
+}
+
+// bindAddr is the address of a listener as reported by `show stat`, e.g. 0.0.0.0:80 for *:80
+func bindAddr(bind string) string {
+	if strings.HasPrefix(bind, "*:") {
+		return "0.0.0.0" + bind[1:]
+	}
+
+	return bind
+}
+
+func statLine(row map[string]string) string {
+	values := make([]string, len(statFields))
+	for i, f := range statFields {
+		values[i] = row[f]
+	}
+
+	return strings.Join(values, ",") + ",\n"
+}
+
+// statFilter narrows down `show stat` to a proxy (by id or name), the object types
+// (a mask of 1 frontends, 2 backends, 4 servers, -1 for all) and a server id (-1 for all)
+type statFilter struct {
+	proxy string
+	types int
+	sid   int
+}
+
+func parseStatFilter(args []string) (statFilter, string) {
+	f := statFilter{types: -1, sid: -1}
+	if len(args) == 0 || args[0] == "typed" || args[0] == "json" {
+		return f, ""
+	}
+	if len(args) < 3 {
+		return f, "Require <iid|proxy> <type> <sid>."
+	}
+
+	types, err1 := strconv.Atoi(args[1])
+	sid, err2 := strconv.Atoi(args[2])
+	if err1 != nil || err2 != nil {
+		return f, "Invalid type or server id."
+	}
+	f.proxy, f.types, f.sid = args[0], types, sid
+
+	return f, ""
+}
+
+func (f statFilter) matches(row map[string]string) bool {
+	if f.proxy != "" && f.proxy != "-1" && f.proxy != row["pxname"] && f.proxy != row["iid"] {
+		return false
+	}
+
+	// listeners are reported with the frontends
+	mask := map[string]int{"0": 1, "1": 2, "2": 4, "3": 1}[row["type"]]
+	if f.types != -1 && f.types&mask == 0 {
+		return false
+	}
+
+	return f.sid == -1 || row["type"] != "2" || strconv.Itoa(f.sid) == row["sid"]
+}
@@ -0,0 +1,175 @@
+package fake
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type tableFilter struct {
+	column string
+	op     string
+	value  int
+}
+
+func (f tableFilter) matches(e *tableEntry) bool {
+	v := e.data[f.column]
+	switch f.op {
+	case "eq":
+		return v == f.value
+	case "ne":
+		return v != f.value
+	case "lt":
+		return v < f.value
+	case "le":
+		return v <= f.value
+	case "gt":
+		return v > f.value
+	case "ge":
+		return v >= f.value
+	}
+
+	return false
+}
+
+func (h *HAProxy) table(name string) *stickTable {
+	for _, t := range h.tables {
+		if t.name == name {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// parseTableArgs resolves the table and its optional `key <k>` and `data.<type> <op> <value>` filters
+func (h *HAProxy) parseTableArgs(args []string) (*stickTable, string, []tableFilter, string) {
+	if len(args) == 0 {
+		return nil, "", nil, "Optional table name expected"
+	}
+
+	t := h.table(args[0])
+	if t == nil {
+		return nil, "", nil, "No such table"
+	}
+
+	var key string
+	var filters []tableFilter
+	for i := 1; i < len(args); i++ {
+		switch {
+		case args[i] == "key" && i+1 < len(args):
+			key = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "data.") && i+2 < len(args):
+			name := strings.TrimPrefix(args[i], "data.")
+			if _, ok := t.column(name); !ok {
+				return nil, "", nil, "Data type not stored in this table"
+			}
+			v, err := strconv.Atoi(args[i+2])
+			if err != nil {
+				return nil, "", nil, "Require a valid integer value to compare against"
+			}
+			switch args[i+1] {
+			case "eq", "ne", "lt", "le", "gt", "ge":
+			default:
+				return nil, "", nil, "Require and operator among \"eq\", \"ne\", \"le\", \"ge\", \"lt\", \"gt\""
+			}
+			filters = append(filters, tableFilter{column: name, op: args[i+1], value: v})
+			i += 2
+		default:
+			return nil, "", nil, "Optional argument only supports \"data.<store_data_type>\" <operator> <value> and key <key>"
+		}
+	}
+
+	return t, key, filters, ""
+}
+
+func (t *stickTable) filter(key string, filters []tableFilter) []*tableEntry {
+	var res []*tableEntry
+	for _, e := range t.entries {
+		if key != "" && e.key != key {
+			continue
+		}
+		matched := true
+		for _, f := range filters {
+			matched = matched && f.matches(e)
+		}
+		if matched {
+			res = append(res, e)
+		}
+	}
+
+	return res
+}
+
+func (h *HAProxy) showTable(_ *Session, args []string, _ string) string {
+	if len(args) == 0 {
+		out := ""
+		for _, t := range h.tables {
+			out += t.header()
+		}
+		return out
+	}
+
+	t, key, filters, err := h.parseTableArgs(args)
+	if err != "" {
+		return err
+	}
+
+	out := t.header()
+	for _, e := range t.filter(key, filters) {
+		out += t.line(e)
+	}
+
+	return out
+}
+
+func (h *HAProxy) clearTable(_ *Session, args []string, _ string) string {
+	t, key, filters, err := h.parseTableArgs(args)
+	if err != "" {
+		return err
+	}
+
+	matched := t.filter(key, filters)
+	var kept []*tableEntry
+	for _, e := range t.entries {
+		if !slices.Contains(matched, e) {
+			kept = append(kept, e)
+		}
+	}
+	t.entries = kept
+
+	return ""
+}
+
+func (h *HAProxy) setTable(_ *Session, args []string, _ string) string {
+	if len(args) < 3 || args[1] != "key" {
+		return "Require 'key' followed by a key."
+	}
+
+	t := h.table(args[0])
+	if t == nil {
+		return "No such table"
+	}
+
+	e := t.find(args[2])
+	if e == nil {
+		e = &tableEntry{ref: h.nextRef(), key: args[2], exp: 30000, data: map[string]int{}}
+		t.entries = append(t.entries, e)
+	}
+
+	rest := args[3:]
+	for i := 0; i+1 < len(rest); i += 2 {
+		name := strings.TrimPrefix(rest[i], "data.")
+		if _, ok := t.column(name); !ok || !strings.HasPrefix(rest[i], "data.") {
+			return "Data type not stored in this table"
+		}
+		v, err := strconv.Atoi(rest[i+1])
+		if err != nil {
+			return "Require a valid integer value to store"
+		}
+		e.data[name] = v
+	}
+
+	return ""
+}
@@ -0,0 +1,118 @@
+package haproxy
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrUnknownCommand is reported for commands the HAProxy version doesn't know, or the master cli doesn't forward
+	ErrUnknownCommand = errors.New("unknown command")
+	// ErrPermissionDenied is reported for commands which need a higher level than the socket (or session) has
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// ResponseError is a reply HAProxy uses to report that a command failed
+type ResponseError struct {
+	Command string
+	Message string
+}
+
+func (e ResponseError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Command, e.Message)
+}
+
+// Unwrap makes the kind of failure comparable with errors.Is, e.g. ErrPermissionDenied
+func (e ResponseError) Unwrap() error {
+	switch {
+	case strings.HasPrefix(e.Message, "Unknown command"):
+		return ErrUnknownCommand
+	case strings.HasPrefix(e.Message, "Permission denied"):
+		return ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// ParseError is returned for a line of a response which could not be parsed
+type ParseError struct {
+	Line string
+	Err  error
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("failed to parse %q: %s", e.Line, e.Err)
+}
+
+func (e ParseError) Unwrap() error {
+	return e.Err
+}
+
+// the runtime api has no error marker, so failures are detected by the messages HAProxy emits
+var errorPrefixes = []string{
+	"Unknown command",
+	"Permission denied",
+	"No such ",
+	"Can't find ",
+	"Can't display ",
+	"Can't replace ",
+	"Can't load ",
+	"No ongoing transaction",
+	"The ongoing transaction",
+	"Require ",
+	"Unknown map identifier",
+	"Unknown ACL identifier",
+	"Unknown version",
+	"Key not found",
+	"Malformed ",
+	"Missing ",
+	"Invalid ",
+	"Integer value is expected",
+	"Data type not stored",
+	"Optional argument",
+	"Session not found",
+	"The command is too big",
+	"Session pointer expected",
+	"Frontend is already",
+	"Frontend was already",
+	"Health checks are not configured",
+	"Agent was not configured",
+	"usage:",
+	"This command expects",
+}
+
+// usageError matches the usage HAProxy replies with to incomplete arguments, e.g. `'add map' expects three parameters`
+var usageError = regexp.MustCompile(`^'([a-z][^'\n]*)' (?:expects|requires|only supports) `)
+
+// CheckResponse returns a ResponseError if the response of the given command reports a failure
+func CheckResponse(command string, response string) error {
+	msg := strings.TrimSpace(response)
+
+	// with `set severity-output number` every message is prefixed with its syslog severity
+	if len(msg) > 4 && msg[0] == '[' && msg[2] == ']' && msg[3] == ':' {
+		if msg[1] <= '3' {
+			return ResponseError{Command: command, Message: strings.TrimSpace(msg[4:])}
+		}
+		return nil
+	}
+
+	if slices.ContainsFunc(errorPrefixes, func(p string) bool { return strings.HasPrefix(msg, p) }) || isUsage(command, msg) {
+		return ResponseError{Command: command, Message: strings.SplitN(msg, "\n", 2)[0]}
+	}
+
+	return nil
+}
+
+// isUsage reports whether the response is the usage of the command, which names the command it belongs to
+func isUsage(command string, msg string) bool {
+	m := usageError.FindStringSubmatch(msg)
+	if m == nil {
+		return false
+	}
+	words := strings.Fields(command)
+
+	return len(words) > 0 && strings.Fields(m[1])[0] == words[0]
+}
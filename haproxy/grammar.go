@@ -0,0 +1,329 @@
+package haproxy
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrIncompleteCommand is reported for commands missing a required argument
+	ErrIncompleteCommand = errors.New("incomplete command")
+	// ErrInvalidArgument is reported for a word which is none of the keywords the grammar expects
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+// Grammar is the syntax of the arguments of a command, parsed from the usage in `help`, e.g. `[@<ver>] <map> <key> <val>`.
+//
+// The usage is written for humans and some lines are cut short by HAProxy, so the grammar is lenient:
+// unbalanced brackets are closed at the end and words following the known arguments are accepted.
+type Grammar struct {
+	Usage string
+	nodes []node
+}
+
+// Token is an argument of the usage, Start and End are its position in Grammar.Usage
+type Token struct {
+	Name     string
+	Start    int
+	End      int
+	Optional bool
+}
+
+// node is a word, or a group of alternative sequences for `[a|b c]` and `{a|b}`
+type node struct {
+	word     *word
+	group    [][]node
+	optional bool
+	repeated bool
+}
+
+// word matches a single argument, placeholders like <bk>/<srv> match any text
+type word struct {
+	Token
+	pattern *regexp.Regexp
+}
+
+// ParseGrammar parses the arguments of a command as listed by `help`
+func ParseGrammar(usage string) Grammar {
+	p := grammarParser{usage: usage}
+
+	return Grammar{Usage: usage, nodes: p.sequence(0)}
+}
+
+// Validate checks the arguments of a command, a missing argument is reported as ErrIncompleteCommand
+// and a wrong keyword as ErrInvalidArgument
+func (g Grammar) Validate(args []string) error {
+	m := matcher{args: args, failedAt: -1}
+	if len(m.sequence(g.nodes, []int{0})) > 0 {
+		return nil
+	}
+
+	if m.failedAt < 0 {
+		return ErrIncompleteCommand
+	}
+	names := make([]string, 0, len(m.expected))
+	for _, t := range m.expected {
+		names = append(names, t.Name)
+	}
+	if m.failedAt >= len(args) {
+		return fmt.Errorf("%w, missing %s", ErrIncompleteCommand, oneOf(names))
+	}
+
+	return fmt.Errorf("%w %q, expected %s", ErrInvalidArgument, args[m.failedAt], oneOf(names))
+}
+
+// Expected returns the arguments which may follow the given ones
+func (g Grammar) Expected(args []string) []Token {
+	m := matcher{args: args, failedAt: -1, next: true}
+	m.sequence(g.nodes, []int{0})
+
+	return m.expected
+}
+
+// Tokens returns every argument of the usage
+func (g Grammar) Tokens() []Token {
+	return tokens(g.nodes)
+}
+
+func tokens(nodes []node) []Token {
+	var res []Token
+	for _, n := range nodes {
+		if n.word != nil {
+			res = append(res, n.word.Token)
+		}
+		for _, alternative := range n.group {
+			res = append(res, tokens(alternative)...)
+		}
+	}
+
+	return res
+}
+
+type grammarParser struct {
+	usage string
+	pos   int
+	// optional counts the enclosing optional groups
+	optional int
+}
+
+// sequence parses words and groups up to the closing bracket of the current group, or an alternative
+func (p *grammarParser) sequence(depth int) []node {
+	var nodes []node
+
+	for p.pos < len(p.usage) {
+		c := p.usage[p.pos]
+		switch {
+		case c == ' ' || c == '\t':
+			p.pos++
+		case c == '[' || c == '{':
+			p.pos++
+			nodes = append(nodes, p.group(c == '['))
+		case c == ']' || c == '}' || c == '|':
+			if depth > 0 {
+				return nodes
+			}
+			// stray, e.g. `<acl>]`
+			p.pos++
+		default:
+			nodes = append(nodes, p.word())
+		}
+	}
+
+	return nodes
+}
+
+func (p *grammarParser) group(optional bool) node {
+	n := node{optional: optional}
+	if optional {
+		p.optional++
+		defer func() { p.optional-- }()
+	}
+
+	for {
+		n.group = append(n.group, p.sequence(1))
+		if p.pos >= len(p.usage) {
+			return n
+		}
+
+		c := p.usage[p.pos]
+		p.pos++
+		if c != '|' {
+			break
+		}
+	}
+
+	if p.pos < len(p.usage) && p.usage[p.pos] == '*' {
+		n.repeated = true
+		p.pos++
+	}
+
+	return n
+}
+
+// word reads up to the next space or bracket, placeholders in angle brackets may contain anything but '>'
+func (p *grammarParser) word() node {
+	start := p.pos
+	for p.pos < len(p.usage) {
+		c := p.usage[p.pos]
+		if c == '<' {
+			if end := strings.IndexByte(p.usage[p.pos:], '>'); end > 0 {
+				p.pos += end + 1
+				continue
+			}
+			p.pos = len(p.usage)
+			break
+		}
+		if strings.IndexByte(" \t[]{}|", c) >= 0 {
+			break
+		}
+		p.pos++
+	}
+
+	name := p.usage[start:p.pos]
+	w := &word{Token: Token{Name: name, Start: start, End: p.pos, Optional: p.optional > 0}}
+	n := node{word: w}
+
+	text := name
+	if strings.HasSuffix(text, "...") {
+		// e.g. `args...`
+		text = strings.TrimSuffix(text, "...")
+		n.repeated = true
+	}
+	w.pattern = regexp.MustCompile("^" + wordPattern(text) + "$")
+
+	return n
+}
+
+// wordPattern turns placeholders into wildcards, e.g. <bk>/<srv> into .+/.+ and data.* into data\..*
+func wordPattern(text string) string {
+	var b strings.Builder
+	for text != "" {
+		switch {
+		case text[0] == '<':
+			end := strings.IndexByte(text, '>')
+			if end < 0 {
+				end = len(text) - 1
+			}
+			b.WriteString(".+")
+			text = text[end+1:]
+		case text[0] == '*':
+			b.WriteString(".*")
+			text = text[1:]
+		default:
+			end := strings.IndexAny(text, "<*")
+			if end < 0 {
+				end = len(text)
+			}
+			b.WriteString(regexp.QuoteMeta(text[:end]))
+			text = text[end:]
+		}
+	}
+
+	return b.String()
+}
+
+// matcher follows every way through the grammar at once, tracking the positions in args each way reached
+type matcher struct {
+	args []string
+	// next collects the words tried right after the args, instead of the failures
+	next bool
+	// failedAt is the furthest position a required word failed, expected are the words tried there
+	failedAt int
+	expected []Token
+	optional int
+}
+
+func (m *matcher) sequence(nodes []node, positions []int) []int {
+	for _, n := range nodes {
+		if len(positions) == 0 {
+			return nil
+		}
+		positions = m.node(n, positions)
+	}
+
+	return positions
+}
+
+func (m *matcher) node(n node, positions []int) []int {
+	if n.optional {
+		m.optional++
+		defer func() { m.optional-- }()
+	}
+
+	res := slices.Clone(positions)
+	if !n.optional {
+		res = nil
+	}
+
+	reached := m.once(n, positions)
+	for len(reached) > 0 {
+		var added []int
+		for _, p := range reached {
+			if !slices.Contains(res, p) {
+				res = append(res, p)
+				added = append(added, p)
+			}
+		}
+		if !n.repeated {
+			break
+		}
+		reached = m.once(n, added)
+	}
+
+	slices.Sort(res)
+
+	return res
+}
+
+func (m *matcher) once(n node, positions []int) []int {
+	if n.word == nil {
+		var res []int
+		for _, alternative := range n.group {
+			res = append(res, m.sequence(alternative, positions)...)
+		}
+		return res
+	}
+
+	var res []int
+	for _, p := range positions {
+		if p < len(m.args) && n.word.pattern.MatchString(m.args[p]) {
+			res = append(res, p+1)
+			continue
+		}
+		m.fail(n.word, p)
+	}
+
+	return res
+}
+
+func (m *matcher) fail(w *word, position int) {
+	if m.next {
+		if position == len(m.args) && !slices.Contains(m.expected, w.Token) {
+			m.expected = append(m.expected, w.Token)
+		}
+		return
+	}
+
+	if m.optional > 0 || position < m.failedAt {
+		return
+	}
+	if position > m.failedAt {
+		m.failedAt = position
+		m.expected = nil
+	}
+	if !slices.Contains(m.expected, w.Token) {
+		m.expected = append(m.expected, w.Token)
+	}
+}
+
+// oneOf lists names like "a, b or c"
+func oneOf(names []string) string {
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
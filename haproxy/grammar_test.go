@@ -0,0 +1,85 @@
+package haproxy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestGrammarValidate(t *testing.T) {
+	tests := []struct {
+		usage string
+		args  string
+		err   string
+	}{
+		{"", "", ""},
+		{"", "extra words", ""},
+		{"[@<ver>] <map> <key> <val>", "m k v", ""},
+		{"[@<ver>] <map> <key> <val>", "@1 m k v", ""},
+		{"[@<ver>] <map> <key> <val>", "", "incomplete command, missing <map>"},
+		{"[@<ver>] <map> <key> <val>", "m k", "incomplete command, missing <val>"},
+		{"<bk>/<srv>", "default/apache", ""},
+		{"<bk>/<srv>", "default", `invalid argument "default", expected <bk>/<srv>`},
+		{"<map> [<key>|#<ref>] <value>", "m #1 v", ""},
+		{"<what> {auto|on|off}", "all on", ""},
+		{"<what> {auto|on|off}", "all", "incomplete command, missing auto, on or off"},
+		{"<what> {auto|on|off}", "all maybe", `invalid argument "maybe", expected auto, on or off`},
+		{"<table> key <k> [data.* <v>]*", "t key k data.gpc0 1 data.gpc1 2", ""},
+		{"<table> key <k> [data.* <v>]*", "t k", `invalid argument "k", expected key`},
+		{"{-h|<delay_ms>} cond [args...]", "-h", "incomplete command, missing cond"},
+		{"[desc|json|no-maint|typed|up]*", "json typed", ""},
+		{"[[text|base64] id]", "base64 1", ""},
+		// cut short by HAProxy or with stray brackets
+		{"<list> <cert[", "l", "incomplete command, missing <cert["},
+		{"[@<ver>] <acl>]", "a", ""},
+	}
+
+	for _, tt := range tests {
+		err := ParseGrammar(tt.usage).Validate(strings.Fields(tt.args))
+		if tt.err == "" {
+			assert.NoError(t, err, "%s: %s", tt.usage, tt.args)
+		} else {
+			assert.EqualError(t, err, tt.err, "%s: %s", tt.usage, tt.args)
+		}
+	}
+}
+
+func TestGrammarValidateErrors(t *testing.T) {
+	g := ParseGrammar("<what> {auto|on|off}")
+
+	assert.ErrorIs(t, g.Validate(nil), ErrIncompleteCommand)
+	assert.ErrorIs(t, g.Validate([]string{"all", "maybe"}), ErrInvalidArgument)
+}
+
+func TestGrammarExpected(t *testing.T) {
+	g := ParseGrammar("[@<ver>] <map> <key> <val>")
+
+	assert.Equal(t, []Token{
+		{Name: "@<ver>", Start: 1, End: 7, Optional: true},
+		{Name: "<map>", Start: 9, End: 14},
+	}, g.Expected(nil))
+	assert.Equal(t, []Token{{Name: "<key>", Start: 15, End: 20}}, g.Expected([]string{"m"}))
+	assert.Empty(t, g.Expected([]string{"m", "k", "v"}))
+
+	assert.Len(t, ParseGrammar("<what> {auto|on|off}").Expected([]string{"all"}), 3)
+}
+
+func TestGrammarTokens(t *testing.T) {
+	var names []string
+	for _, token := range ParseGrammar("<table> key <k> [data.* <v>]*").Tokens() {
+		names = append(names, token.Name)
+	}
+
+	assert.Equal(t, []string{"<table>", "key", "<k>", "data.*", "<v>"}, names)
+}
+
+func TestParseGrammarHelp(t *testing.T) {
+	input := rawHelp
+
+	// every usage of the help parses, even the ones cut short
+	for _, c := range ParseHelp(&input) {
+		g := ParseGrammar(c.Args)
+		assert.Equal(t, c.Args, g.Usage)
+		assert.NotPanics(t, func() { g.Expected(strings.Fields(c.Args)) }, c.Name)
+	}
+}
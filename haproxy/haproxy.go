@@ -2,7 +2,7 @@ package haproxy
 
 import (
 	"errors"
-	"maps"
+	"fmt"
 	"net"
 	"slices"
 	"strconv"
@@ -91,6 +91,8 @@ type Server struct {
 	CheckAddr        string
 	AgentAddr        string
 	AgentPort        int
+	// Raw is the line of `show servers state` the server was parsed from
+	Raw string
 }
 
 func (c Command) FilterValue() string {
@@ -116,7 +118,10 @@ func ParseHelp(rawHelp *string) ParsedHelp {
 		if cmd == "" {
 			continue
 		}
-		parts := strings.Split(cmd, ":")
+		parts := strings.SplitN(cmd, ":", 2)
+		if len(parts) < 2 {
+			continue
+		}
 		help := strings.TrimSpace(parts[1])
 		command := strings.TrimSpace(parts[0])
 		var args string
@@ -141,89 +146,137 @@ func ParseHelp(rawHelp *string) ParsedHelp {
 	return parsedHelp
 }
 
-func ParseBackends(input string) []Backend {
-	backends := make(map[string]Backend)
-	lines := strings.Split(input, "\n")
-
-	// Skip lines with comments (#) or empty lines
-	for _, line := range lines {
+// serversStateFormat is the only format version of `show servers state` so far
+const serversStateFormat = "1"
+
+// serversStateFields are the columns of `show servers state` since HAProxy 2.1, assumed if the response has no header.
+// Older versions list fewer columns, newer ones may append some, so the fields are mapped by the names of the header.
+var serversStateFields = strings.Fields("be_id be_name srv_id srv_name srv_addr srv_op_state srv_admin_state srv_uweight srv_iweight " +
+	"srv_time_since_last_change srv_check_status srv_check_result srv_check_health srv_check_state srv_agent_state " +
+	"bk_f_forced_id srv_f_forced_id srv_fqdn srv_port srvrecord srv_use_ssl srv_check_port srv_check_addr srv_agent_addr srv_agent_port")
+
+// ParseBackends parses `show servers state`, malformed lines are skipped and reported as ParseError
+func ParseBackends(input string) ([]Backend, error) {
+	var backends []Backend
+	// index of each backend, they are kept in the order HAProxy lists them
+	index := make(map[string]int)
+	names := serversStateFields
+	version := ""
+	var errs []error
+
+	for _, line := range strings.Split(input, "\n") {
 		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
+
+		switch {
+		case line == "":
+			continue
+		case version == "":
+			// the first line is the format version
+			version = line
+			if version != serversStateFormat {
+				errs = append(errs, ParseError{Line: line, Err: fmt.Errorf("unknown format version, expected %s", serversStateFormat)})
+			}
+			continue
+		case strings.HasPrefix(line, "# "):
+			names = strings.Fields(strings.TrimPrefix(line, "# "))
+			continue
+		case strings.HasPrefix(line, "#"):
 			continue
 		}
 
-		// Split the line into fields
 		fields := strings.Fields(line)
-
-		if len(fields) == 1 {
-			//TODO what is this "1" ?
+		if len(fields) < len(names) {
+			errs = append(errs, ParseError{Line: line, Err: fmt.Errorf("expected %d fields, got %d", len(names), len(fields))})
 			continue
 		}
 
-		backend := Backend{
-			Id:   strToInt(fields[0]), // be_id
-			Name: fields[1],           // ba_name
+		named := make(map[string]string, len(names))
+		for i, name := range names {
+			named[name] = fields[i]
 		}
 
-		// see https://docs.haproxy.org/3.1/management.html for number permutations
-		server := Server{
-			Id:               strToInt(fields[2]),               // srv_id
-			Name:             fields[3],                         //srv_name
-			Address:          strToIp(fields[4]),                // srv_addr
-			State:            strToServerState(fields[5]),       // srv_op_state
-			AdminState:       strToAdminState(fields[6]),        // srv_admin_state
-			UserWeight:       strToInt(fields[7]),               // srv_uweight
-			CalculatedWeight: strToInt(fields[8]),               // srv_iweight
-			LastStateChanged: strToTime(fields[9]),              // srv_time_since_last_change
-			SrvCheckStatus:   strToCheckState(fields[10]),       // srv_check_status
-			SrvCheckResult:   strToCheckResultState(fields[11]), // srv_check_result
-			ChecksSucceeded:  strToInt(fields[12]),              // srv_check_health
-			CheckState:       strToCheckInfoState(fields[13]),   // srv_check_state
-			SrvAgentState:    strToAgentState(fields[14]),       // srv_agent_state
-			BkFForcedID:      strToInt(fields[15]),
-			SrvFForcedID:     strToInt(fields[16]),
-			Fqdn:             fields[17],
-			Port:             strToInt(fields[18]),
-			SrvRecord:        fields[19],
-			UseSSL:           strToBool(fields[20]),
-			CheckPort:        strToInt(fields[21]),
-			CheckAddr:        fields[22],
-			AgentAddr:        fields[23],
-			AgentPort:        strToInt(fields[24]),
-		}
-		if _, ok := backends[backend.Name]; !ok {
-			backends[backend.Name] = backend
+		backend, server, err := parseServer(named)
+		if err != nil {
+			errs = append(errs, ParseError{Line: line, Err: err})
+			continue
 		}
+		server.Raw = line
 
-		backend = backends[backend.Name]
-		backend.Servers = append(backend.Servers, server)
-		backends[backend.Name] = backend
+		i, ok := index[backend.Name]
+		if !ok {
+			i = len(backends)
+			index[backend.Name] = i
+			backends = append(backends, backend)
+		}
+		backends[i].Servers = append(backends[i].Servers, server)
 	}
 
-	return slices.Collect(maps.Values(backends))
+	return backends, errors.Join(errs...)
 }
 
-func strToInt(s string) int {
-	i, err := strconv.Atoi(s)
+// parseServer maps the fields of a `show servers state` line by their names,
+// fields unknown to the HAProxy version are left empty
+func parseServer(fields map[string]string) (Backend, Server, error) {
+	for _, name := range []string{"be_name", "srv_name"} {
+		if fields[name] == "" {
+			return Backend{}, Server{}, fmt.Errorf("missing %s", name)
+		}
+	}
+
+	p := fieldParser{fields: fields}
+
+	addr, err := strToIp(fields["srv_addr"])
 	if err != nil {
-		panic(err)
+		return Backend{}, Server{}, err
+	}
+
+	backend := Backend{
+		Id:   p.int("be_id"),
+		Name: fields["be_name"],
+	}
+
+	// see https://docs.haproxy.org/3.1/management.html for number permutations
+	server := Server{
+		Id:               p.int("srv_id"),
+		Name:             fields["srv_name"],
+		Address:          addr,
+		State:            strToServerState(fields["srv_op_state"]),
+		AdminState:       strToAdminState(p.int("srv_admin_state")),
+		UserWeight:       p.int("srv_uweight"),
+		CalculatedWeight: p.int("srv_iweight"),
+		LastStateChanged: secondsAgo(p.int("srv_time_since_last_change")),
+		SrvCheckStatus:   strToCheckState(fields["srv_check_status"]),
+		SrvCheckResult:   strToCheckResultState(fields["srv_check_result"]),
+		ChecksSucceeded:  p.int("srv_check_health"),
+		CheckState:       strToCheckInfoState(p.int("srv_check_state")),
+		SrvAgentState:    strToAgentState(p.int("srv_agent_state")),
+		BkFForcedID:      p.int("bk_f_forced_id"),
+		SrvFForcedID:     p.int("srv_f_forced_id"),
+		Fqdn:             fields["srv_fqdn"],
+		Port:             p.int("srv_port"),
+		SrvRecord:        fields["srvrecord"],
+		UseSSL:           strToBool(fields["srv_use_ssl"]),
+		CheckPort:        p.int("srv_check_port"),
+		CheckAddr:        fields["srv_check_addr"],
+		AgentAddr:        fields["srv_agent_addr"],
+		AgentPort:        p.int("srv_agent_port"),
 	}
 
-	return i
+	return backend, server, p.err
 }
 
-func strToIp(s string) net.IP {
-	if s == "-" {
-		return nil
+func strToIp(s string) (net.IP, error) {
+	if s == "-" || s == "" {
+		return nil, nil
 	}
 
 	i := net.ParseIP(s)
 
 	if i == nil {
-		panic(errors.New("invalid ip"))
+		return nil, fmt.Errorf("invalid ip %q", s)
 	}
 
-	return i
+	return i, nil
 }
 
 func strToBool(s string) bool {
@@ -249,7 +302,7 @@ func strToServerState(s string) string {
 	}
 }
 
-func strToAgentState(s string) string {
+func strToAgentState(state int) string {
 	//bitmask
 	const (
 		running = 1 << iota
@@ -258,7 +311,6 @@ func strToAgentState(s string) string {
 		paused
 		is_agent
 	)
-	state := strToInt(s)
 
 	if (state & (configured | enabled)) == (configured | enabled) {
 		return ENABLED
@@ -272,7 +324,7 @@ func strToAgentState(s string) string {
 		return AGENT
 	}
 
-	return s
+	return strconv.Itoa(state)
 }
 
 func strToCheckState(s string) string {
@@ -286,7 +338,7 @@ func strToCheckState(s string) string {
 	}
 }
 
-func strToAdminState(s string) string {
+func strToAdminState(state int) string {
 	const (
 		f_maint = 1 << iota
 		i_maint
@@ -296,7 +348,6 @@ func strToAdminState(s string) string {
 		r_maint
 		h_maint
 	)
-	state := strToInt(s)
 
 	if state&(f_maint|i_maint|c_maint|h_maint|r_maint|h_maint) != 0 {
 		return MAINT
@@ -306,9 +357,9 @@ func strToAdminState(s string) string {
 		return DRAIN
 	}
 
-	return s
+	return strconv.Itoa(state)
 }
-func strToCheckInfoState(s string) string {
+func strToCheckInfoState(state int) string {
 	//bitmask
 	const (
 		running = 1 << iota
@@ -316,7 +367,6 @@ func strToCheckInfoState(s string) string {
 		enabled
 		paused
 	)
-	state := strToInt(s)
 
 	if (state & (configured | enabled)) == (configured | enabled) {
 		return ENABLED
@@ -346,8 +396,22 @@ func strToCheckResultState(s string) string {
 	}
 }
 
-func strToTime(s string) time.Time {
-	return time.Unix(time.Now().Unix()-int64(strToInt(s)), 0)
+// EscapeArg escapes an argument for the command line of the cli, which splits words at spaces and commands at `;`
+func EscapeArg(arg string) string {
+	var b strings.Builder
+	for _, r := range arg {
+		switch r {
+		case '\\', ' ', '\t', ';':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func secondsAgo(seconds int) time.Time {
+	return time.Unix(time.Now().Unix()-int64(seconds), 0)
 }
 
 func findArgumentsStart(command string) int {
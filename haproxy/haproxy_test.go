@@ -193,10 +193,99 @@ func TestSplitArgumentsFromCommand(t *testing.T) {
 	assert.Equal(t, "wait", strings.TrimSpace(cmd[:5]))
 }
 
+func TestEscapeArg(t *testing.T) {
+	assert.Equal(t, "example.com", EscapeArg("example.com"))
+	assert.Equal(t, `a\ b\;\ show\ info\\`, EscapeArg(`a b; show info\`))
+	assert.Equal(t, "\\\t", EscapeArg("\t"))
+}
+
 func TestParseBackends(t *testing.T) {
-	res := ParseBackends(sampleBackends)
+	res, err := ParseBackends(sampleBackends)
 
+	assert.Nil(t, err)
 	assert.Len(t, res, 2)
 	assert.Len(t, res[0].Servers, 2)
 	assert.Len(t, res[1].Servers, 2)
+
+	// in the order of the response
+	assert.Equal(t, "default", res[0].Name)
+	assert.Equal(t, "other", res[1].Name)
+}
+
+func TestParseBackendsFormats(t *testing.T) {
+	// HAProxy 2.0 has no ssl, check and agent address columns
+	res, err := ParseBackends(`1
+# be_id be_name srv_id srv_name srv_addr srv_op_state srv_admin_state srv_uweight srv_iweight srv_time_since_last_change srv_check_status srv_check_result srv_check_health srv_check_state srv_agent_state bk_f_forced_id srv_f_forced_id srv_fqdn srv_port srvrecord
+4 default 1 haproxy 209.126.35.1 2 0 20 20 9 9 3 4 6 0 0 0 haproxy.com 443 -
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, "haproxy", res[0].Servers[0].Name)
+	assert.Equal(t, 443, res[0].Servers[0].Port)
+	assert.Equal(t, "", res[0].Servers[0].AgentAddr)
+	assert.False(t, res[0].Servers[0].UseSSL)
+
+	// columns are mapped by name, unknown ones are ignored
+	res, err = ParseBackends(`1
+# be_id be_name srv_id srv_name srv_port srv_addr srv_future
+4 default 1 haproxy 8080 10.0.0.1 foo
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, 8080, res[0].Servers[0].Port)
+	assert.Equal(t, "10.0.0.1", res[0].Servers[0].Address.String())
+
+	// without header the columns of HAProxy 2.1+ are assumed
+	res, err = ParseBackends("1\n4 default 1 haproxy 209.126.35.1 2 0 20 20 9 9 3 4 6 0 0 0 haproxy.com 443 - 1 0 - - 0\n")
+	assert.Nil(t, err)
+	assert.True(t, res[0].Servers[0].UseSSL)
+
+	res, err = ParseBackends("2\n4 default 1 haproxy 209.126.35.1 2 0 20 20 9 9 3 4 6 0 0 0 haproxy.com 443 - 1 0 - - 0\n")
+	assert.ErrorContains(t, err, "unknown format version")
+	assert.Len(t, res, 1)
+
+	_, err = ParseBackends("1\n# be_id be_name srv_id srv_name srv_port\n4 default 1 haproxy foo\n")
+	assert.ErrorContains(t, err, `field srv_port is not a number: "foo"`)
+}
+
+func TestParseBackendsWithMalformedLine(t *testing.T) {
+	input := strings.Replace(sampleBackends, "5 other 2 apache 151.101.2.132", "5 other 2 apache foo", 1) + "4 default 3 broken\n"
+
+	res, err := ParseBackends(input)
+
+	var parseErr ParseError
+	assert.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "5 other 2 apache foo 0 0 1 1 7 17 2 0 6 0 0 0 apache.org 443 - 1 0 - - 0", parseErr.Line)
+	assert.Contains(t, err.Error(), `invalid ip "foo"`)
+	assert.Contains(t, err.Error(), "expected 25 fields, got 4")
+
+	servers := 0
+	for _, b := range res {
+		servers += len(b.Servers)
+	}
+	assert.Equal(t, 3, servers)
+}
+
+func TestCheckResponse(t *testing.T) {
+	assert.Nil(t, CheckResponse("show info", "Name: HAProxy\nVersion: 3.1.0"))
+	assert.Nil(t, CheckResponse("set server default/apache state maint", ""))
+	assert.Nil(t, CheckResponse("set server default/apache state maint", "[6]: done"))
+
+	err := CheckResponse("foo", "Unknown command: 'foo'. Please enter one of the following commands only:\n  help")
+	assert.Equal(t, ResponseError{Command: "foo", Message: "Unknown command: 'foo'. Please enter one of the following commands only:"}, err)
+	assert.Equal(t, "foo: Unknown command: 'foo'. Please enter one of the following commands only:", err.Error())
+
+	assert.Error(t, CheckResponse("set server default/foo state maint", "No such server."))
+	assert.Error(t, CheckResponse("set server default/foo state foo", "'set server <srv> state' expects 'ready', 'drain' and 'maint'."))
+	assert.Error(t, CheckResponse("add map foo", "'add map' expects three parameters: map identifier, key and value."))
+	assert.Error(t, CheckResponse("del map foo", "This command expects two parameters: map identifier and key."))
+	// responses merely starting with a quote are no usage
+	assert.Nil(t, CheckResponse("echo 'x'", "'x'"))
+	assert.Nil(t, CheckResponse("echo 'x' expects y", "'x' expects y"))
+	assert.Error(t, CheckResponse("enable agent default/apache", "Agent was not configured on this server, cannot enable."))
+	assert.Error(t, CheckResponse("show table http-in conn_cur gt 0", `Optional argument only supports "data.<store_data_type>" <operator> <value> and key <key>`))
+	assert.Error(t, CheckResponse("show sess 0x1", "Session not found."))
+	assert.Equal(t, ResponseError{Command: "get weight x", Message: "No such server."}, CheckResponse("get weight x", "[3]: No such server."))
+
+	assert.ErrorIs(t, err, ErrUnknownCommand)
+	assert.ErrorIs(t, CheckResponse("set server default/apache state maint", "Permission denied\n"), ErrPermissionDenied)
+	assert.NotErrorIs(t, CheckResponse("get weight x", "No such server."), ErrPermissionDenied)
 }
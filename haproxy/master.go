@@ -0,0 +1,117 @@
+package haproxy
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	MASTER  = "master"
+	WORKER  = "worker"
+	PROGRAM = "program"
+)
+
+// Process is a line of the master cli's `show proc`
+type Process struct {
+	Pid     int
+	Type    string
+	Reloads int
+	Failed  int
+	Uptime  string
+	Version string
+	// Old is set for workers of a previous generation which are still finishing their connections
+	Old bool
+}
+
+// Target is the master cli prefix routing a command to this process
+func (p Process) Target() string {
+	if p.Type == MASTER {
+		return "@master"
+	}
+
+	return "@!" + strconv.Itoa(p.Pid)
+}
+
+// ReloadResult is the outcome of the master cli's `reload`
+type ReloadResult struct {
+	// Reported is false for HAProxy < 2.7, which closes the connection without telling the outcome
+	Reported bool
+	Success  bool
+	Logs     string
+}
+
+// IsMaster reports whether the help was emitted by the master cli of a master-worker HAProxy
+func (p ParsedHelp) IsMaster() bool {
+	return p.Contains("show proc") && p.Contains("@master")
+}
+
+func ParseProcesses(input string) []Process {
+	var processes []Process
+	section := ""
+
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#<PID>"):
+			continue
+		case strings.HasPrefix(line, "#"):
+			section = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		reloads, _ := strconv.Atoi(fields[2])
+
+		p := Process{
+			Pid:     pid,
+			Type:    fields[1],
+			Reloads: reloads,
+			Uptime:  fields[len(fields)-2],
+			Version: fields[len(fields)-1],
+			Old:     section == "old workers",
+		}
+
+		// the master reports its failed reloads as "1 [failed: 0]"
+		if _, rest, ok := strings.Cut(line, "[failed:"); ok {
+			failed, _, _ := strings.Cut(rest, "]")
+			p.Failed, _ = strconv.Atoi(strings.TrimSpace(failed))
+		}
+
+		processes = append(processes, p)
+	}
+
+	return processes
+}
+
+// CurrentWorker returns the worker of the latest generation, the default target of commands
+func CurrentWorker(processes []Process) *Process {
+	for _, p := range processes {
+		if p.Type == WORKER && !p.Old {
+			return &p
+		}
+	}
+
+	return nil
+}
+
+// ParseReload parses the `Success=1\n--\n<startup logs>` reply of a reload (HAProxy >= 2.7)
+func ParseReload(input string) ReloadResult {
+	status, logs, _ := strings.Cut(input, "\n--")
+	status = strings.TrimSpace(status)
+
+	return ReloadResult{
+		Reported: strings.HasPrefix(status, "Success="),
+		Success:  status == "Success=1",
+		Logs:     strings.TrimSpace(logs),
+	}
+}
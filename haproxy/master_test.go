@@ -0,0 +1,56 @@
+package haproxy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const sampleProcesses = `#<PID>          <type>          <reloads>       <uptime>        <version>
+1               master          2 [failed: 1]   0d00h02m10s     3.1.0
+# workers
+31              worker          0               0d00h00m09s     3.1.0
+# old workers
+8               worker          1               0d00h02m10s     3.1.0
+# programs
+`
+
+const masterHelp = `The following commands are valid at this level:
+  @!<pid>                                 : send a command to the <pid> process
+  @<relative pid>                         : send a command to the <relative pid> process
+  @master                                 : send a command to the master process
+  help [<command>]                        : list matching or all commands
+  prompt [timed]                          : toggle interactive mode with prompt
+  quit                                    : disconnect
+  reload                                  : achieve a soft-reload (-sf) of haproxy
+  show proc                               : show processes status
+  show startup-logs                       : report logs emitted during HAProxy startup
+`
+
+func TestParseProcesses(t *testing.T) {
+	res := ParseProcesses(sampleProcesses)
+
+	assert.Equal(t, []Process{
+		{Pid: 1, Type: MASTER, Reloads: 2, Failed: 1, Uptime: "0d00h02m10s", Version: "3.1.0"},
+		{Pid: 31, Type: WORKER, Uptime: "0d00h00m09s", Version: "3.1.0"},
+		{Pid: 8, Type: WORKER, Reloads: 1, Uptime: "0d00h02m10s", Version: "3.1.0", Old: true},
+	}, res)
+
+	assert.Equal(t, "@master", res[0].Target())
+	assert.Equal(t, "@!31", res[1].Target())
+	assert.Equal(t, 31, CurrentWorker(res).Pid)
+	assert.Nil(t, CurrentWorker(res[:1]))
+}
+
+func TestIsMaster(t *testing.T) {
+	help := masterHelp
+	assert.True(t, ParseHelp(&help).IsMaster())
+
+	help = rawHelp
+	assert.False(t, ParseHelp(&help).IsMaster())
+}
+
+func TestParseReload(t *testing.T) {
+	assert.Equal(t, ReloadResult{Reported: true, Success: true, Logs: "[NOTICE]   (1) : haproxy version is 3.1.0"}, ParseReload("Success=1\n--\n[NOTICE]   (1) : haproxy version is 3.1.0\n"))
+	assert.Equal(t, ReloadResult{Reported: true, Success: false, Logs: "[ALERT]    (1) : config : parsing error"}, ParseReload("Success=0\n--\n[ALERT]    (1) : config : parsing error"))
+	assert.Equal(t, ReloadResult{}, ParseReload(""))
+}
@@ -0,0 +1,171 @@
+package haproxy
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PatternList is a map or acl as listed by `show map` and `show acl`
+type PatternList struct {
+	Id          int
+	File        string
+	Description string
+	// CurrentVersion is in use, NextVersion is the next one `prepare map` hands out
+	CurrentVersion int
+	NextVersion    int
+	Entries        int
+}
+
+// Name is how commands refer to the list, by its file or #<id> for lists declared inline
+func (p PatternList) Name() string {
+	if p.File != "" {
+		return p.File
+	}
+
+	return "#" + strconv.Itoa(p.Id)
+}
+
+// ParsePatternLists parses `show map` and `show acl`, lines like
+// `-1 (/etc/haproxy/hosts.map) pattern loaded from file ... curr_ver=0 next_ver=0 entry_cnt=2`
+func ParsePatternLists(input string) ([]PatternList, error) {
+	var lists []PatternList
+	var errs []error
+
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		l, err := parsePatternList(line)
+		if err != nil {
+			errs = append(errs, ParseError{Line: line, Err: err})
+			continue
+		}
+		lists = append(lists, l)
+	}
+
+	return lists, errors.Join(errs...)
+}
+
+func parsePatternList(line string) (PatternList, error) {
+	id, rest, _ := strings.Cut(line, " ")
+	if !strings.HasPrefix(rest, "(") {
+		return PatternList{}, fmt.Errorf("missing file")
+	}
+	file, rest, ok := strings.Cut(rest[1:], ")")
+	if !ok {
+		return PatternList{}, fmt.Errorf("missing file")
+	}
+
+	l := PatternList{File: file}
+	var err error
+	if l.Id, err = strconv.Atoi(id); err != nil {
+		return PatternList{}, fmt.Errorf("invalid id %q", id)
+	}
+
+	// the versions follow the description, they are missing before HAProxy 2.4
+	description, counts := rest, ""
+	if i := strings.Index(rest, " curr_ver="); i >= 0 {
+		description, counts = rest[:i], rest[i+1:]
+	}
+	l.Description = strings.TrimSuffix(strings.TrimSpace(description), ".")
+
+	fields := map[string]string{}
+	for _, f := range strings.Fields(counts) {
+		k, v, _ := strings.Cut(f, "=")
+		fields[k] = v
+	}
+
+	p := fieldParser{fields: fields}
+	l.CurrentVersion = p.int("curr_ver")
+	l.NextVersion = p.int("next_ver")
+	l.Entries = p.int("entry_cnt")
+
+	return l, p.err
+}
+
+// Pattern is an entry of a map or acl as listed by `show map <map>` and `show acl <acl>`,
+// Ref identifies it as `#<ref>` in commands
+type Pattern struct {
+	Ref   string
+	Key   string
+	Value string
+}
+
+// ParsePatterns parses the entries of a map, lines like `0x55d7e1c00020 example.com default`
+func ParsePatterns(input string) ([]Pattern, error) {
+	return parsePatterns(input, true)
+}
+
+// ParseAclPatterns parses the patterns of an acl, lines like `0x55d7e1c00080 10.0.0.0/8`.
+// A pattern has no value, the whole rest of the line is the pattern even if it contains spaces.
+func ParseAclPatterns(input string) ([]Pattern, error) {
+	return parsePatterns(input, false)
+}
+
+func parsePatterns(input string, hasValue bool) ([]Pattern, error) {
+	var patterns []Pattern
+	var errs []error
+
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		ref, key, _ := strings.Cut(line, " ")
+		key, value := strings.TrimSpace(key), ""
+		if hasValue {
+			key, value, _ = strings.Cut(key, " ")
+		}
+		if key == "" {
+			errs = append(errs, ParseError{Line: line, Err: fmt.Errorf("missing key")})
+			continue
+		}
+		patterns = append(patterns, Pattern{Ref: ref, Key: key, Value: strings.TrimSpace(value)})
+	}
+
+	return patterns, errors.Join(errs...)
+}
+
+// PatternMatch is the result of looking up a value with `get map` or `get acl`
+type PatternMatch struct {
+	Matched bool
+	// Match is the matching method of the map or acl, like `str` or `beg`
+	Match string
+	// Pattern is the matching key of a map or pattern of an acl, Value is the value of the map entry
+	Pattern string
+	Value   string
+}
+
+// ParsePatternMatch parses a response like `type=beg, case=sensitive, match=yes, idx=list, pattern="/admin"`
+// of `get acl` or `type=str, case=sensitive, found=yes, idx=tree, key="example.com", value="default", type="str"` of `get map`
+func ParsePatternMatch(input string) (PatternMatch, error) {
+	line, _, _ := strings.Cut(strings.TrimSpace(input), "\n")
+
+	fields := map[string]string{}
+	for _, f := range strings.Split(line, ", ") {
+		k, v, _ := strings.Cut(f, "=")
+		if _, ok := fields[k]; !ok {
+			fields[k] = strings.Trim(v, `"`)
+		}
+	}
+
+	result, ok := fields["match"]
+	if !ok {
+		result, ok = fields["found"]
+	}
+	if !ok {
+		return PatternMatch{}, ParseError{Line: line, Err: fmt.Errorf("missing match")}
+	}
+
+	m := PatternMatch{Matched: result == "yes", Match: fields["type"], Pattern: fields["pattern"], Value: fields["value"]}
+	if m.Pattern == "" {
+		m.Pattern = fields["key"]
+	}
+
+	return m, nil
+}
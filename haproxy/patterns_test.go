@@ -0,0 +1,76 @@
+package haproxy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParsePatternLists(t *testing.T) {
+	input := `# id (file) description
+-1 (/etc/haproxy/maps/hosts.map) pattern loaded from file '/etc/haproxy/maps/hosts.map' used by map at file '/usr/local/etc/haproxy/haproxy.cfg' line 28. curr_ver=0 next_ver=1 entry_cnt=2
+1 () acl 'path_beg' file '/usr/local/etc/haproxy/haproxy.cfg' line 25. curr_ver=0 next_ver=0 entry_cnt=2
+`
+
+	lists, err := ParsePatternLists(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []PatternList{
+		{Id: -1, File: "/etc/haproxy/maps/hosts.map", Description: "pattern loaded from file '/etc/haproxy/maps/hosts.map' used by map at file '/usr/local/etc/haproxy/haproxy.cfg' line 28", NextVersion: 1, Entries: 2},
+		{Id: 1, Description: "acl 'path_beg' file '/usr/local/etc/haproxy/haproxy.cfg' line 25", Entries: 2},
+	}, lists)
+	assert.Equal(t, "/etc/haproxy/maps/hosts.map", lists[0].Name())
+	assert.Equal(t, "#1", lists[1].Name())
+}
+
+func TestParsePatternListsWithoutVersions(t *testing.T) {
+	lists, err := ParsePatternLists("0 (/etc/haproxy/blocklist.acl) pattern loaded from file '/etc/haproxy/blocklist.acl'\n")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pattern loaded from file '/etc/haproxy/blocklist.acl'", lists[0].Description)
+}
+
+func TestParsePatternListsMalformed(t *testing.T) {
+	lists, err := ParsePatternLists("foo (bar) baz\n0 missing file\n1 (a.acl) ok\n")
+
+	assert.Len(t, lists, 1)
+	assert.ErrorAs(t, err, &ParseError{})
+	assert.ErrorContains(t, err, `invalid id "foo"`)
+	assert.ErrorContains(t, err, "missing file")
+}
+
+func TestParsePatterns(t *testing.T) {
+	patterns, err := ParsePatterns("0x55d7e1c00020 example.com default\n0x55d7e1c00040 api.example.com api servers\n0x55d7e1c00060\n")
+
+	assert.ErrorAs(t, err, &ParseError{})
+	assert.Equal(t, []Pattern{
+		{Ref: "0x55d7e1c00020", Key: "example.com", Value: "default"},
+		{Ref: "0x55d7e1c00040", Key: "api.example.com", Value: "api servers"},
+	}, patterns)
+
+}
+
+func TestParseAclPatterns(t *testing.T) {
+	acl, err := ParseAclPatterns("0x55d7e1c00080 10.0.0.0/8\n0x55d7e1c000a0 Mozilla/5.0 (compatible; bot)\n0x55d7e1c000c0\n")
+
+	assert.ErrorAs(t, err, &ParseError{})
+	assert.Equal(t, []Pattern{
+		{Ref: "0x55d7e1c00080", Key: "10.0.0.0/8"},
+		{Ref: "0x55d7e1c000a0", Key: "Mozilla/5.0 (compatible; bot)"},
+	}, acl)
+}
+
+func TestParsePatternMatch(t *testing.T) {
+	m, err := ParsePatternMatch(`type=beg, case=sensitive, match=yes, idx=list, pattern="/admin"` + "\n")
+	assert.NoError(t, err)
+	assert.Equal(t, PatternMatch{Matched: true, Match: "beg", Pattern: "/admin"}, m)
+
+	m, err = ParsePatternMatch(`type=str, case=sensitive, found=yes, idx=tree, key="example.com", value="default", type="str"`)
+	assert.NoError(t, err)
+	assert.Equal(t, PatternMatch{Matched: true, Match: "str", Pattern: "example.com", Value: "default"}, m)
+
+	m, err = ParsePatternMatch("type=beg, case=sensitive, match=no")
+	assert.NoError(t, err)
+	assert.False(t, m.Matched)
+
+	_, err = ParsePatternMatch("something else")
+	assert.ErrorAs(t, err, &ParseError{})
+}
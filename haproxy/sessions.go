@@ -0,0 +1,123 @@
+package haproxy
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Session is a stream as listed by `show sess`, Id is the pointer identifying it in
+// `show sess <id>` and `shutdown session <id>`
+type Session struct {
+	Id       string
+	Proto    string
+	Source   string
+	Frontend string
+	Backend  string
+	Server   string
+	Age      time.Duration
+	// State is the state of the task handling the stream (ts)
+	State string
+	// RequestFlags and ResponseFlags are the flags of the request and response channels, like `848000h`
+	RequestFlags  string
+	ResponseFlags string
+	UniqueId      int
+}
+
+// HasServer reports whether the stream is connected to a server, cli and rejected streams are not
+func (s Session) HasServer() bool {
+	return s.Server != "" && s.Server != "<none>" && s.Backend != "" && s.Backend != "<NONE>"
+}
+
+// ParseSessions parses the streams of `show sess`, lines like
+// `0x55d7e1c00020: proto=tcpv4 src=10.0.0.1:51234 fe=http-in be=default srv=apache ts=00 age=1m12s ... rq[f=848000h,i=0] rp[f=80048000h,i=0] ...`
+func ParseSessions(input string) ([]Session, error) {
+	var sessions []Session
+	var errs []error
+
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		s, err := parseSession(line)
+		if err != nil {
+			errs = append(errs, ParseError{Line: line, Err: err})
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, errors.Join(errs...)
+}
+
+func parseSession(line string) (Session, error) {
+	id, rest, ok := strings.Cut(line, ": ")
+	if !ok || !strings.HasPrefix(id, "0x") {
+		return Session{}, fmt.Errorf("missing stream pointer")
+	}
+
+	fields := map[string]string{}
+	for _, f := range strings.Fields(rest) {
+		// channels are listed like `rq[f=848000h,i=0,an=00h]`, stream connectors like `scf=[8,200h,fd=23]`
+		if name, inner, ok := strings.Cut(f, "["); ok && !strings.Contains(name, "=") {
+			for _, c := range strings.Split(strings.TrimSuffix(inner, "]"), ",") {
+				k, v, _ := strings.Cut(c, "=")
+				fields[name+"."+k] = v
+			}
+			continue
+		}
+		k, v, _ := strings.Cut(f, "=")
+		fields[k] = v
+	}
+
+	age, err := parseHumanTime(fields["age"])
+	if err != nil {
+		return Session{}, fmt.Errorf("invalid age: %w", err)
+	}
+
+	s := Session{
+		Id:            id,
+		Proto:         fields["proto"],
+		Source:        fields["src"],
+		Frontend:      fields["fe"],
+		Backend:       fields["be"],
+		Server:        fields["srv"],
+		Age:           age,
+		State:         fields["ts"],
+		RequestFlags:  fields["rq.f"],
+		ResponseFlags: fields["rp.f"],
+	}
+	if v, ok := fields["uniq_id"]; ok {
+		s.UniqueId, err = strconv.Atoi(v)
+	}
+
+	return s, err
+}
+
+// parseHumanTime parses durations formatted by HAProxy like `5s`, `1m12s` or `1d3h`
+func parseHumanTime(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	var days time.Duration
+	if d, rest, ok := strings.Cut(s, "d"); ok {
+		n, err := strconv.Atoi(d)
+		if err != nil {
+			return 0, err
+		}
+		days = time.Duration(n) * 24 * time.Hour
+		if rest == "" {
+			return days, nil
+		}
+		s = rest
+	}
+
+	d, err := time.ParseDuration(s)
+
+	return days + d, err
+}
@@ -0,0 +1,33 @@
+package haproxy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseSessions(t *testing.T) {
+	input := `0x55d7e1c00020: proto=tcpv4 src=10.0.0.1:51234 fe=http-in be=default srv=apache ts=00 epoch=0 age=1m12s calls=4 rate=0 cpu=0 lat=0 rq[f=848000h,i=0,an=00h,ax=] rp[f=80048000h,i=0,an=00h,ax=] scf=[8,200h,fd=23,rex=1m,wex=] scb=[8,1h,fd=24,rex=,wex=] exp=1m rc=0 c_exp= uniq_id=12
+0x55d7e1c00a40: proto=unix_stream src=unix:1 fe=GLOBAL be=<NONE> srv=<none> ts=02 age=1d3h calls=2 rq[f=c48202h,i=0,an=00h] rp[f=80008002h,i=0,an=00h] s0=[8,280h,fd=25,ex=] s1=[8,1h,fd=-1,ex=] exp=10s
+
+`
+
+	sessions, err := ParseSessions(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []Session{
+		{Id: "0x55d7e1c00020", Proto: "tcpv4", Source: "10.0.0.1:51234", Frontend: "http-in", Backend: "default", Server: "apache",
+			Age: 72 * time.Second, State: "00", RequestFlags: "848000h", ResponseFlags: "80048000h", UniqueId: 12},
+		{Id: "0x55d7e1c00a40", Proto: "unix_stream", Source: "unix:1", Frontend: "GLOBAL", Backend: "<NONE>", Server: "<none>",
+			Age: 27 * time.Hour, State: "02", RequestFlags: "c48202h", ResponseFlags: "80008002h"},
+	}, sessions)
+	assert.True(t, sessions[0].HasServer())
+	assert.False(t, sessions[1].HasServer())
+}
+
+func TestParseSessionsMalformed(t *testing.T) {
+	sessions, err := ParseSessions("0x55d7e1c00020: proto=tcpv4 age=soon\nSession not found.\n")
+
+	assert.Empty(t, sessions)
+	assert.ErrorContains(t, err, "invalid age")
+	assert.ErrorContains(t, err, "missing stream pointer")
+}
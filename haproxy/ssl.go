@@ -0,0 +1,120 @@
+package haproxy
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParseCertFiles parses the file names listed by `show ssl cert`, uncommitted files of a
+// transaction are listed with a leading `*` and skipped
+func ParseCertFiles(input string) []string {
+	var files []string
+
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "*") {
+			continue
+		}
+		files = append(files, line)
+	}
+
+	return files
+}
+
+// ParseCertTransaction returns the file of the ongoing transaction listed by `show ssl cert`, if any
+func ParseCertTransaction(input string) string {
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "*") {
+			return strings.TrimPrefix(line, "*")
+		}
+	}
+
+	return ""
+}
+
+// Certificate is the leaf certificate of a file as shown by `show ssl cert <file>`
+type Certificate struct {
+	File      string
+	Status    string
+	Serial    string
+	NotBefore time.Time
+	NotAfter  time.Time
+	// SANs are the subject alternative names, DNS names without their `DNS:` prefix
+	SANs        []string
+	Algorithm   string
+	Fingerprint string
+	// Subject and Issuer are in the one line format of OpenSSL, like `/O=Example/CN=example.com`
+	Subject string
+	Issuer  string
+}
+
+// CommonName is the CN of the subject
+func (c Certificate) CommonName() string {
+	return commonName(c.Subject)
+}
+
+// IssuerName is the CN of the issuer, or the whole issuer if it has none
+func (c Certificate) IssuerName() string {
+	return cmp.Or(commonName(c.Issuer), c.Issuer)
+}
+
+// ExpiresWithin reports whether the certificate expires before the given time from now, or has expired
+func (c Certificate) ExpiresWithin(d time.Duration, now time.Time) bool {
+	return c.NotAfter.Before(now.Add(d))
+}
+
+// ParseCertificate parses the details of `show ssl cert <file>`, the lines of the chain are skipped
+func ParseCertificate(input string) (Certificate, error) {
+	fields := map[string]string{}
+	for _, line := range strings.Split(input, "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(line), ": ")
+		if _, seen := fields[k]; ok && !seen {
+			fields[k] = strings.TrimSpace(v)
+		}
+	}
+	if fields["Filename"] == "" {
+		return Certificate{}, ParseError{Line: strings.TrimSpace(input), Err: fmt.Errorf("missing filename")}
+	}
+
+	c := Certificate{
+		File:        fields["Filename"],
+		Status:      fields["Status"],
+		Serial:      fields["Serial"],
+		Algorithm:   fields["Algorithm"],
+		Fingerprint: fields["SHA1 FingerPrint"],
+		Subject:     fields["Subject"],
+		Issuer:      fields["Issuer"],
+	}
+	if sans := fields["Subject Alternative Name"]; sans != "" {
+		for _, n := range strings.Split(sans, ", ") {
+			c.SANs = append(c.SANs, strings.TrimPrefix(n, "DNS:"))
+		}
+	}
+
+	var errs []error
+	for name, t := range map[string]*time.Time{"notBefore": &c.NotBefore, "notAfter": &c.NotAfter} {
+		// OpenSSL pads the day with a space, e.g. `Sep  9 00:00:00 2020 GMT`
+		v, err := time.Parse("Jan _2 15:04:05 2006 MST", strings.Join(strings.Fields(fields[name]), " "))
+		if err != nil {
+			errs = append(errs, ParseError{Line: name + ": " + fields[name], Err: err})
+			continue
+		}
+		*t = v.UTC()
+	}
+
+	return c, errors.Join(errs...)
+}
+
+func commonName(dn string) string {
+	for _, part := range strings.Split(dn, "/") {
+		if cn, ok := strings.CutPrefix(part, "CN="); ok {
+			return cn
+		}
+	}
+
+	return ""
+}
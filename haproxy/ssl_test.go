@@ -0,0 +1,68 @@
+package haproxy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseCertFiles(t *testing.T) {
+	input := `# transaction
+*/etc/haproxy/certs/site.pem
+# filename
+/etc/haproxy/certs/site.pem
+/etc/haproxy/certs/api.pem
+`
+
+	assert.Equal(t, []string{"/etc/haproxy/certs/site.pem", "/etc/haproxy/certs/api.pem"}, ParseCertFiles(input))
+	assert.Empty(t, ParseCertFiles("# filename\n"))
+}
+
+func TestParseCertTransaction(t *testing.T) {
+	assert.Equal(t, "/etc/haproxy/certs/site.pem", ParseCertTransaction("# transaction\n*/etc/haproxy/certs/site.pem\n# filename\n/etc/haproxy/certs/site.pem\n"))
+	assert.Empty(t, ParseCertTransaction("# filename\n/etc/haproxy/certs/site.pem\n"))
+}
+
+func TestParseCertificate(t *testing.T) {
+	input := `Filename: /etc/haproxy/certs/site.pem
+Status: Used
+Serial: 0D933C1B1089BF660AE5253A245BB388
+notBefore: Sep  9 00:00:00 2020 GMT
+notAfter: Sep 14 12:00:00 2021 GMT
+Subject Alternative Name: DNS:example.com, DNS:www.example.com, IP Address:10.0.0.10
+Algorithm: RSA2048
+SHA1 FingerPrint: 417A11CAE25F607B24F638B4A8AEE51D1E211477
+Subject: /C=US/O=Example/CN=www.example.org
+Issuer: /C=US/O=DigiCert Inc/CN=DigiCert TLS RSA SHA256 2020 CA1
+Chain Subject: /C=US/O=DigiCert Inc/CN=DigiCert TLS RSA SHA256 2020 CA1
+Chain Issuer: /C=US/O=DigiCert Inc/OU=www.digicert.com/CN=DigiCert Global Root CA
+
+`
+
+	c, err := ParseCertificate(input)
+	assert.NoError(t, err)
+	assert.Equal(t, Certificate{
+		File:        "/etc/haproxy/certs/site.pem",
+		Status:      "Used",
+		Serial:      "0D933C1B1089BF660AE5253A245BB388",
+		NotBefore:   time.Date(2020, 9, 9, 0, 0, 0, 0, time.UTC),
+		NotAfter:    time.Date(2021, 9, 14, 12, 0, 0, 0, time.UTC),
+		SANs:        []string{"example.com", "www.example.com", "IP Address:10.0.0.10"},
+		Algorithm:   "RSA2048",
+		Fingerprint: "417A11CAE25F607B24F638B4A8AEE51D1E211477",
+		Subject:     "/C=US/O=Example/CN=www.example.org",
+		Issuer:      "/C=US/O=DigiCert Inc/CN=DigiCert TLS RSA SHA256 2020 CA1",
+	}, c)
+	assert.Equal(t, "www.example.org", c.CommonName())
+	assert.Equal(t, "DigiCert TLS RSA SHA256 2020 CA1", c.IssuerName())
+	assert.True(t, c.ExpiresWithin(30*24*time.Hour, time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, c.ExpiresWithin(30*24*time.Hour, time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestParseCertificateMalformed(t *testing.T) {
+	_, err := ParseCertificate("Can't display the certificate: Not found or the certificate is a bundle!\n")
+	assert.ErrorContains(t, err, "missing filename")
+
+	_, err = ParseCertificate("Filename: site.pem\nnotBefore: soon\nnotAfter: Sep 14 12:00:00 2021 GMT\n")
+	assert.ErrorContains(t, err, `"notBefore: soon"`)
+}
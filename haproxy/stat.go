@@ -0,0 +1,340 @@
+package haproxy
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	FRONTEND = "FRONTEND"
+	BACKEND  = "BACKEND"
+	SERVER   = "SERVER"
+	LISTENER = "LISTENER"
+)
+
+// Stat is a line of `show stat`: the traffic numbers of a frontend, backend, server or listener
+type Stat struct {
+	ProxyName   string // pxname
+	ServiceName string // svname, FRONTEND and BACKEND for proxies, the name of servers and listeners
+	ProxyId     int    // iid
+	ServiceId   int    // sid
+	Type        string
+	Status      string
+	Weight      int
+	Address     string // addr
+	Mode        string
+
+	CurrentSessions int   // scur
+	MaxSessions     int   // smax
+	SessionLimit    int   // slim
+	TotalSessions   int64 // stot
+	SessionRate     int   // rate
+	RequestRate     int   // req_rate
+	TotalRequests   int64 // req_tot
+
+	BytesIn  int64 // bin
+	BytesOut int64 // bout
+
+	CurrentQueue int // qcur
+	MaxQueue     int // qmax
+	QueueLimit   int // qlimit
+
+	DeniedRequests   int64 // dreq
+	DeniedResponses  int64 // dresp
+	RequestErrors    int64 // ereq
+	ConnectionErrors int64 // econ
+	ResponseErrors   int64 // eresp
+	Retries          int64 // wretr
+	Redispatches     int64 // wredis
+
+	Responses HTTPResponses
+
+	// average times of the last 1024 requests in milliseconds
+	QueueTime    int // qtime
+	ConnectTime  int // ctime
+	ResponseTime int // rtime
+	TotalTime    int // ttime
+
+	CheckStatus   string // check_status
+	CheckCode     int    // check_code
+	CheckDuration int    // check_duration in milliseconds
+	CheckDesc     string // check_desc
+	LastCheck     string // last_chk
+	CheckFailures int64  // chkfail
+	CheckDowns    int64  // chkdown
+	LastChange    int    // lastchg, seconds since the last status change
+	Downtime      int    // downtime in seconds
+}
+
+// HTTPResponses counts the responses by status code class
+type HTTPResponses struct {
+	Informational int64 // hrsp_1xx
+	Success       int64 // hrsp_2xx
+	Redirection   int64 // hrsp_3xx
+	ClientError   int64 // hrsp_4xx
+	ServerError   int64 // hrsp_5xx
+	Other         int64 // hrsp_other
+}
+
+// ParseStat parses the csv output of `show stat`, malformed lines are skipped and reported as ParseError
+func ParseStat(input string) ([]Stat, error) {
+	header, body, _ := strings.Cut(strings.TrimSpace(input), "\n")
+	if !strings.HasPrefix(header, "# ") {
+		return nil, ParseError{Line: header, Err: errors.New("missing csv header")}
+	}
+	names := strings.Split(strings.TrimPrefix(header, "# "), ",")
+
+	var stats []Stat
+	var errs []error
+
+	r := csv.NewReader(strings.NewReader(body))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		fields := make(map[string]string, len(names))
+		for i, name := range names {
+			if i < len(record) {
+				fields[name] = record[i]
+			}
+		}
+
+		stat, err := statFromFields(fields)
+		if err != nil {
+			errs = append(errs, ParseError{Line: strings.Join(record, ","), Err: err})
+			continue
+		}
+		stats = append(stats, stat)
+	}
+
+	return stats, errors.Join(errs...)
+}
+
+// ParseStatTyped parses `show stat typed`, one field per line like `F.2.0.0.pxname.1:KNSV:str:http-in`
+func ParseStatTyped(input string) ([]Stat, error) {
+	var objects []map[string]string
+	var errs []error
+	last := ""
+
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		// <type>.<proxy id>.<object id>.<field pos>.<field name>.<process>:<tags>:<data type>:<value>
+		parts := strings.SplitN(line, ":", 4)
+		position := strings.Split(parts[0], ".")
+		if len(parts) != 4 || len(position) != 6 {
+			errs = append(errs, ParseError{Line: line, Err: errors.New("expected <object>:<tags>:<type>:<value>")})
+			continue
+		}
+
+		// the fields of an object are listed one after another
+		if key := strings.Join(position[:3], "."); key != last {
+			last = key
+			objects = append(objects, map[string]string{"": objectType(position[0])})
+		}
+		objects[len(objects)-1][position[4]] = parts[3]
+	}
+
+	stats, err := statsFromObjects(objects)
+
+	return stats, errors.Join(append(errs, err)...)
+}
+
+// ParseStatJSON parses `show stat json`, a list of objects each given as a list of fields
+func ParseStatJSON(input string) ([]Stat, error) {
+	var raw [][]struct {
+		ObjType string `json:"objType"`
+		Field   struct {
+			Name string `json:"name"`
+		} `json:"field"`
+		Value struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"value"`
+	}
+
+	d := json.NewDecoder(strings.NewReader(input))
+	if err := d.Decode(&raw); err != nil {
+		return nil, ParseError{Line: strings.SplitN(strings.TrimSpace(input), "\n", 2)[0], Err: err}
+	}
+
+	var objects []map[string]string
+	for _, fields := range raw {
+		o := map[string]string{}
+		for _, f := range fields {
+			o[""] = objectType(f.ObjType)
+			o[f.Field.Name] = jsonValue(f.Value.Value)
+		}
+		objects = append(objects, o)
+	}
+
+	return statsFromObjects(objects)
+}
+
+func statsFromObjects(objects []map[string]string) ([]Stat, error) {
+	var stats []Stat
+	var errs []error
+
+	for _, o := range objects {
+		stat, err := statFromFields(o)
+		if err != nil {
+			errs = append(errs, ParseError{Line: o["pxname"] + "/" + o["svname"], Err: err})
+			continue
+		}
+		stats = append(stats, stat)
+	}
+
+	return stats, errors.Join(errs...)
+}
+
+// statFromFields maps the fields by their name, as they are numbered differently across HAProxy versions.
+// The object type of the typed and json formats is passed with the empty name.
+func statFromFields(fields map[string]string) (Stat, error) {
+	p := fieldParser{fields: fields}
+
+	s := Stat{
+		ProxyName:   fields["pxname"],
+		ServiceName: fields["svname"],
+		ProxyId:     p.int("iid"),
+		ServiceId:   p.int("sid"),
+		Type:        statType(fields),
+		Status:      fields["status"],
+		Weight:      p.int("weight"),
+		Address:     fields["addr"],
+		Mode:        fields["mode"],
+
+		CurrentSessions: p.int("scur"),
+		MaxSessions:     p.int("smax"),
+		SessionLimit:    p.int("slim"),
+		TotalSessions:   p.int64("stot"),
+		SessionRate:     p.int("rate"),
+		RequestRate:     p.int("req_rate"),
+		TotalRequests:   p.int64("req_tot"),
+
+		BytesIn:  p.int64("bin"),
+		BytesOut: p.int64("bout"),
+
+		CurrentQueue: p.int("qcur"),
+		MaxQueue:     p.int("qmax"),
+		QueueLimit:   p.int("qlimit"),
+
+		DeniedRequests:   p.int64("dreq"),
+		DeniedResponses:  p.int64("dresp"),
+		RequestErrors:    p.int64("ereq"),
+		ConnectionErrors: p.int64("econ"),
+		ResponseErrors:   p.int64("eresp"),
+		Retries:          p.int64("wretr"),
+		Redispatches:     p.int64("wredis"),
+
+		Responses: HTTPResponses{
+			Informational: p.int64("hrsp_1xx"),
+			Success:       p.int64("hrsp_2xx"),
+			Redirection:   p.int64("hrsp_3xx"),
+			ClientError:   p.int64("hrsp_4xx"),
+			ServerError:   p.int64("hrsp_5xx"),
+			Other:         p.int64("hrsp_other"),
+		},
+
+		QueueTime:    p.int("qtime"),
+		ConnectTime:  p.int("ctime"),
+		ResponseTime: p.int("rtime"),
+		TotalTime:    p.int("ttime"),
+
+		CheckStatus:   fields["check_status"],
+		CheckCode:     p.int("check_code"),
+		CheckDuration: p.int("check_duration"),
+		CheckDesc:     fields["check_desc"],
+		LastCheck:     fields["last_chk"],
+		CheckFailures: p.int64("chkfail"),
+		CheckDowns:    p.int64("chkdown"),
+		LastChange:    p.int("lastchg"),
+		Downtime:      p.int("downtime"),
+	}
+
+	if s.ProxyName == "" {
+		return Stat{}, errors.New("missing pxname")
+	}
+
+	return s, p.err
+}
+
+// fieldParser parses numeric fields by name, an empty or missing field (not applicable, or unknown to the HAProxy version) is 0
+type fieldParser struct {
+	fields map[string]string
+	err    error
+}
+
+func (p *fieldParser) int64(name string) int64 {
+	v := p.fields[name]
+	if v == "" {
+		return 0
+	}
+
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("field %s is not a number: %q", name, v)
+	}
+
+	return i
+}
+
+func (p *fieldParser) int(name string) int {
+	return int(p.int64(name))
+}
+
+// statType is the type of the object, given by the `type` field (0 frontend, 1 backend, 2 server, 3 listener)
+func statType(fields map[string]string) string {
+	switch fields["type"] {
+	case "0":
+		return FRONTEND
+	case "1":
+		return BACKEND
+	case "2":
+		return SERVER
+	case "3":
+		return LISTENER
+	}
+
+	return fields[""]
+}
+
+// objectType maps the object type of the typed (F, B, S, L) and json (Frontend, ...) formats
+func objectType(t string) string {
+	switch strings.ToUpper(t) {
+	case "F", FRONTEND:
+		return FRONTEND
+	case "B", BACKEND:
+		return BACKEND
+	case "S", SERVER:
+		return SERVER
+	case "L", LISTENER:
+		return LISTENER
+	}
+
+	return t
+}
+
+// jsonValue returns strings unquoted and numbers as they are
+func jsonValue(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	return string(bytes.TrimSpace(raw))
+}
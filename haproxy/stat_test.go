@@ -0,0 +1,140 @@
+package haproxy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// output of `show stat` of HAProxy 3.0
+const sampleStat = `# pxname,svname,qcur,qmax,scur,smax,slim,stot,bin,bout,dreq,dresp,ereq,econ,eresp,wretr,wredis,status,weight,act,bck,chkfail,chkdown,lastchg,downtime,qlimit,pid,iid,sid,throttle,lbtot,tracked,type,rate,rate_lim,rate_max,check_status,check_code,check_duration,hrsp_1xx,hrsp_2xx,hrsp_3xx,hrsp_4xx,hrsp_5xx,hrsp_other,hanafail,req_rate,req_rate_max,req_tot,cli_abrt,srv_abrt,comp_in,comp_out,comp_byp,comp_rsp,lastsess,last_chk,last_agt,qtime,ctime,rtime,ttime,agent_status,agent_code,agent_duration,check_desc,agent_desc,check_rise,check_fall,check_health,agent_rise,agent_fall,agent_health,addr,cookie,mode,algo,conn_rate,conn_rate_max,conn_tot,intercepted,dcon,dses,wrew,connect,reuse,cache_lookups,cache_hits,srv_icur,src_ilim,qtime_max,ctime_max,rtime_max,ttime_max,eint,idle_conn_cur,safe_conn_cur,used_conn_cur,need_conn_est,uweight,agg_server_status,agg_server_check_status,agg_check_status,srid,sess_other,h1sess,h2sess,h3sess,req_other,h1req,h2req,h3req,proto,
+http-in,FRONTEND,,,3,12,262120,1542,2384511,48211037,4,0,7,,,,,OPEN,,,,,,,,,1,2,,,,,0,2,0,31,,,,0,1489,21,19,6,0,,2,33,1535,,,0,0,0,0,,,,,,,,,,,,,,,,,,,,,http,,2,31,1542,0,0,0,0,,,0,0,,,,,,,0,,,,,,,,,,0,1542,0,0,0,1535,0,0,,
+default,haproxy,0,0,1,6,,771,1192001,24101312,,0,,0,1,0,0,UP,20,1,0,0,0,8812,0,,1,4,1,,771,,2,1,,15,L7OK,200,3,0,748,11,9,3,0,,,,,0,0,,,,,1,OK,,0,1,12,240,,,,Layer7 check passed,,2,3,4,,,,209.126.35.1:443,,http,,,,,,,,,771,0,,,0,,0,9,131,1873,0,0,0,1,1,20,,,,1,,,,,,,,,,
+default,apache,2,5,0,4,,770,1191204,24099101,,0,,2,0,1,0,DOWN,80,1,0,3,1,42,42,,1,4,2,,770,,2,0,,16,L4CON,,0,0,741,10,10,3,0,,,,,0,0,,,,,45,Connection refused,,0,2,15,251,,,,Layer4 connection problem,,2,3,0,,,,151.101.2.132:443,,http,,,,,,,,,770,0,,,0,,3,11,140,1911,0,0,0,0,0,80,,,,1,,,,,,,,,,
+default,BACKEND,2,5,1,10,26212,1541,2383205,48200413,0,0,,2,1,1,0,UP,20,1,0,,0,8812,0,,1,4,0,,1541,,1,1,,31,,,,0,1489,21,19,6,0,,,,1535,0,0,0,0,0,0,1,,,0,1,13,245,,,,,,,,,,,,,,http,roundrobin,,,,,,,,1541,0,0,0,,,3,11,140,1911,0,0,0,1,1,100,1,1,1,0,,,,,,,,,,
+`
+
+// excerpt of `show stat typed`
+const sampleStatTyped = `F.2.0.0.pxname.1:KNSV:str:http-in
+F.2.0.1.svname.1:KNSV:str:FRONTEND
+F.2.0.4.scur.1:MGPV:u32:3
+F.2.0.7.stot.1:MCPV:u64:1542
+F.2.0.8.bin.1:MCPV:u64:2384511
+F.2.0.9.bout.1:MCPV:u64:48211037
+F.2.0.17.status.1:SGPV:str:OPEN
+F.2.0.27.iid.1:KGPV:u32:2
+F.2.0.32.type.1:CGPV:u32:0
+F.2.0.40.hrsp_2xx.1:MCPV:u64:1489
+S.4.2.0.pxname.1:KNSV:str:default
+S.4.2.1.svname.1:KNSV:str:apache
+S.4.2.17.status.1:SGPV:str:DOWN
+S.4.2.36.check_status.1:SGPV:str:L4CON
+S.4.2.56.last_chk.1:SGPV:str:Connection refused
+S.4.2.73.addr.1:CGPV:str:151.101.2.132:443
+`
+
+// excerpt of `show stat json`
+const sampleStatJSON = `[
+  [
+    {"objType":"Server","proxyId":4,"id":1,"field":{"pos":0,"name":"pxname"},"processNum":1,"tags":{"origin":"Key","nature":"Name","scope":"Service"},"value":{"type":"str","value":"default"}},
+    {"objType":"Server","proxyId":4,"id":1,"field":{"pos":1,"name":"svname"},"processNum":1,"tags":{"origin":"Key","nature":"Name","scope":"Service"},"value":{"type":"str","value":"haproxy"}},
+    {"objType":"Server","proxyId":4,"id":1,"field":{"pos":4,"name":"scur"},"processNum":1,"tags":{"origin":"Metric","nature":"Gauge","scope":"Process"},"value":{"type":"u32","value":1}},
+    {"objType":"Server","proxyId":4,"id":1,"field":{"pos":17,"name":"status"},"processNum":1,"tags":{"origin":"Status","nature":"Output","scope":"Process"},"value":{"type":"str","value":"UP"}},
+    {"objType":"Server","proxyId":4,"id":1,"field":{"pos":60,"name":"rtime"},"processNum":1,"tags":{"origin":"Metric","nature":"Average","scope":"Process"},"value":{"type":"u32","value":12}}
+  ],
+  [
+    {"objType":"Backend","proxyId":4,"id":0,"field":{"pos":0,"name":"pxname"},"processNum":1,"tags":{"origin":"Key","nature":"Name","scope":"Service"},"value":{"type":"str","value":"default"}},
+    {"objType":"Backend","proxyId":4,"id":0,"field":{"pos":1,"name":"svname"},"processNum":1,"tags":{"origin":"Key","nature":"Name","scope":"Service"},"value":{"type":"str","value":"BACKEND"}},
+    {"objType":"Backend","proxyId":4,"id":0,"field":{"pos":8,"name":"bin"},"processNum":1,"tags":{"origin":"Metric","nature":"Counter","scope":"Process"},"value":{"type":"u64","value":2383205}}
+  ]
+]
+`
+
+func TestParseStat(t *testing.T) {
+	res, err := ParseStat(sampleStat)
+
+	assert.Nil(t, err)
+	assert.Len(t, res, 4)
+
+	frontend := res[0]
+	assert.Equal(t, FRONTEND, frontend.Type)
+	assert.Equal(t, "http-in", frontend.ProxyName)
+	assert.Equal(t, "OPEN", frontend.Status)
+	assert.Equal(t, 3, frontend.CurrentSessions)
+	assert.Equal(t, int64(1542), frontend.TotalSessions)
+	assert.Equal(t, int64(2384511), frontend.BytesIn)
+	assert.Equal(t, int64(48211037), frontend.BytesOut)
+	assert.Equal(t, int64(7), frontend.RequestErrors)
+	assert.Equal(t, HTTPResponses{Success: 1489, Redirection: 21, ClientError: 19, ServerError: 6}, frontend.Responses)
+	assert.Equal(t, "http", frontend.Mode)
+
+	server := res[2]
+	assert.Equal(t, SERVER, server.Type)
+	assert.Equal(t, "apache", server.ServiceName)
+	assert.Equal(t, 4, server.ProxyId)
+	assert.Equal(t, 2, server.ServiceId)
+	assert.Equal(t, "DOWN", server.Status)
+	assert.Equal(t, 2, server.CurrentQueue)
+	assert.Equal(t, int64(2), server.ConnectionErrors)
+	assert.Equal(t, 15, server.ResponseTime)
+	assert.Equal(t, "L4CON", server.CheckStatus)
+	assert.Equal(t, "Connection refused", server.LastCheck)
+	assert.Equal(t, "Layer4 connection problem", server.CheckDesc)
+	assert.Equal(t, int64(3), server.CheckFailures)
+	assert.Equal(t, 42, server.Downtime)
+	assert.Equal(t, "151.101.2.132:443", server.Address)
+
+	assert.Equal(t, BACKEND, res[3].Type)
+	assert.Equal(t, int64(1535), res[3].TotalRequests)
+}
+
+func TestParseStatWithMalformedLine(t *testing.T) {
+	res, err := ParseStat(strings.Replace(sampleStat, "default,apache,2,5,0", "default,apache,2,5,x", 1))
+
+	assert.Len(t, res, 3)
+	assert.ErrorAs(t, err, &ParseError{})
+	assert.Contains(t, err.Error(), `field scur is not a number: "x"`)
+
+	_, err = ParseStat("Unknown command: 'show stat foo'")
+	assert.ErrorAs(t, err, &ParseError{})
+}
+
+func TestParseStatTyped(t *testing.T) {
+	res, err := ParseStatTyped(sampleStatTyped)
+
+	assert.Nil(t, err)
+	assert.Len(t, res, 2)
+
+	assert.Equal(t, FRONTEND, res[0].Type)
+	assert.Equal(t, "http-in", res[0].ProxyName)
+	assert.Equal(t, int64(48211037), res[0].BytesOut)
+	assert.Equal(t, int64(1489), res[0].Responses.Success)
+
+	// the object type is taken from the prefix if the type field is missing
+	assert.Equal(t, SERVER, res[1].Type)
+	assert.Equal(t, "apache", res[1].ServiceName)
+	assert.Equal(t, "Connection refused", res[1].LastCheck)
+	assert.Equal(t, "151.101.2.132:443", res[1].Address)
+
+	_, err = ParseStatTyped("F.2.0.0.pxname.1:KNSV:str:http-in\nfoo")
+	assert.ErrorAs(t, err, &ParseError{})
+}
+
+func TestParseStatJSON(t *testing.T) {
+	res, err := ParseStatJSON(sampleStatJSON)
+
+	assert.Nil(t, err)
+	assert.Len(t, res, 2)
+
+	assert.Equal(t, SERVER, res[0].Type)
+	assert.Equal(t, "haproxy", res[0].ServiceName)
+	assert.Equal(t, 1, res[0].CurrentSessions)
+	assert.Equal(t, "UP", res[0].Status)
+	assert.Equal(t, 12, res[0].ResponseTime)
+
+	assert.Equal(t, BACKEND, res[1].Type)
+	assert.Equal(t, int64(2383205), res[1].BytesIn)
+
+	_, err = ParseStatJSON("Unknown command")
+	assert.ErrorAs(t, err, &ParseError{})
+}
@@ -0,0 +1,138 @@
+package haproxy
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Table is a stick table as listed by `show table`
+type Table struct {
+	Name string
+	Type string
+	Size int
+	Used int
+}
+
+// ParseTables parses the headers of `show table`, like `# table: http-in, type: ip, size:102400, used:2`
+func ParseTables(input string) ([]Table, error) {
+	var tables []Table
+	var errs []error
+
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "# table:") {
+			continue
+		}
+
+		fields := map[string]string{}
+		for _, f := range strings.Split(strings.TrimPrefix(line, "# "), ",") {
+			k, v, _ := strings.Cut(f, ":")
+			fields[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+		if fields["table"] == "" {
+			errs = append(errs, ParseError{Line: line, Err: fmt.Errorf("missing table name")})
+			continue
+		}
+
+		p := fieldParser{fields: fields}
+		t := Table{Name: fields["table"], Type: fields["type"], Size: p.int("size"), Used: p.int("used")}
+		if p.err != nil {
+			errs = append(errs, ParseError{Line: line, Err: p.err})
+			continue
+		}
+		tables = append(tables, t)
+	}
+
+	return tables, errors.Join(errs...)
+}
+
+// TableEntry is an entry of a stick table as listed by `show table <name>`
+type TableEntry struct {
+	Ref string
+	Key string
+	Use int
+	// Expire is the time left until the entry expires, in milliseconds
+	Expire int
+	// Data are the stored data types, in the order of the table
+	Data []TableData
+}
+
+// TableData is a data type stored in a stick table with its value, like `http_req_rate(10000)=42`
+type TableData struct {
+	Name  string
+	Value string
+}
+
+// Type is the name of the data type without its period, as used by `data.<type>` arguments
+func (d TableData) Type() string {
+	name, _, _ := strings.Cut(d.Name, "(")
+	return name
+}
+
+// Int is the value of a counter, false for data types like `server_key` which are no numbers
+func (e TableEntry) Int(dataType string) (int64, bool) {
+	for _, d := range e.Data {
+		if d.Type() == dataType {
+			v, err := strconv.ParseInt(d.Value, 10, 64)
+			return v, err == nil
+		}
+	}
+
+	return 0, false
+}
+
+// ParseTableEntries parses the entries of `show table <name>`, lines like
+// `0x55d3c0f1e2a0: key=10.0.0.1 use=0 exp=28000 shard=0 conn_cur=1 http_req_rate(10000)=42`
+func ParseTableEntries(input string) ([]TableEntry, error) {
+	var entries []TableEntry
+	var errs []error
+
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		e, err := parseTableEntry(line)
+		if err != nil {
+			errs = append(errs, ParseError{Line: line, Err: err})
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, errors.Join(errs...)
+}
+
+func parseTableEntry(line string) (TableEntry, error) {
+	ref, rest, ok := strings.Cut(line, ": ")
+	if !ok {
+		return TableEntry{}, fmt.Errorf("missing ref")
+	}
+
+	e := TableEntry{Ref: ref}
+	fields := map[string]string{}
+	for _, f := range strings.Fields(rest) {
+		k, v, _ := strings.Cut(f, "=")
+		switch k {
+		case "key", "use", "exp":
+			fields[k] = v
+		case "shard":
+			// the shard of the entry is an internal detail of HAProxy 3.0
+		default:
+			e.Data = append(e.Data, TableData{Name: k, Value: v})
+		}
+	}
+	if _, ok := fields["key"]; !ok {
+		return TableEntry{}, fmt.Errorf("missing key")
+	}
+
+	p := fieldParser{fields: fields}
+	e.Key = fields["key"]
+	e.Use = p.int("use")
+	e.Expire = p.int("exp")
+
+	return e, p.err
+}
@@ -0,0 +1,59 @@
+package haproxy
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseTables(t *testing.T) {
+	input := `# table: http-in, type: ip, size:102400, used:2
+# table: sessions, type: string, size:1024, used:0
+0x55d3c0f1e2a0: key=10.0.0.1 use=0 exp=28000 shard=0 conn_cur=1
+`
+
+	tables, err := ParseTables(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []Table{
+		{Name: "http-in", Type: "ip", Size: 102400, Used: 2},
+		{Name: "sessions", Type: "string", Size: 1024},
+	}, tables)
+}
+
+func TestParseTablesMalformed(t *testing.T) {
+	tables, err := ParseTables("# table: http-in, type: ip, size:big, used:2\n")
+
+	assert.Empty(t, tables)
+	assert.ErrorContains(t, err, "field size is not a number")
+}
+
+func TestParseTableEntries(t *testing.T) {
+	input := `# table: http-in, type: ip, size:102400, used:2
+0x55d3c0f1e2a0: key=10.0.0.1 use=0 exp=28000 shard=0 conn_cur=1 http_req_rate(10000)=42
+0x55d3c0f1e2c0: key=10.0.0.2 use=1 exp=29500 shard=0 server_key=apache conn_cur=0 http_req_rate(10000)=3
+`
+
+	entries, err := ParseTableEntries(input)
+	assert.NoError(t, err)
+	assert.Equal(t, []TableEntry{
+		{Ref: "0x55d3c0f1e2a0", Key: "10.0.0.1", Expire: 28000, Data: []TableData{{"conn_cur", "1"}, {"http_req_rate(10000)", "42"}}},
+		{Ref: "0x55d3c0f1e2c0", Key: "10.0.0.2", Use: 1, Expire: 29500, Data: []TableData{{"server_key", "apache"}, {"conn_cur", "0"}, {"http_req_rate(10000)", "3"}}},
+	}, entries)
+
+	assert.Equal(t, "http_req_rate", entries[0].Data[1].Type())
+	rate, ok := entries[0].Int("http_req_rate")
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), rate)
+	_, ok = entries[1].Int("server_key")
+	assert.False(t, ok)
+	_, ok = entries[1].Int("gpc0")
+	assert.False(t, ok)
+}
+
+func TestParseTableEntriesMalformed(t *testing.T) {
+	entries, err := ParseTableEntries("0x1: use=0\n0x2 key=a\n0x3: key=b exp=soon\n0x4: key=c\n")
+
+	assert.Len(t, entries, 1)
+	assert.ErrorContains(t, err, "missing key")
+	assert.ErrorContains(t, err, "missing ref")
+	assert.ErrorContains(t, err, "field exp is not a number")
+}
@@ -0,0 +1,203 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// MaxEntries are kept, older entries are dropped
+const MaxEntries = 1000
+
+// maxResponse is the size of a response kept with its command, huge outputs like `show sess all` are cut
+const maxResponse = 64 << 10
+
+// maxLine is the longest line read, a response escapes to at most 6 bytes per byte (`\u001b`). Longer lines
+// are not written by Add and are skipped.
+const maxLine = 6*maxResponse + 4096
+
+// Entry is an executed command with its response
+type Entry struct {
+	Command  string    `json:"command"`
+	Response string    `json:"response"`
+	Time     time.Time `json:"time"`
+}
+
+// History keeps the executed commands of a socket, oldest first. It is persisted as json lines
+// if it has a path, entries are appended and the file is compacted once it holds twice MaxEntries.
+type History struct {
+	path    string
+	entries []Entry
+	// lines counts the entries in the file
+	lines int
+}
+
+// New creates a history which is not persisted
+func New() *History {
+	return &History{}
+}
+
+// Load reads the history of the given file, which is created with the first entry
+func Load(path string) (*History, error) {
+	h := &History{path: path}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return h, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			var e Entry
+			// a broken or overlong line, e.g. of a crash while writing, doesn't spoil the rest
+			if len(line) <= maxLine && json.Unmarshal(line, &e) == nil {
+				h.append(e)
+			}
+			h.lines++
+		}
+		if err == io.EOF {
+			return h, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Path is the history file of a socket in the state directory of the user
+func Path(socket string) (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.Trim(unsafeChars.ReplaceAllString(socket, "_"), "_")
+
+	return filepath.Join(dir, "haproxy-runtime-cli", "history", name+".jsonl"), nil
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// stateDir follows the XDG base directory spec, with the config directory as fallback on macOS and windows
+func stateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return dir, nil
+	}
+
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		return os.UserConfigDir()
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "state"), nil
+}
+
+// Entries returns the entries, oldest first
+func (h *History) Entries() []Entry {
+	return h.entries
+}
+
+// Add appends an executed command, repeating the last command only updates its response
+func (h *History) Add(e Entry) error {
+	if len(e.Response) > maxResponse {
+		e.Response = e.Response[:maxResponse]
+	}
+	h.append(e)
+
+	if h.path == "" {
+		return nil
+	}
+	if h.lines >= 2*MaxEntries {
+		return h.compact()
+	}
+
+	line, err := marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h.lines++
+	_, err = f.Write(line)
+
+	return err
+}
+
+// Search returns the index of the newest entry before the given index whose command contains the query
+func (h *History) Search(query string, before int) (int, bool) {
+	for i := min(before, len(h.entries)) - 1; i >= 0; i-- {
+		if strings.Contains(h.entries[i].Command, query) {
+			return i, true
+		}
+	}
+
+	return -1, false
+}
+
+func (h *History) append(e Entry) {
+	if n := len(h.entries); n > 0 && h.entries[n-1].Command == e.Command {
+		h.entries[n-1] = e
+		return
+	}
+
+	h.entries = append(h.entries, e)
+	if len(h.entries) > MaxEntries {
+		h.entries = h.entries[len(h.entries)-MaxEntries:]
+	}
+}
+
+// compact rewrites the file with the kept entries
+func (h *History) compact() error {
+	var b strings.Builder
+	for _, e := range h.entries {
+		line, err := marshal(e)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+	}
+
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o600); err != nil {
+		return err
+	}
+	h.lines = len(h.entries)
+
+	return os.Rename(tmp, h.path)
+}
+
+// marshal encodes an entry as a json line, `<>&` are kept as is instead of being escaped to 6 bytes
+func marshal(e Entry) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(e); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
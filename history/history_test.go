@@ -0,0 +1,109 @@
+package history
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "socket.jsonl")
+
+	h, err := Load(path)
+	assert.NoError(t, err)
+	assert.Empty(t, h.Entries())
+
+	now := time.Now().UTC().Truncate(time.Second)
+	assert.NoError(t, h.Add(Entry{Command: "show info", Response: "Name: HAProxy", Time: now}))
+	assert.NoError(t, h.Add(Entry{Command: "show stat", Response: "# pxname", Time: now}))
+	// a repeated command only updates the last entry
+	assert.NoError(t, h.Add(Entry{Command: "show stat", Response: "# pxname,svname", Time: now}))
+
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Command: "show info", Response: "Name: HAProxy", Time: now},
+		{Command: "show stat", Response: "# pxname,svname", Time: now},
+	}, loaded.Entries())
+}
+
+func TestHistoryBrokenLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "socket.jsonl")
+	assert.NoError(t, os.WriteFile(path, []byte("{\"command\":\"show info\"}\n{\"comm\n{\"command\":\"help\"}\n"), 0o600))
+
+	h, err := Load(path)
+	assert.NoError(t, err)
+	assert.Len(t, h.Entries(), 2)
+}
+
+func TestHistoryLongResponse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "socket.jsonl")
+	h, _ := Load(path)
+
+	// html characters are not escaped, escaped control characters take 6 bytes each
+	assert.NoError(t, h.Add(Entry{Command: "show table", Response: strings.Repeat("<>&", maxResponse)}))
+	assert.NoError(t, h.Add(Entry{Command: "show errors", Response: strings.Repeat("\x1b", maxResponse)}))
+
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, h.Entries(), loaded.Entries())
+
+	// a line too long to be written by Add is skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	assert.NoError(t, err)
+	_, err = f.WriteString("{\"command\":\"" + strings.Repeat("x", maxLine) + "\"}\n{\"command\":\"help\"}\n")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	loaded, err = Load(path)
+	assert.NoError(t, err)
+	assert.Len(t, loaded.Entries(), 3)
+	assert.Equal(t, "help", loaded.Entries()[2].Command)
+}
+
+func TestHistoryLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "socket.jsonl")
+	h, _ := Load(path)
+
+	assert.NoError(t, h.Add(Entry{Command: "show sess all", Response: strings.Repeat("x", 2*maxResponse)}))
+	assert.Len(t, h.Entries()[0].Response, maxResponse)
+
+	for i := range 2 * MaxEntries {
+		assert.NoError(t, h.Add(Entry{Command: "echo " + strings.Repeat("x", i%3+1) + string(rune('a'+i%26))}))
+	}
+	assert.Len(t, h.Entries(), MaxEntries)
+
+	// the file was compacted
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, h.Entries(), loaded.Entries())
+	assert.LessOrEqual(t, loaded.lines, 2*MaxEntries)
+}
+
+func TestHistorySearch(t *testing.T) {
+	h := New()
+	for _, c := range []string{"show info", "show stat", "show map", "help"} {
+		assert.NoError(t, h.Add(Entry{Command: c}))
+	}
+
+	i, ok := h.Search("show", 4)
+	assert.True(t, ok)
+	assert.Equal(t, 2, i)
+
+	i, _ = h.Search("show", i)
+	assert.Equal(t, 1, i)
+
+	_, ok = h.Search("clear", 4)
+	assert.False(t, ok)
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+
+	path, err := Path("unix:///var/run/haproxy.sock")
+	assert.NoError(t, err)
+	assert.Equal(t, "/state/haproxy-runtime-cli/history/unix_var_run_haproxy.sock.jsonl", path)
+}
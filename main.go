@@ -1,13 +1,17 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
+	"haproxy-runtime-cli/components"
+	"haproxy-runtime-cli/fake"
+	"haproxy-runtime-cli/history"
 	"haproxy-runtime-cli/socket"
 	"haproxy-runtime-cli/styles"
 	"log"
-	"net"
 	"os"
+	"time"
 )
 
 var (
@@ -18,35 +22,141 @@ var (
 
 func main() {
 	args := os.Args[1:]
-	parseCommandLine(args)
+	if len(args) > 0 && args[0] == "exec" {
+		os.Exit(runExec(args[1:], os.Stdin, os.Stdout, os.Stderr))
+	}
+
+	os.Exit(runUi(args))
+}
+
+// runUi runs the ui and returns the process exit code, so the connection and the demo socket are closed before exiting
+func runUi(args []string) int {
+	endpoint, opts, closeSocket := parseCommandLine(args)
+	defer closeSocket()
+
+	client := socket.NewClient(endpoint.Dial)
+	client.SetTimeout(opts.timeout)
+	defer client.Close()
 
-	openSocket := func() net.Conn {
-		return socket.Listen(args[0])
+	api := NewRuntimeApi(client)
+	api.SetRefreshInterval(opts.refresh)
+	if !opts.demo {
+		api.SetHistory(loadHistory(endpoint))
 	}
 
-	p := tea.NewProgram(NewRuntimeApi(openSocket), tea.WithAltScreen())
+	p := tea.NewProgram(api, tea.WithAltScreen())
 
 	if _, err := p.Run(); err != nil {
-		log.Fatal(styles.ErrorStyle.Render(fmt.Sprintf("Alas, there's been an error: %v", err)))
+		log.Print(styles.ErrorStyle.Render(fmt.Sprintf("Alas, there's been an error: %v", err)))
+		return 1
 	}
+
+	return 0
 }
 
-func parseCommandLine(args []string) {
-	if len(args) == 0 {
-		log.Fatal(styles.ErrorStyle.Render("Please specify a haproxy socket as argument"))
+// options tune the ui
+type options struct {
+	timeout time.Duration
+	refresh time.Duration
+	// demo runs against a fake haproxy, its history is not kept
+	demo bool
+}
+
+func parseCommandLine(args []string) (socket.Endpoint, options, func()) {
+	flags := flag.NewFlagSet(styles.AppName, flag.ExitOnError)
+	showVersion := flags.Bool("version", false, "print the version")
+	flags.BoolVar(showVersion, "v", false, "print the version")
+	demo := flags.Bool("demo", false, "connect to a built-in fake haproxy")
+	tlsOptions := tlsFlags(flags)
+	timeout := timeoutFlag(flags)
+	refresh := flags.Duration("refresh", components.DefaultRefreshInterval, "interval of the auto refresh of the status page, 0 starts with it switched off")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(flags.Output(), "Usage: %s [flags] <socket>\n       %s exec [flags] <socket> [command]\n\n", styles.AppName, styles.AppName)
+		_, _ = fmt.Fprintln(flags.Output(), "<socket> is a path, unix:///path/to.sock or tcp://host:port")
+		flags.PrintDefaults()
 	}
+	_ = flags.Parse(args)
 
-	if args[0] == "-v" || args[0] == "--version" {
+	if *showVersion {
 		fmt.Println(styles.ActiveStyle.Render(fmt.Sprintf("%s, commit %s, built at %s by digitalkaoz", version, commit, date)))
 		os.Exit(0)
 	}
 
-	stat, err := os.Stat(args[0])
+	opts := options{timeout: *timeout, refresh: *refresh, demo: *demo}
+
+	if *demo {
+		endpoint, closeSocket := demoSocket()
+		return endpoint, opts, closeSocket
+	}
+
+	if flags.NArg() == 0 {
+		log.Fatal(styles.ErrorStyle.Render("Please specify a haproxy socket as argument"))
+	}
+
+	endpoint, err := socket.ParseEndpoint(flags.Arg(0), *tlsOptions)
+	if err != nil {
+		log.Fatal(styles.ErrorStyle.Render(err.Error()))
+	}
+
+	if endpoint.Network == "unix" {
+		stat, err := os.Stat(endpoint.Address)
+		if err != nil {
+			log.Fatal(styles.ErrorStyle.Render(err.Error()))
+		}
+
+		if stat.IsDir() {
+			log.Fatal(styles.ErrorStyle.Render(fmt.Sprintf(`%s is not a valid haproxy socket`, endpoint.Address)))
+		}
+	}
+
+	return endpoint, opts, func() {}
+}
+
+// loadHistory reads the persisted history of the socket, without it the history is only kept until exit
+func loadHistory(endpoint socket.Endpoint) *history.History {
+	path, err := history.Path(endpoint.String())
+	if err != nil {
+		return history.New()
+	}
+
+	h, err := history.Load(path)
+	if err != nil {
+		log.Print(styles.ErrorBarStyle.Render("history not loaded: " + err.Error()))
+		return history.New()
+	}
+
+	return h
+}
+
+// tlsFlags registers the flags to connect to a tls terminated socket
+func tlsFlags(flags *flag.FlagSet) *socket.TLSOptions {
+	o := &socket.TLSOptions{}
+	flags.BoolVar(&o.Enabled, "tls", false, "connect to the socket with tls")
+	flags.StringVar(&o.CAFile, "tls-ca", "", "verify the server certificate against the ca certificates in `file`")
+	flags.StringVar(&o.CertFile, "tls-cert", "", "client certificate `file` (pem)")
+	flags.StringVar(&o.KeyFile, "tls-key", "", "client certificate key `file` (pem)")
+	flags.StringVar(&o.ServerName, "tls-server-name", "", "expected `name` in the server certificate, defaults to the socket host")
+	flags.BoolVar(&o.InsecureSkipVerify, "tls-insecure", false, "skip the verification of the server certificate")
+
+	return o
+}
+
+// timeoutFlag registers the flag for the time HAProxy has to answer a command
+func timeoutFlag(flags *flag.FlagSet) *time.Duration {
+	return flags.Duration("timeout", socket.DefaultTimeout, "time haproxy has to answer a command, 0 waits forever")
+}
+
+// demoSocket serves a fake haproxy with some demo state on a temporary socket
+func demoSocket() (socket.Endpoint, func()) {
+	srv, err := fake.NewServer()
 	if err != nil {
 		log.Fatal(styles.ErrorStyle.Render(err.Error()))
 	}
 
-	if stat.IsDir() {
-		log.Fatal(styles.ErrorStyle.Render(fmt.Sprintf(`%s is not a valid haproxy socket`, args[0])))
+	endpoint, err := socket.ParseEndpoint(srv.Addr, socket.TLSOptions{})
+	if err != nil {
+		log.Fatal(styles.ErrorStyle.Render(err.Error()))
 	}
+
+	return endpoint, func() { _ = srv.Close() }
 }
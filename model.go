@@ -3,9 +3,13 @@ package main
 import (
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"haproxy-runtime-cli/components"
+	"haproxy-runtime-cli/history"
+	"haproxy-runtime-cli/socket"
 	"haproxy-runtime-cli/styles"
-	"net"
+	"strings"
+	"time"
 )
 
 type sessionState uint
@@ -14,34 +18,84 @@ const (
 	commandsListPage sessionState = iota
 	executePage
 	statusPage
+	processesPage
+	frontendsPage
+	consolePage
+	mapsPage
+	aclsPage
+	tablesPage
+	sessionsPage
+	certsPage
 )
 
 type RuntimeAPI struct {
-	page         sessionState
-	commandsPage components.CommandsPage
-	statusPage   components.StatusPage
-	executePage  components.ExecutePage
+	page          sessionState
+	socket        *socket.Client
+	commandsPage  components.CommandsPage
+	statusPage    components.StatusPage
+	executePage   components.ExecutePage
+	processesPage components.ProcessesPage
+	frontendsPage components.FrontendsPage
+	consolePage   components.ConsolePage
+	mapsPage      components.MapsPage
+	aclsPage      components.AclsPage
+	tablesPage    components.TablesPage
+	sessionsPage  components.SessionsPage
+	certsPage     components.CertsPage
+	errorBar      components.ErrorBar
 }
 
 type ActivePage interface {
 	Supports(tea.Msg, bool) bool
 }
 
-func NewRuntimeApi(socket func() net.Conn) RuntimeAPI {
+func NewRuntimeApi(socket *socket.Client) RuntimeAPI {
 	return RuntimeAPI{
-		page:         statusPage,
-		commandsPage: components.NewCommandsPage(socket),
-		statusPage:   components.NewStatusPage(socket),
-		executePage:  components.NewExecutePage(socket),
+		page:          statusPage,
+		socket:        socket,
+		commandsPage:  components.NewCommandsPage(socket),
+		statusPage:    components.NewStatusPage(socket),
+		executePage:   components.NewExecutePage(socket),
+		processesPage: components.NewProcessesPage(socket),
+		frontendsPage: components.NewFrontendsPage(socket),
+		consolePage:   components.NewConsolePage(socket),
+		mapsPage:      components.NewMapsPage(socket),
+		aclsPage:      components.NewAclsPage(socket),
+		tablesPage:    components.NewTablesPage(socket),
+		sessionsPage:  components.NewSessionsPage(socket),
+		certsPage:     components.NewCertsPage(socket),
+		errorBar:      components.NewErrorBar(),
 	}
 }
 
+// SetRefreshInterval configures the auto refresh of the status page, which is switched off with 0
+func (m *RuntimeAPI) SetRefreshInterval(interval time.Duration) {
+	m.statusPage.SetRefreshInterval(interval)
+}
+
+// SetHistory sets the history of the executed commands
+func (m *RuntimeAPI) SetHistory(h *history.History) {
+	m.executePage.SetHistory(h)
+	m.consolePage.SetHistory(h)
+}
+
 func (m RuntimeAPI) Init() tea.Cmd {
-	return tea.Batch(
-		tea.SetWindowTitle(fmt.Sprintf("haproxy-runtime-cli")),
-		m.commandsPage.Init(),
-		m.statusPage.Init(),
-		m.executePage.Init(),
+	// a master cli must be detected first, so the pages talk to a worker
+	return tea.Sequence(
+		m.processesPage.Init(),
+		tea.Batch(
+			tea.SetWindowTitle(fmt.Sprintf("haproxy-runtime-cli")),
+			m.commandsPage.Init(),
+			m.statusPage.Init(),
+			m.executePage.Init(),
+			m.frontendsPage.Init(),
+			m.consolePage.Init(),
+			m.mapsPage.Init(),
+			m.aclsPage.Init(),
+			m.tablesPage.Init(),
+			m.sessionsPage.Init(),
+			m.certsPage.Init(),
+		),
 	)
 }
 
@@ -49,9 +103,13 @@ func (m RuntimeAPI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
+	// errors are shown instead of crashing, dismissing them doesn't reach the pages
+	if m.errorBar.Supports(msg, true) {
+		m.errorBar, cmd = m.errorBar.Update(msg)
+		return m, cmd
+	}
+
 	switch msg := msg.(type) {
-	case error:
-		panic(msg)
 	case components.ActivateStatusPage:
 		m.page = statusPage
 		return m, nil
@@ -61,6 +119,26 @@ func (m RuntimeAPI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case components.ActivateExecutePage:
 		m.page = executePage
 		return m, nil
+	case components.ActivateProcessesPage:
+		m.page = processesPage
+		return m, nil
+	case components.ActivateFrontendsPage:
+		m.page = frontendsPage
+		return m, nil
+	case components.ActivateConsolePage:
+		m.page = consolePage
+		return m, nil
+	// the following pages refresh when activated, so their activation is passed on to them
+	case components.ActivateMapsPage:
+		m.page = mapsPage
+	case components.ActivateAclsPage:
+		m.page = aclsPage
+	case components.ActivateTablesPage:
+		m.page = tablesPage
+	case components.ActivateSessionsPage:
+		m.page = sessionsPage
+	case components.ActivateCertsPage:
+		m.page = certsPage
 
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -82,12 +160,48 @@ func (m RuntimeAPI) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.executePage, cmd = m.executePage.Update(msg)
 		cmds = append(cmds, cmd)
 	}
+	if m.processesPage.Supports(msg, m.page == processesPage) {
+		m.processesPage, cmd = m.processesPage.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.frontendsPage.Supports(msg, m.page == frontendsPage) {
+		m.frontendsPage, cmd = m.frontendsPage.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
+	if m.consolePage.Supports(msg, m.page == consolePage) {
+		m.consolePage, cmd = m.consolePage.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.mapsPage.Supports(msg, m.page == mapsPage) {
+		m.mapsPage, cmd = m.mapsPage.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.aclsPage.Supports(msg, m.page == aclsPage) {
+		m.aclsPage, cmd = m.aclsPage.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.tablesPage.Supports(msg, m.page == tablesPage) {
+		m.tablesPage, cmd = m.tablesPage.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.sessionsPage.Supports(msg, m.page == sessionsPage) {
+		m.sessionsPage, cmd = m.sessionsPage.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.certsPage.Supports(msg, m.page == certsPage) {
+		m.certsPage, cmd = m.certsPage.Update(msg)
+		cmds = append(cmds, cmd)
+	}
 
 	return m, tea.Batch(cmds...)
 }
 
 func (m RuntimeAPI) View() string {
-	s := header() + "\n"
+	s := m.header() + "\n"
+	if e := m.errorBar.View(); e != "" {
+		s += e + "\n"
+	}
 
 	switch m.page {
 	case statusPage:
@@ -96,11 +210,32 @@ func (m RuntimeAPI) View() string {
 		s += m.commandsPage.View()
 	case executePage:
 		s += m.executePage.View()
+	case processesPage:
+		s += m.processesPage.View()
+	case frontendsPage:
+		s += m.frontendsPage.View()
+	case consolePage:
+		s += m.consolePage.View()
+	case mapsPage:
+		s += m.mapsPage.View()
+	case aclsPage:
+		s += m.aclsPage.View()
+	case tablesPage:
+		s += m.tablesPage.View()
+	case sessionsPage:
+		s += m.sessionsPage.View()
+	case certsPage:
+		s += m.certsPage.View()
 	}
 
 	return styles.PageStyle.Render(s)
 }
 
-func header() string {
-	return styles.HeaderStyle.Render(styles.AppName)
+func (m RuntimeAPI) header() string {
+	h := styles.HeaderStyle.Render(styles.AppName)
+	if m.socket == nil || m.socket.Target() == "" {
+		return h
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Center, h, styles.ComplementStyle.PaddingLeft(2).Render("worker "+strings.TrimPrefix(m.socket.Target(), "@!")))
 }
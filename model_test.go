@@ -1,36 +1,88 @@
 package main
 
 import (
+	"errors"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/stretchr/testify/assert"
 	"haproxy-runtime-cli/components"
+	"haproxy-runtime-cli/fake"
 	"haproxy-runtime-cli/haproxy"
+	"haproxy-runtime-cli/history"
+	"haproxy-runtime-cli/socket"
 	"net"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewModel(t *testing.T) {
-	m := NewRuntimeApi(func() net.Conn {
-		return nil
-	})
+	m := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) {
+		return nil, nil
+	}))
 
 	assert.NotNil(t, m)
 	assert.NotNil(t, m.statusPage)
 	assert.NotNil(t, m.commandsPage)
 	assert.NotNil(t, m.executePage)
+	assert.NotNil(t, m.processesPage)
 	assert.Equal(t, sessionState(2), m.page)
 }
 
 func TestInit(t *testing.T) {
-	cmd := NewRuntimeApi(func() net.Conn { return nil }).Init()
+	cmd := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) { return nil, nil })).Init()
 
 	assert.NotNil(t, cmd)
-	cmds := cmd()
 
-	assert.Len(t, cmds, 3) //tea.Cmd from sub component inits
+	//type juggling as the type is tea.sequenceMsg (which is private), we cast it to the underlying type []tea.Cmd
+	cmds := reflect.ValueOf(cmd()).Convert(reflect.TypeOf([]tea.Cmd{})).Interface().([]tea.Cmd)
+
+	assert.Len(t, cmds, 2)      // master detection, then the sub component inits
+	assert.Len(t, cmds[1](), 4) //tea.Cmd from sub component inits
+}
+
+func TestSetRefreshInterval(t *testing.T) {
+	m := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) { return nil, nil }))
+	assert.Contains(t, m.View(), "auto refresh off")
+
+	m.SetRefreshInterval(time.Second)
+	assert.Contains(t, m.View(), "auto refresh 1s")
+}
+
+func TestSetHistory(t *testing.T) {
+	m := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) { return nil, nil }))
+	h := history.New()
+	_ = h.Add(history.Entry{Command: "show info"})
+
+	m.SetHistory(h)
+	m.page = executePage
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("info")})
+
+	assert.Contains(t, model.View(), "show info")
+}
+
+func TestMasterCli(t *testing.T) {
+	srv, err := fake.NewMasterServer()
+	assert.Nil(t, err)
+	defer srv.Close()
+
+	endpoint, err := socket.ParseEndpoint(srv.Addr, socket.TLSOptions{})
+	assert.Nil(t, err)
+
+	m := NewRuntimeApi(socket.NewClient(endpoint.Dial))
+	cmds := reflect.ValueOf(m.Init()()).Convert(reflect.TypeOf([]tea.Cmd{})).Interface().([]tea.Cmd)
+
+	nm, _ := m.Update(cmds[0]())
+	assert.Contains(t, nm.View(), "worker 8")
+
+	nm, _ = nm.Update(components.ActivateProcessesPage(true))
+	res := nm.View()
+	assert.Contains(t, res, "master")
+	assert.Contains(t, res, "current")
 }
 
 func TestViewStatus(t *testing.T) {
-	m := NewRuntimeApi(func() net.Conn { return nil })
+	m := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) { return nil, nil }))
 	res := m.View()
 
 	// default page is status page
@@ -46,7 +98,7 @@ func TestViewStatus(t *testing.T) {
 }
 
 func TestViewCommands(t *testing.T) {
-	m := NewRuntimeApi(func() net.Conn { return nil })
+	m := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) { return nil, nil }))
 	nm, _ := m.Update(components.ActivateCommandsPage(true))
 	res := nm.View()
 
@@ -55,7 +107,7 @@ func TestViewCommands(t *testing.T) {
 }
 
 func TestViewExecute(t *testing.T) {
-	m := NewRuntimeApi(func() net.Conn { return nil })
+	m := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) { return nil, nil }))
 	nm, _ := m.Update(components.ActivateExecutePage(true))
 	res := nm.View()
 
@@ -63,8 +115,76 @@ func TestViewExecute(t *testing.T) {
 	assert.Contains(t, res, "enter execute") // a column from status page
 }
 
+func TestViewFrontends(t *testing.T) {
+	m := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) { return nil, nil }))
+	nm, _ := m.Update(components.ActivateFrontendsPage(true))
+	res := nm.View()
+
+	assert.Contains(t, res, "haproxy-runtime-cli")
+	assert.Contains(t, res, "Listener") // a column from frontends page
+}
+
+func TestViewConsole(t *testing.T) {
+	m := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) { return nil, nil }))
+	nm, _ := m.Update(components.ActivateConsolePage(true))
+	res := nm.View()
+
+	assert.Contains(t, res, "haproxy-runtime-cli")
+	assert.Contains(t, res, "esc status page")
+}
+
+func TestViewMaps(t *testing.T) {
+	m := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) { return nil, nil }))
+	nm, cmd := m.Update(components.ActivateMapsPage(true))
+	res := nm.View()
+
+	assert.NotNil(t, cmd) // the maps are fetched
+	assert.Contains(t, res, "haproxy-runtime-cli")
+	assert.Contains(t, res, "Entries") // a column from maps page
+}
+
+func TestViewAcls(t *testing.T) {
+	m := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) { return nil, nil }))
+	nm, cmd := m.Update(components.ActivateAclsPage(true))
+	res := nm.View()
+
+	assert.NotNil(t, cmd) // the acls are fetched
+	assert.Contains(t, res, "haproxy-runtime-cli")
+	assert.Contains(t, res, "Patterns") // a column from acls page
+}
+
+func TestViewTables(t *testing.T) {
+	m := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) { return nil, nil }))
+	nm, cmd := m.Update(components.ActivateTablesPage(true))
+	res := nm.View()
+
+	assert.NotNil(t, cmd) // the tables are fetched
+	assert.Contains(t, res, "haproxy-runtime-cli")
+	assert.Contains(t, res, "Used") // a column from tables page
+}
+
+func TestViewSessions(t *testing.T) {
+	m := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) { return nil, nil }))
+	nm, cmd := m.Update(components.ActivateSessionsPage(true))
+	res := nm.View()
+
+	assert.NotNil(t, cmd) // the sessions are fetched
+	assert.Contains(t, res, "haproxy-runtime-cli")
+	assert.Contains(t, res, "Frontend") // a column from sessions page
+}
+
+func TestViewCerts(t *testing.T) {
+	m := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) { return nil, nil }))
+	nm, cmd := m.Update(components.ActivateCertsPage(true))
+	res := nm.View()
+
+	assert.NotNil(t, cmd) // the certificates are fetched
+	assert.Contains(t, res, "haproxy-runtime-cli")
+	assert.Contains(t, res, "SANs") // a column from certificates page
+}
+
 func TestUpdateWithKnownCommands(t *testing.T) {
-	m := NewRuntimeApi(func() net.Conn { return nil })
+	m := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) { return nil, nil }))
 
 	// supported by status
 	_, cmds := m.Update([]haproxy.Backend{
@@ -89,3 +209,18 @@ func TestUpdateWithKnownCommands(t *testing.T) {
 
 	assert.Nil(t, cmds)
 }
+
+func TestUpdateWithError(t *testing.T) {
+	m := NewRuntimeApi(socket.NewClient(func() (net.Conn, error) { return nil, nil }))
+
+	nm, cmd := m.Update(socket.ConnectionError{Err: errors.New("dial unix /var/run/haproxy.sock: connect: no such file or directory")})
+	assert.Nil(t, cmd)
+	assert.Contains(t, nm.View(), "connection to haproxy failed")
+	assert.Contains(t, nm.View(), "esc dismiss")
+
+	// dismissing the error doesn't reach the page
+	nm, cmd = nm.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Nil(t, cmd)
+	assert.NotContains(t, nm.View(), "connection to haproxy failed")
+	assert.Equal(t, statusPage, nm.(RuntimeAPI).page)
+}
@@ -0,0 +1,218 @@
+package socket
+
+import (
+	"context"
+	"errors"
+	"haproxy-runtime-cli/haproxy"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client sends commands to a runtime api socket, one at a time, over a connection kept open in
+// interactive mode. Connected to the master cli of a master-worker HAProxy, commands are routed to the selected worker.
+type Client struct {
+	dial func() (net.Conn, error)
+	// state guards the routing, it is read while rendering and must not wait for a command to complete
+	state  sync.RWMutex
+	target string
+	master bool
+	// mu is held for the whole round trip of a command
+	mu      sync.Mutex
+	timeout time.Duration
+	session *session
+	// oneShot is set if the socket does not support interactive mode, then each command uses its own connection
+	oneShot bool
+}
+
+// DefaultTimeout is the time HAProxy has to answer a command
+const DefaultTimeout = 10 * time.Second
+
+func NewClient(dial func() (net.Conn, error)) *Client {
+	return &Client{dial: dial, timeout: DefaultTimeout}
+}
+
+// SetTimeout changes the time HAProxy has to answer a command, 0 waits forever
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.timeout = timeout
+}
+
+// Target is the master cli prefix (e.g. @!1234) commands are routed to, empty if not connected to a master cli
+func (c *Client) Target() string {
+	c.state.RLock()
+	defer c.state.RUnlock()
+
+	return c.target
+}
+
+func (c *Client) SetTarget(target string) {
+	c.state.Lock()
+	defer c.state.Unlock()
+
+	c.target = target
+}
+
+func (c *Client) IsMaster() bool {
+	c.state.RLock()
+	defer c.state.RUnlock()
+
+	return c.master
+}
+
+// Detect finds out from the `help` output whether the socket is a master cli,
+// and if so routes all further commands to the current worker
+func Detect(ctx context.Context, c *Client) ([]haproxy.Process, error) {
+	help, err := ExecMaster(ctx, c, "help")
+	if err != nil {
+		return nil, err
+	}
+
+	if !haproxy.ParseHelp(help).IsMaster() {
+		return nil, nil
+	}
+
+	processes, err := Processes(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	c.state.Lock()
+	defer c.state.Unlock()
+	c.master = true
+	if w := haproxy.CurrentWorker(processes); w != nil {
+		c.target = w.Target()
+	}
+
+	return processes, nil
+}
+
+// Processes lists the master and its workers with `show proc`
+func Processes(ctx context.Context, c *Client) ([]haproxy.Process, error) {
+	out, err := ExecMaster(ctx, c, "show proc")
+	if err != nil {
+		return nil, err
+	}
+
+	return haproxy.ParseProcesses(*out), nil
+}
+
+// ExecMaster sends the command unrouted, on a master cli it is executed by the master itself
+func ExecMaster(ctx context.Context, c *Client, command string) (*string, error) {
+	return c.exec(ctx, command)
+}
+
+// Close closes the connection kept open between commands
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closeSession()
+}
+
+func (c *Client) exec(ctx context.Context, command string) (*string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var response *string
+	var err error
+	if c.oneShot {
+		response, err = writeToSocket(ctx, c.dial, c.timeout, command)
+	} else {
+		response, err = c.execSession(ctx, command)
+	}
+	if ctx.Err() != nil {
+		// the command was interrupted, its response can't be told apart from the next one anymore
+		_ = c.closeSession()
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, ConnectionError{Err: err}
+	}
+
+	if response == nil {
+		return nil, ConnectionError{Err: errors.New("no response")}
+	}
+
+	return response, nil
+}
+
+func (c *Client) execSession(ctx context.Context, command string) (*string, error) {
+	if c.session != nil && !c.session.alive() {
+		_ = c.closeSession()
+	}
+
+	for retry := true; ; retry = false {
+		if c.session == nil {
+			s, err := openSession(ctx, c.dial, c.timeout)
+			if errors.Is(err, errNoPrompt) {
+				c.oneShot = true
+				return writeToSocket(ctx, c.dial, c.timeout, command)
+			} else if err != nil {
+				return nil, err
+			}
+			c.session = s
+		}
+
+		err := c.session.write(ctx, command)
+		if err != nil {
+			_ = c.closeSession()
+			// nothing was sent, so it is safe to try again on a new connection
+			if retry && ctx.Err() == nil {
+				continue
+			}
+			return nil, err
+		}
+
+		response, prompted, err := c.session.read(ctx)
+		if err != nil || !prompted {
+			_ = c.closeSession()
+		}
+
+		return response, err
+	}
+}
+
+func (c *Client) closeSession() error {
+	if c.session == nil {
+		return nil
+	}
+
+	err := c.session.Close()
+	c.session = nil
+
+	return err
+}
+
+// route prefixes every command of a `;` separated command line with the target
+func route(target string, command string) string {
+	if target == "" || strings.HasPrefix(strings.TrimSpace(command), "@") {
+		return command
+	}
+
+	line, payload, hasPayload := strings.Cut(command, "\n")
+	out := target + " "
+	for i := 0; i < len(line); i++ {
+		out += string(line[i])
+		switch {
+		case line[i] == '\\' && i+1 < len(line):
+			out += string(line[i+1])
+			i++
+		case line[i] == ';':
+			out += target + " "
+		}
+	}
+
+	if hasPayload {
+		out += "\n" + payload
+	}
+
+	return out
+}
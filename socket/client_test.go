@@ -0,0 +1,83 @@
+package socket
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/fake"
+	"testing"
+	"time"
+)
+
+func TestRoute(t *testing.T) {
+	assert.Equal(t, "show info", route("", "show info"))
+	assert.Equal(t, "@!8 show info", route("@!8", "show info"))
+	assert.Equal(t, "@1 show info", route("@!8", "@1 show info"))
+	assert.Equal(t, "@!8 show info;@!8 show stat", route("@!8", "show info;show stat"))
+	assert.Equal(t, `@!8 echo a\;b`, route("@!8", `echo a\;b`))
+	assert.Equal(t, "@!8 add map foo <<\na;b\n", route("@!8", "add map foo <<\na;b\n"))
+}
+
+func TestDetect(t *testing.T) {
+	t.Run("Plain socket", func(t *testing.T) {
+		srv, err := fake.NewServer()
+		assert.Nil(t, err)
+		defer srv.Close()
+
+		c := fakeClient(t, srv)
+		processes, err := Detect(context.Background(), c)
+
+		assert.Nil(t, err)
+		assert.Nil(t, processes)
+		assert.False(t, c.IsMaster())
+		assert.Equal(t, "", c.Target())
+	})
+
+	t.Run("Master cli", func(t *testing.T) {
+		srv, err := fake.NewMasterServer()
+		assert.Nil(t, err)
+		defer srv.Close()
+
+		c := fakeClient(t, srv)
+		processes, err := Detect(context.Background(), c)
+
+		assert.Nil(t, err)
+		assert.Len(t, processes, 2)
+		assert.True(t, c.IsMaster())
+		assert.Equal(t, "@!8", c.Target())
+
+		// commands are routed to the worker, master commands are not
+		res, err := Exec(context.Background(), c, "show cli level")
+		assert.Nil(t, err)
+		assert.Equal(t, "admin", *res)
+
+		res, err = ExecMaster(context.Background(), c, "reload")
+		assert.Nil(t, err)
+		assert.Contains(t, *res, "Success=1")
+	})
+}
+
+func TestTargetDuringCommand(t *testing.T) {
+	c := NewClient(nil)
+	c.SetTarget("@!8")
+
+	// a command in flight holds the client, the routing is still readable to render the header
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	done := make(chan string)
+	go func() { done <- c.Target() }()
+
+	select {
+	case target := <-done:
+		assert.Equal(t, "@!8", target)
+	case <-time.After(time.Second):
+		t.Fatal("Target blocked by the command in flight")
+	}
+}
+
+func fakeClient(t *testing.T, srv *fake.Server) *Client {
+	endpoint, err := ParseEndpoint(srv.Addr, TLSOptions{})
+	assert.Nil(t, err)
+
+	return NewClient(endpoint.Dial)
+}
@@ -0,0 +1,142 @@
+package socket
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// Endpoint is the address of a runtime api socket
+type Endpoint struct {
+	Network string
+	Address string
+	TLS     *tls.Config
+}
+
+// TLSOptions configure the connection to a tls terminated socket, e.g. one fronted by stunnel
+type TLSOptions struct {
+	Enabled            bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// ParseEndpoint understands unix:///path/to.sock, tcp://host:port, HAProxy style
+// unix@/path/to.sock, ipv4@host:port and ipv6@host:port addresses, and plain socket paths
+func ParseEndpoint(address string, options TLSOptions) (Endpoint, error) {
+	var e Endpoint
+
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		e = Endpoint{Network: "unix", Address: strings.TrimPrefix(address, "unix://")}
+	case strings.HasPrefix(address, "unix@"):
+		e = Endpoint{Network: "unix", Address: strings.TrimPrefix(address, "unix@")}
+	case strings.HasPrefix(address, "tcp://"):
+		e = Endpoint{Network: "tcp", Address: strings.TrimPrefix(address, "tcp://")}
+	case strings.HasPrefix(address, "ipv4@"):
+		e = Endpoint{Network: "tcp4", Address: strings.TrimPrefix(address, "ipv4@")}
+	case strings.HasPrefix(address, "ipv6@"):
+		e = Endpoint{Network: "tcp6", Address: strings.TrimPrefix(address, "ipv6@")}
+	case strings.Contains(address, "://"):
+		return Endpoint{}, fmt.Errorf("unsupported socket address %s, use unix:// or tcp://", address)
+	default:
+		e = Endpoint{Network: "unix", Address: address}
+	}
+
+	if e.Address == "" {
+		return Endpoint{}, fmt.Errorf("missing socket address in %s", address)
+	}
+
+	if e.Network != "unix" {
+		if _, _, err := net.SplitHostPort(e.Address); err != nil {
+			return Endpoint{}, fmt.Errorf("invalid socket address %s: %w", address, err)
+		}
+	}
+
+	if options.Enabled {
+		config, err := options.config(e)
+		if err != nil {
+			return Endpoint{}, err
+		}
+		e.TLS = config
+	}
+
+	return e, nil
+}
+
+// dialTimeout is the time to connect to HAProxy, including the tls handshake
+const dialTimeout = 5 * time.Second
+
+func (e Endpoint) Dial() (net.Conn, error) {
+	conn, err := net.DialTimeout(e.Network, e.Address, dialTimeout)
+	if err != nil || e.TLS == nil {
+		return conn, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	tlsConn := tls.Client(conn, e.TLS)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("tls handshake with %s failed: %w", e, err)
+	}
+
+	return tlsConn, nil
+}
+
+func (e Endpoint) String() string {
+	if e.Network == "unix" {
+		return e.Address
+	}
+
+	scheme := "tcp"
+	if e.TLS != nil {
+		scheme = "tls"
+	}
+
+	return scheme + "://" + e.Address
+}
+
+func (o TLSOptions) config(e Endpoint) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if config.ServerName == "" && e.Network != "unix" {
+		config.ServerName, _, _ = net.SplitHostPort(e.Address)
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca file: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in ca file %s", o.CAFile)
+		}
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		if o.CertFile == "" || o.KeyFile == "" {
+			return nil, errors.New("a client certificate needs both a cert and a key file")
+		}
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,140 @@
+package socket
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/fake"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	for address, expected := range map[string]Endpoint{
+		"/var/run/haproxy.sock":        {Network: "unix", Address: "/var/run/haproxy.sock"},
+		"unix:///var/run/haproxy.sock": {Network: "unix", Address: "/var/run/haproxy.sock"},
+		"unix@/var/run/haproxy.sock":   {Network: "unix", Address: "/var/run/haproxy.sock"},
+		"tcp://127.0.0.1:9999":         {Network: "tcp", Address: "127.0.0.1:9999"},
+		"ipv4@127.0.0.1:9999":          {Network: "tcp4", Address: "127.0.0.1:9999"},
+		"ipv6@[::1]:9999":              {Network: "tcp6", Address: "[::1]:9999"},
+	} {
+		e, err := ParseEndpoint(address, TLSOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, expected, e)
+	}
+
+	for _, address := range []string{"http://localhost", "tcp://localhost", "unix://", ""} {
+		_, err := ParseEndpoint(address, TLSOptions{})
+		assert.Error(t, err, address)
+	}
+}
+
+func TestParseEndpointTLS(t *testing.T) {
+	e, err := ParseEndpoint("tcp://haproxy.local:9999", TLSOptions{Enabled: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "haproxy.local", e.TLS.ServerName)
+	assert.Equal(t, "tls://haproxy.local:9999", e.String())
+
+	_, err = ParseEndpoint("tcp://haproxy.local:9999", TLSOptions{Enabled: true, CertFile: "client.pem"})
+	assert.EqualError(t, err, "a client certificate needs both a cert and a key file")
+
+	_, err = ParseEndpoint("tcp://haproxy.local:9999", TLSOptions{Enabled: true, CAFile: "missing.pem"})
+	assert.Error(t, err)
+}
+
+func TestExecOverTCP(t *testing.T) {
+	srv, err := fake.NewTCPServer(nil)
+	assert.Nil(t, err)
+	defer srv.Close()
+
+	endpoint, err := ParseEndpoint(srv.Addr, TLSOptions{})
+	assert.Nil(t, err)
+
+	res, err := Exec(context.Background(), NewClient(endpoint.Dial), "show cli level")
+	assert.Nil(t, err)
+	assert.Equal(t, "admin", *res)
+}
+
+func TestExecOverTLSWithClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := writeCertificate(t, dir, "ca", nil, nil)
+	writeCertificate(t, dir, "server", ca, caKey)
+	writeCertificate(t, dir, "client", ca, caKey)
+	serverCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key"))
+	assert.Nil(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	srv, err := fake.NewTCPServer(&tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	assert.Nil(t, err)
+	defer srv.Close()
+
+	endpoint, err := ParseEndpoint(srv.Addr, TLSOptions{
+		Enabled:    true,
+		CAFile:     filepath.Join(dir, "ca.pem"),
+		CertFile:   filepath.Join(dir, "client.pem"),
+		KeyFile:    filepath.Join(dir, "client.key"),
+		ServerName: "localhost",
+	})
+	assert.Nil(t, err)
+
+	res, err := Exec(context.Background(), NewClient(endpoint.Dial), "show cli level")
+	assert.Nil(t, err)
+	assert.Equal(t, "admin", *res)
+
+	// without client certificate the handshake is rejected
+	endpoint.TLS.Certificates = nil
+	conn, err := endpoint.Dial()
+	if err == nil {
+		// tls 1.3 reports the rejected client certificate on the first read
+		_, err = conn.Read(make([]byte, 1))
+	}
+	assert.Error(t, err)
+}
+
+// writeCertificate creates a certificate signed by the parent (or a self-signed ca) as <name>.pem and <name>.key
+func writeCertificate(t *testing.T, dir string, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, name+".pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+
+	return cert, key
+}
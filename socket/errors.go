@@ -0,0 +1,16 @@
+package socket
+
+import "fmt"
+
+// ConnectionError is returned if HAProxy is unreachable or the connection broke, e.g. because HAProxy was restarted
+type ConnectionError struct {
+	Err error
+}
+
+func (e ConnectionError) Error() string {
+	return fmt.Sprintf("connection to haproxy failed: %s", e.Err)
+}
+
+func (e ConnectionError) Unwrap() error {
+	return e.Err
+}
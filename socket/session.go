@@ -0,0 +1,135 @@
+package socket
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// errNoPrompt is returned if the socket does not switch to interactive mode, e.g. because it is not a HAProxy
+var errNoPrompt = errors.New("socket does not support interactive mode")
+
+// session is a connection in interactive mode (`prompt`), which is kept open across commands.
+// Each response is terminated by the prompt, e.g. "> " or "master> ".
+type session struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+	// continuations are the "+ " prompts preceding the response, one for the command line and each line of a payload
+	continuations int
+	// prompt is the prompt seen when the session opened, empty until then
+	prompt string
+}
+
+func openSession(ctx context.Context, dial func() (net.Conn, error), timeout time.Duration) (*session, error) {
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &session{conn: conn, timeout: timeout}
+	s.reader = bufio.NewReader(s.conn)
+
+	if err := s.write(ctx, "prompt"); err != nil {
+		_ = s.conn.Close()
+		return nil, err
+	}
+
+	_, prompted, err := s.read(ctx)
+	if err != nil || !prompted {
+		_ = s.conn.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !prompted {
+		return nil, errNoPrompt
+	}
+
+	return s, nil
+}
+
+// alive reports whether HAProxy still keeps the connection open, idle sessions are closed after `stats timeout`
+func (s *session) alive() bool {
+	_ = s.conn.SetReadDeadline(time.Now().Add(time.Millisecond))
+	defer s.conn.SetReadDeadline(time.Time{})
+
+	_, err := s.reader.Peek(1)
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func (s *session) write(ctx context.Context, command string) error {
+	defer watch(ctx, s.conn, s.timeout)()
+
+	s.continuations = 0
+	if line, _, _ := strings.Cut(command, "\n"); strings.HasSuffix(line, "<<") {
+		s.continuations = strings.Count(strings.TrimSuffix(command, "\n"), "\n") + 1
+	}
+
+	if _, err := s.conn.Write([]byte(command + "\n")); err != nil {
+		return fmt.Errorf("failed to write to socket: %w", err)
+	}
+
+	return nil
+}
+
+// read reads the response up to the next prompt. If HAProxy closes the connection
+// instead (e.g. after `quit` or a reload), what was received so far is the response.
+func (s *session) read(ctx context.Context) (*string, bool, error) {
+	defer watch(ctx, s.conn, s.timeout)()
+
+	response := ""
+	buf := make([]byte, 4096)
+	for {
+		n, err := s.reader.Read(buf)
+		response += string(buf[:n])
+
+		if out, prompt, ok := cutPrompt(cutContinuations(response, s.continuations), s.prompt); ok {
+			s.prompt = prompt
+			return &out, true, nil
+		}
+		if err == io.EOF {
+			trimmedResponse := strings.TrimSpace(cutContinuations(response, s.continuations))
+			return &trimmedResponse, false, nil
+		} else if err != nil {
+			return nil, false, fmt.Errorf("failed to read from socket: %w", err)
+		}
+	}
+}
+
+func (s *session) Close() error {
+	return s.conn.Close()
+}
+
+// cutContinuations removes up to n "+ " prompts HAProxy sends while reading a payload
+func cutContinuations(response string, n int) string {
+	for ; n > 0 && strings.HasPrefix(response, "+ "); n-- {
+		response = response[2:]
+	}
+
+	return response
+}
+
+// cutPrompt removes the prompt from a complete response, the prompt follows its last newline. Once the
+// session is open only its prompt, "> " or "master> " are matched, so that a read ending within a line
+// such as the usage `<val> ` is not taken for the end. Before that, any last line ending with "> " is the prompt.
+func cutPrompt(response string, prompt string) (string, string, bool) {
+	i := strings.LastIndex(response, "\n")
+	last := response[i+1:]
+
+	switch {
+	case prompt == "" && strings.HasSuffix(last, "> "):
+	case prompt != "" && (last == prompt || last == "> " || last == "master> "):
+	default:
+		return "", "", false
+	}
+
+	return strings.TrimSpace(response[:i+1]), last, true
+}
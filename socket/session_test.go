@@ -0,0 +1,151 @@
+package socket
+
+import (
+	"bufio"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/fake"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestCutPrompt(t *testing.T) {
+	res, prompt, ok := cutPrompt("foo\nbar\n\n> ", "> ")
+	assert.True(t, ok)
+	assert.Equal(t, "foo\nbar", res)
+	assert.Equal(t, "> ", prompt)
+
+	res, prompt, ok = cutPrompt("master> ", "")
+	assert.True(t, ok)
+	assert.Equal(t, "", res)
+	assert.Equal(t, "master> ", prompt)
+
+	_, _, ok = cutPrompt("foo\nbar\n", "> ")
+	assert.False(t, ok)
+
+	_, _, ok = cutPrompt("foo\nbar> baz", "> ")
+	assert.False(t, ok)
+
+	// a read may end within the usage of a command
+	_, _, ok = cutPrompt("Usage: add map [@<ver>] <map> <key> <val> ", "> ")
+	assert.False(t, ok)
+	_, _, ok = cutPrompt("foo\n  add map [@<ver>] <map> <key> <val> ", "> ")
+	assert.False(t, ok)
+}
+
+func TestSession(t *testing.T) {
+	srv, err := fake.NewServer()
+	assert.Nil(t, err)
+	defer srv.Close()
+
+	endpoint, err := ParseEndpoint(srv.Addr, TLSOptions{})
+	assert.Nil(t, err)
+
+	dials := 0
+	c := NewClient(func() (net.Conn, error) {
+		dials++
+		return endpoint.Dial()
+	})
+	defer c.Close()
+
+	t.Run("Reuses the connection", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			res, err := Exec(context.Background(), c, "show cli level")
+			assert.Nil(t, err)
+			assert.Equal(t, "admin", *res)
+		}
+
+		assert.Equal(t, 1, dials)
+		assert.False(t, c.oneShot)
+	})
+
+	t.Run("Keeps the session level", func(t *testing.T) {
+		_, err := Exec(context.Background(), c, "operator")
+		assert.Nil(t, err)
+
+		res, err := Exec(context.Background(), c, "show cli level")
+		assert.Nil(t, err)
+		assert.Equal(t, "operator", *res)
+	})
+
+	t.Run("Reconnects after the timeout", func(t *testing.T) {
+		srv.SetTimeout(20 * time.Millisecond)
+		// the timeout applies from the next command on
+		_, err := Exec(context.Background(), c, "show cli level")
+		assert.Nil(t, err)
+		time.Sleep(50 * time.Millisecond)
+
+		res, err := Exec(context.Background(), c, "show cli level")
+		assert.Nil(t, err)
+		assert.Equal(t, "admin", *res)
+		assert.Equal(t, 2, dials)
+	})
+
+	t.Run("Reconnects after quit", func(t *testing.T) {
+		srv.SetTimeout(0)
+		res, err := Exec(context.Background(), c, "quit")
+		assert.Nil(t, err)
+		assert.Equal(t, "", *res)
+
+		res, err = Exec(context.Background(), c, "show cli level")
+		assert.Nil(t, err)
+		assert.Equal(t, "admin", *res)
+		assert.Equal(t, 3, dials)
+	})
+
+	t.Run("Drops the payload prompts", func(t *testing.T) {
+		res, err := Exec(context.Background(), c, "add map /etc/haproxy/maps/hosts.map <<\na.com default\nb.com other\n")
+		assert.Nil(t, err)
+		assert.Equal(t, "", *res)
+
+		res, err = Exec(context.Background(), c, "add map unknown.map <<\na.com default\n")
+		assert.Nil(t, err)
+		assert.Equal(t, "Unknown map identifier. Please use #<id> or <file>.", *res)
+	})
+}
+
+func TestSessionSplitRead(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+
+	go func() {
+		reader := bufio.NewReader(server)
+		_, _ = reader.ReadString('\n')
+		_, _ = server.Write([]byte("\n> "))
+
+		// the first read ends within the usage, like a full buffer may
+		_, _ = reader.ReadString('\n')
+		_, _ = server.Write([]byte("'add map' expects three parameters:\n  add map [@<ver>] <map> <key> <val> "))
+		_, _ = server.Write([]byte("\n\n> "))
+	}()
+
+	s, err := openSession(context.Background(), func() (net.Conn, error) { return client, nil }, time.Second)
+	assert.Nil(t, err)
+	assert.Nil(t, s.write(context.Background(), "add map foo"))
+
+	res, prompted, err := s.read(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, prompted)
+	assert.Equal(t, "'add map' expects three parameters:\n  add map [@<ver>] <map> <key> <val>", *res)
+}
+
+func TestCutContinuations(t *testing.T) {
+	assert.Equal(t, "> ", cutContinuations("+ + + > ", 3))
+	assert.Equal(t, "Unknown version: 2.\n\n> ", cutContinuations("+ + Unknown version: 2.\n\n> ", 2))
+	// only the prompts of the payload are dropped
+	assert.Equal(t, "+ foo\n\n> ", cutContinuations("+ + foo\n\n> ", 1))
+	assert.Equal(t, "+ foo\n\n> ", cutContinuations("+ foo\n\n> ", 0))
+}
+
+func TestSessionFallback(t *testing.T) {
+	c := NewClient(func() (net.Conn, error) {
+		return &DummySocket{Output: []byte("Hello, this is a response from the socket.")}, nil
+	})
+
+	res, err := Exec(context.Background(), c, "help")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello, this is a response from the socket.", *res)
+	assert.True(t, c.oneShot)
+}
@@ -2,65 +2,61 @@ package socket
 
 import (
 	"bufio"
-	"errors"
+	"context"
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
+	"haproxy-runtime-cli/haproxy"
 	"io"
 	"net"
 	"strings"
-	"sync"
+	"time"
 )
 
-var (
-	mu sync.Mutex // Declare a mutex
-)
-
-func Listen(file string) net.Conn {
-	c, err := net.Dial("unix", file)
-	if err != nil {
-		panic(err)
-	}
-
-	return c
-}
-
-func ExecCmd[T any](conn func() net.Conn, command string, cb func(*string) T) tea.Cmd {
+// ExecCmd executes the command and hands the response over to cb. Connection errors,
+// failures reported by HAProxy and errors returned by cb are sent as error messages.
+func ExecCmd[T any](ctx context.Context, conn *Client, command string, cb func(*string) (T, error)) tea.Cmd {
 	return func() tea.Msg {
-		res, err := writeToSocket(conn, command)
+		res, err := Exec(ctx, conn, command)
 		if err != nil {
 			return err
 		}
-		return cb(res)
+		if err := haproxy.CheckResponse(command, *res); err != nil {
+			return err
+		}
+
+		msg, err := cb(res)
+		if err != nil {
+			// whatever could be parsed is still shown
+			return tea.BatchMsg{
+				func() tea.Msg { return msg },
+				func() tea.Msg { return err },
+			}
+		}
+
+		return msg
 	}
 }
 
-func Exec(conn func() net.Conn, command string) (*string, error) {
-	response, err := writeToSocket(conn, command)
+// Exec sends the command, on a master cli it is routed to the selected worker.
+// It gives up once the context is done or the timeout of the client is exceeded.
+func Exec(ctx context.Context, conn *Client, command string) (*string, error) {
+	return conn.exec(ctx, route(conn.Target(), command))
+}
+
+func writeToSocket(ctx context.Context, c func() (net.Conn, error), timeout time.Duration, command string) (*string, error) {
+	sock, err := c()
 	if err != nil {
 		return nil, err
 	}
+	defer sock.Close()
+	defer watch(ctx, sock, timeout)()
 
-	if response == nil {
-		return nil, errors.New("no response")
-	}
-
-	return response, nil
-}
-
-func writeToSocket(c func() net.Conn, command string) (*string, error) {
-	mu.Lock()
-	sock := c()
-	_, err := sock.Write([]byte(command + "\n"))
+	_, err = sock.Write([]byte(command + "\n"))
 	if err != nil {
-		mu.Unlock()
 		return nil, fmt.Errorf("failed to write to socket: %w", err)
 	}
 
-	//time.Sleep(time.Second)
-	res, err := readFromSocket(sock)
-	sock.Close()
-	mu.Unlock()
-	return res, err
+	return readFromSocket(sock)
 }
 
 func readFromSocket(r io.Reader) (*string, error) {
@@ -79,3 +75,24 @@ func readFromSocket(r io.Reader) (*string, error) {
 
 	return &trimmedResponse, nil
 }
+
+// watch applies the timeout to the connection and interrupts pending reads and writes once the context
+// is done. The deadline of the context is not copied onto the connection, the connection could time out
+// before the context does and the caller would get an i/o timeout instead of the context error.
+// The returned func stops watching.
+func watch(ctx context.Context, conn net.Conn, timeout time.Duration) func() {
+	deadline := time.Time{}
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+
+	return func() {
+		stop()
+		_ = conn.SetDeadline(time.Time{})
+	}
+}
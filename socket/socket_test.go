@@ -1,9 +1,14 @@
 package socket
 
 import (
+	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"haproxy-runtime-cli/fake"
 	"net"
+	"os"
 	"testing"
+	"time"
 )
 
 func TestReadFromSocket(t *testing.T) {
@@ -22,10 +27,82 @@ func TestWriteToSocket(t *testing.T) {
 		Output: []byte("Hello, this is a response from the socket."),
 	}
 
-	data, err := writeToSocket(func() net.Conn {
-		return conn
-	}, "help")
+	data, err := writeToSocket(context.Background(), func() (net.Conn, error) {
+		return conn, nil
+	}, DefaultTimeout, "help")
 
 	assert.Nil(t, err)
 	assert.Equal(t, "Hello, this is a response from the socket.", *data)
 }
+
+func TestExecAgainstFakeHAProxy(t *testing.T) {
+	srv, err := fake.NewServer()
+	assert.Nil(t, err)
+	defer srv.Close()
+
+	c := fakeClient(t, srv)
+
+	res, err := Exec(context.Background(), c, "set server default/apache state maint")
+	assert.Nil(t, err)
+	assert.Equal(t, "", *res)
+
+	res, err = Exec(context.Background(), c, "show servers state default")
+	assert.Nil(t, err)
+	assert.Contains(t, *res, "4 default 2 apache 151.101.2.132 0 1 80")
+}
+
+func TestExecConnectionError(t *testing.T) {
+	c := NewClient(func() (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	_, err := Exec(context.Background(), c, "show info")
+
+	assert.ErrorAs(t, err, &ConnectionError{})
+	assert.Equal(t, "connection to haproxy failed: connection refused", err.Error())
+}
+
+// hangingSocket accepts connections but never answers, like a stuck HAProxy
+func hangingSocket(t *testing.T) func() (net.Conn, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+	}()
+
+	return func() (net.Conn, error) {
+		return net.Dial("tcp", l.Addr().String())
+	}
+}
+
+func TestExecTimeout(t *testing.T) {
+	c := NewClient(hangingSocket(t))
+	c.SetTimeout(50 * time.Millisecond)
+
+	_, err := Exec(context.Background(), c, "show sess all")
+
+	assert.ErrorAs(t, err, &ConnectionError{})
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
+
+func TestExecCancel(t *testing.T) {
+	c := NewClient(hangingSocket(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err := Exec(ctx, c, "show sess all")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = Exec(ctx, c, "show sess all")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -20,6 +20,16 @@ var ErrorColor = lipgloss.CompleteAdaptiveColor{
 	Light: lipgloss.CompleteColor{TrueColor: "#ff0000", ANSI256: "160", ANSI: "9"},
 	Dark:  lipgloss.CompleteColor{TrueColor: "#ff0000", ANSI256: "160", ANSI: "9"},
 }
+var HealthyColor = lipgloss.CompleteAdaptiveColor{
+	Light: lipgloss.CompleteColor{TrueColor: "#008700", ANSI256: "28", ANSI: "2"},
+	Dark:  lipgloss.CompleteColor{TrueColor: "#00d700", ANSI256: "40", ANSI: "10"},
+}
+
+var WarningColor = lipgloss.CompleteAdaptiveColor{
+	Light: lipgloss.CompleteColor{TrueColor: "#af8700", ANSI256: "136", ANSI: "3"},
+	Dark:  lipgloss.CompleteColor{TrueColor: "#ffd700", ANSI256: "220", ANSI: "11"},
+}
+
 var HeaderStyle = lipgloss.NewStyle().
 	Bold(true).
 	Background(ActiveColor).
@@ -40,3 +50,14 @@ var ResponseStyle = lipgloss.NewStyle().
 
 var ErrorStyle = lipgloss.NewStyle().
 	Foreground(ErrorColor).Bold(true).Underline(true).Padding(1)
+
+var ErrorBarStyle = lipgloss.NewStyle().
+	Foreground(ErrorColor).Bold(true)
+
+// the health of a backend: all servers up, some of them or none
+var HealthyStyle = lipgloss.NewStyle().Foreground(HealthyColor)
+var DegradedStyle = lipgloss.NewStyle().Foreground(WarningColor)
+var DownStyle = lipgloss.NewStyle().Foreground(ErrorColor).Bold(true)
+
+// HighlightStyle marks the matches of a search
+var HighlightStyle = lipgloss.NewStyle().Reverse(true)